@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/Andrew-M-C/go.util/china/pinyin"
 	sliceutil "github.com/Andrew-M-C/go.util/slices"
 )
 
@@ -172,7 +173,8 @@ func MatchDivisionByName(name ...string) []*Division {
 }
 
 // SearchDivisionByName 按照一个行政区划名称搜索行政节点层级链, 必须以省级行政区开始查询,
-// 如果查找不到则按照前缀匹配
+// 如果查找不到则按照前缀匹配。每一级名称也可以使用不带声调的全拼或首字母, 如 ("guangdong", "gz")
+// 或 ("gd", "shenzhen")
 func SearchDivisionByName(name ...string) []*Division {
 	if len(name) == 0 {
 		return nil
@@ -262,6 +264,16 @@ func searchDivisionByNameAndTryChongqingCounty(res []*Division, name []string) [
 }
 
 func findClosestDivision(curr *Division, name string) *Division {
+	if closest := findClosestDivisionByPrefix(curr, name); closest != nil {
+		return closest
+	}
+	if in, ok := pinyinInput(name); ok {
+		return findClosestDivisionByPinyin(curr, in)
+	}
+	return nil
+}
+
+func findClosestDivisionByPrefix(curr *Division, name string) *Division {
 	// 遍历当前节点寻找接近的名称
 	var closest *Division
 	for _, sub := range curr.SubDivisions() {
@@ -276,6 +288,47 @@ func findClosestDivision(curr *Division, name string) *Division {
 	return closest
 }
 
+// pinyinInput 判断输入是否为拼音, 并返回去掉空格和隔音符号后的小写形式
+func pinyinInput(name string) (string, bool) {
+	b := strings.Builder{}
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z':
+			b.WriteRune(r)
+		case r == ' ' || r == '\'':
+			// skip
+		default:
+			return "", false
+		}
+	}
+	return b.String(), b.Len() > 0
+}
+
+// findClosestDivisionByPinyin 按照全拼或首字母前缀搜索, 全拼匹配优先于首字母匹配
+func findClosestDivisionByPinyin(curr *Division, in string) *Division {
+	var byFull, byLetters *Division
+	better := func(found, sub *Division) bool {
+		return found == nil || (found.deprecated && !sub.deprecated)
+	}
+	for _, sub := range curr.SubDivisions() {
+		if full := pinyin.Join(sub.name, pinyin.NoTone, ""); strings.HasPrefix(full, in) {
+			if better(byFull, sub) {
+				byFull = sub
+			}
+			continue
+		}
+		if letters := pinyin.Join(sub.name, pinyin.FirstLetter, ""); strings.HasPrefix(letters, in) {
+			if better(byLetters, sub) {
+				byLetters = sub
+			}
+		}
+	}
+	if byFull != nil {
+		return byFull
+	}
+	return byLetters
+}
+
 // JoinDivisionCodes 将一个区划链的代码连接成一个字符串。注意, 仅按照层级 join, 不包含最后的补零
 func JoinDivisionCodes(divisions []*Division) string {
 	buff := strings.Builder{}
@@ -126,4 +126,24 @@ func TestDivisionByName(t *testing.T) {
 		so(len(chain), eq, 3)
 		so(ad.JoinDivisionCodes(chain), eq, "500237")
 	})
+
+	cv("拼音匹配", t, func() {
+		chain := ad.SearchDivisionByName("guangdong", "guangzhou")
+		so(ad.JoinDivisionCodes(chain), eq, "4401")
+
+		chain = ad.SearchDivisionByName("广东", "gz")
+		so(ad.JoinDivisionCodes(chain), eq, "4401")
+
+		chain = ad.SearchDivisionByName("GD", "sz")
+		so(ad.JoinDivisionCodes(chain), eq, "4403")
+
+		chain = ad.SearchDivisionByName("chongqing", "yubei")
+		so(ad.JoinDivisionCodes(chain), eq, "500112")
+
+		chain = ad.SearchDivisionByName("hubei", "shennongjia")
+		so(ad.JoinDivisionCodes(chain), eq, "429021")
+
+		chain = ad.SearchDivisionByName("fujian", "xiamen")
+		so(ad.JoinDivisionCodes(chain), eq, "3502")
+	})
 }
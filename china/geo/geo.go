@@ -0,0 +1,179 @@
+// Package geo 实现中国常用坐标系 WGS-84、GCJ-02 (火星坐标) 和 BD-09 (百度坐标) 之间的转换,
+// 以及中国大陆范围判断和球面距离计算
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+// System 表示坐标系
+type System int
+
+const (
+	// WGS84 GPS 原始坐标系, 国际通用
+	WGS84 System = iota
+	// GCJ02 国测局坐标系, 又称火星坐标系。高德、腾讯地图等使用
+	GCJ02
+	// BD09 百度坐标系, 在 GCJ-02 基础上再次加偏
+	BD09
+)
+
+func (s System) String() string {
+	switch s {
+	case WGS84:
+		return "WGS-84"
+	case GCJ02:
+		return "GCJ-02"
+	case BD09:
+		return "BD-09"
+	default:
+		return fmt.Sprintf("非法值 %d", s)
+	}
+}
+
+// Point 表示一个经纬度坐标, 单位为度
+type Point struct {
+	Lng float64 // 经度
+	Lat float64 // 纬度
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("(%.6f, %.6f)", p.Lng, p.Lat)
+}
+
+// Convert 将坐标从一个坐标系转换到另一个坐标系
+func Convert(p Point, from, to System) Point {
+	if from == to {
+		return p
+	}
+	switch from {
+	case GCJ02:
+		p = GCJ02ToWGS84(p)
+	case BD09:
+		p = BD09ToWGS84(p)
+	}
+	switch to {
+	case GCJ02:
+		return WGS84ToGCJ02(p)
+	case BD09:
+		return WGS84ToBD09(p)
+	default:
+		return p
+	}
+}
+
+const (
+	// 克拉索夫斯基椭球参数
+	krasovskyA  = 6378245.0
+	krasovskyEE = 0.00669342162296594323
+
+	xPi = math.Pi * 3000.0 / 180.0
+
+	// 迭代求逆的精度和最大次数
+	inverseThreshold = 1e-12
+	inverseMaxLoop   = 30
+)
+
+// WGS84ToGCJ02 将 WGS-84 坐标转换为 GCJ-02 坐标。中国大陆以外的坐标不做处理
+func WGS84ToGCJ02(p Point) Point {
+	if !InMainlandChina(p) {
+		return p
+	}
+	d := gcj02Delta(p)
+	return Point{Lng: p.Lng + d.Lng, Lat: p.Lat + d.Lat}
+}
+
+// GCJ02ToWGS84 将 GCJ-02 坐标转换为 WGS-84 坐标。由于加偏算法不存在解析逆运算, 这里采用迭代逼近,
+// 误差在 1e-9 度 (约 0.1 毫米) 以内
+func GCJ02ToWGS84(p Point) Point {
+	if !InMainlandChina(p) {
+		return p
+	}
+	// 以一次近似的结果作为迭代起点
+	d := gcj02Delta(p)
+	initial := Point{Lng: p.Lng - d.Lng, Lat: p.Lat - d.Lat}
+	return inverse(p, initial, func(wgs Point) Point {
+		d := gcj02Delta(wgs)
+		return Point{Lng: wgs.Lng + d.Lng, Lat: wgs.Lat + d.Lat}
+	})
+}
+
+// inverse 迭代求解 forward(x) = target, 从 initial 开始逼近
+func inverse(target, initial Point, forward func(Point) Point) Point {
+	x := initial
+	for range inverseMaxLoop {
+		y := forward(x)
+		dLng, dLat := y.Lng-target.Lng, y.Lat-target.Lat
+		x.Lng -= dLng
+		x.Lat -= dLat
+		if math.Abs(dLng) < inverseThreshold && math.Abs(dLat) < inverseThreshold {
+			break
+		}
+	}
+	return x
+}
+
+// GCJ02ToBD09 将 GCJ-02 坐标转换为 BD-09 坐标
+func GCJ02ToBD09(p Point) Point {
+	z := math.Sqrt(p.Lng*p.Lng+p.Lat*p.Lat) + 0.00002*math.Sin(p.Lat*xPi)
+	theta := math.Atan2(p.Lat, p.Lng) + 0.000003*math.Cos(p.Lng*xPi)
+	return Point{
+		Lng: z*math.Cos(theta) + 0.0065,
+		Lat: z*math.Sin(theta) + 0.006,
+	}
+}
+
+// BD09ToGCJ02 将 BD-09 坐标转换为 GCJ-02 坐标。先使用常见的近似公式, 再迭代修正到 1e-9 度以内
+func BD09ToGCJ02(p Point) Point {
+	return inverse(p, bd09ToGCJ02Approx(p), GCJ02ToBD09)
+}
+
+func bd09ToGCJ02Approx(p Point) Point {
+	x, y := p.Lng-0.0065, p.Lat-0.006
+	z := math.Sqrt(x*x+y*y) - 0.00002*math.Sin(y*xPi)
+	theta := math.Atan2(y, x) - 0.000003*math.Cos(x*xPi)
+	return Point{
+		Lng: z * math.Cos(theta),
+		Lat: z * math.Sin(theta),
+	}
+}
+
+// WGS84ToBD09 将 WGS-84 坐标转换为 BD-09 坐标
+func WGS84ToBD09(p Point) Point {
+	return GCJ02ToBD09(WGS84ToGCJ02(p))
+}
+
+// BD09ToWGS84 将 BD-09 坐标转换为 WGS-84 坐标
+func BD09ToWGS84(p Point) Point {
+	return GCJ02ToWGS84(BD09ToGCJ02(p))
+}
+
+// gcj02Delta 计算 WGS-84 坐标在 GCJ-02 中的偏移量
+func gcj02Delta(p Point) Point {
+	dLat := transformLat(p.Lng-105.0, p.Lat-35.0)
+	dLng := transformLng(p.Lng-105.0, p.Lat-35.0)
+	radLat := p.Lat / 180.0 * math.Pi
+	magic := math.Sin(radLat)
+	magic = 1 - krasovskyEE*magic*magic
+	sqrtMagic := math.Sqrt(magic)
+	dLat = (dLat * 180.0) / ((krasovskyA * (1 - krasovskyEE)) / (magic * sqrtMagic) * math.Pi)
+	dLng = (dLng * 180.0) / (krasovskyA / sqrtMagic * math.Cos(radLat) * math.Pi)
+	return Point{Lng: dLng, Lat: dLat}
+}
+
+func transformLat(x, y float64) float64 {
+	ret := -100.0 + 2.0*x + 3.0*y + 0.2*y*y + 0.1*x*y + 0.2*math.Sqrt(math.Abs(x))
+	ret += (20.0*math.Sin(6.0*x*math.Pi) + 20.0*math.Sin(2.0*x*math.Pi)) * 2.0 / 3.0
+	ret += (20.0*math.Sin(y*math.Pi) + 40.0*math.Sin(y/3.0*math.Pi)) * 2.0 / 3.0
+	ret += (160.0*math.Sin(y/12.0*math.Pi) + 320*math.Sin(y*math.Pi/30.0)) * 2.0 / 3.0
+	return ret
+}
+
+func transformLng(x, y float64) float64 {
+	ret := 300.0 + x + 2.0*y + 0.1*x*x + 0.1*x*y + 0.1*math.Sqrt(math.Abs(x))
+	ret += (20.0*math.Sin(6.0*x*math.Pi) + 20.0*math.Sin(2.0*x*math.Pi)) * 2.0 / 3.0
+	ret += (20.0*math.Sin(x*math.Pi) + 40.0*math.Sin(x/3.0*math.Pi)) * 2.0 / 3.0
+	ret += (150.0*math.Sin(x/12.0*math.Pi) + 300.0*math.Sin(x/30.0*math.Pi)) * 2.0 / 3.0
+	return ret
+}
@@ -0,0 +1,104 @@
+package geo_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Andrew-M-C/go.util/china/geo"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	cv = convey.Convey
+	so = convey.So
+	eq = convey.ShouldEqual
+
+	almostEq = convey.ShouldAlmostEqual
+	isTrue   = convey.ShouldBeTrue
+	isFalse  = convey.ShouldBeFalse
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(m.Run())
+}
+
+func TestConvert(t *testing.T) {
+	wgs := geo.Point{Lng: 116.404, Lat: 39.915}
+
+	cv("WGS-84 与 GCJ-02", t, func() {
+		gcj := geo.WGS84ToGCJ02(wgs)
+		t.Log(gcj)
+		so(gcj.Lng, almostEq, 116.41024449916938, 1e-9)
+		so(gcj.Lat, almostEq, 39.91640428150164, 1e-9)
+
+		back := geo.GCJ02ToWGS84(gcj)
+		so(back.Lng, almostEq, wgs.Lng, 1e-9)
+		so(back.Lat, almostEq, wgs.Lat, 1e-9)
+	})
+
+	cv("GCJ-02 与 BD-09", t, func() {
+		bd := geo.GCJ02ToBD09(wgs)
+		t.Log(bd)
+		so(bd.Lng, almostEq, 116.41036949371029, 1e-9)
+		so(bd.Lat, almostEq, 39.92133699351021, 1e-9)
+
+		back := geo.BD09ToGCJ02(bd)
+		so(back.Lng, almostEq, wgs.Lng, 1e-9)
+		so(back.Lat, almostEq, wgs.Lat, 1e-9)
+	})
+
+	cv("WGS-84 与 BD-09", t, func() {
+		bd := geo.Convert(wgs, geo.WGS84, geo.BD09)
+		so(bd, eq, geo.WGS84ToBD09(wgs))
+
+		back := geo.Convert(bd, geo.BD09, geo.WGS84)
+		so(back.Lng, almostEq, wgs.Lng, 1e-9)
+		so(back.Lat, almostEq, wgs.Lat, 1e-9)
+
+		so(geo.Convert(wgs, geo.GCJ02, geo.GCJ02), eq, wgs)
+	})
+
+	cv("大陆以外不加偏", t, func() {
+		tokyo := geo.Point{Lng: 139.6917, Lat: 35.6895}
+		so(geo.WGS84ToGCJ02(tokyo), eq, tokyo)
+		so(geo.GCJ02ToWGS84(tokyo), eq, tokyo)
+	})
+}
+
+func TestRegion(t *testing.T) {
+	cv("大陆范围", t, func() {
+		so(geo.InMainlandChina(geo.Point{Lng: 116.404, Lat: 39.915}), isTrue)   // 北京
+		so(geo.InMainlandChina(geo.Point{Lng: 87.6168, Lat: 43.8256}), isTrue)  // 乌鲁木齐
+		so(geo.InMainlandChina(geo.Point{Lng: 109.5120, Lat: 18.2528}), isTrue) // 三亚
+		so(geo.InMainlandChina(geo.Point{Lng: 121.5654, Lat: 25.0330}), isFalse)
+		so(geo.InMainlandChina(geo.Point{Lng: 139.6917, Lat: 35.6895}), isFalse)
+		so(geo.InMainlandChina(geo.Point{Lng: 105.8342, Lat: 21.0278}), isFalse)
+
+		// 港澳
+		so(geo.InMainlandChina(geo.Point{Lng: 114.17, Lat: 22.30}), isFalse) // 香港
+		so(geo.InMainlandChina(geo.Point{Lng: 113.94, Lat: 22.28}), isFalse) // 大屿山
+		so(geo.InMainlandChina(geo.Point{Lng: 113.54, Lat: 22.19}), isFalse) // 澳门
+		so(geo.InMainlandChina(geo.Point{Lng: 114.06, Lat: 22.54}), isTrue)  // 深圳
+		so(geo.InMainlandChina(geo.Point{Lng: 113.92, Lat: 22.48}), isTrue)  // 蛇口
+		so(geo.InMainlandChina(geo.Point{Lng: 113.58, Lat: 22.27}), isTrue)  // 珠海
+		so(geo.InMainlandChina(geo.Point{Lng: 113.50, Lat: 22.13}), isTrue)  // 横琴
+
+		// 中朝边境
+		so(geo.InMainlandChina(geo.Point{Lng: 126.60, Lat: 40.97}), isFalse) // 江界
+		so(geo.InMainlandChina(geo.Point{Lng: 127.00, Lat: 41.00}), isFalse)
+		so(geo.InMainlandChina(geo.Point{Lng: 129.77, Lat: 41.80}), isFalse) // 清津
+		so(geo.InMainlandChina(geo.Point{Lng: 124.38, Lat: 40.13}), isTrue)  // 丹东
+		so(geo.InMainlandChina(geo.Point{Lng: 126.18, Lat: 41.12}), isTrue)  // 集安
+		so(geo.InMainlandChina(geo.Point{Lng: 128.20, Lat: 41.42}), isTrue)  // 长白
+		so(geo.InMainlandChina(geo.Point{Lng: 130.36, Lat: 42.86}), isTrue)  // 珲春
+	})
+
+	cv("距离", t, func() {
+		beijing := geo.Point{Lng: 116.4074, Lat: 39.9042}
+		shanghai := geo.Point{Lng: 121.4737, Lat: 31.2304}
+		d := geo.Distance(beijing, shanghai)
+		t.Logf("北京 - 上海: %.0f m", d)
+		so(d, almostEq, 1068000, 3000)
+		so(geo.Distance(beijing, beijing), eq, 0)
+	})
+}
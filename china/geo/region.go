@@ -0,0 +1,80 @@
+package geo
+
+import "math"
+
+// rect 表示一个经纬度矩形范围
+type rect struct {
+	north, west, south, east float64
+}
+
+func (r rect) contains(p Point) bool {
+	return p.Lat <= r.north && p.Lat >= r.south && p.Lng >= r.west && p.Lng <= r.east
+}
+
+// 以若干矩形近似中国大陆的范围, 再去掉其中的台湾、港澳以及周边国家的部分区域
+var (
+	mainlandRegions = []rect{
+		{49.220400, 79.446200, 42.889900, 96.330000},
+		{54.141500, 109.687200, 39.374200, 135.000200},
+		{42.889900, 73.124600, 29.529700, 124.143255},
+		{29.529700, 82.968400, 26.718600, 97.035200},
+		{29.529700, 97.025300, 20.414096, 124.367395},
+		{20.414096, 107.975793, 17.871542, 111.744104},
+	}
+	mainlandExcludes = []rect{
+		{25.398623, 119.921265, 21.785006, 122.497559},
+		{22.284000, 101.865200, 20.098800, 106.665000},
+		{21.542200, 106.452500, 20.487800, 108.051000},
+		{55.817500, 109.032300, 50.325700, 119.127000},
+		{55.817500, 127.456800, 49.557400, 137.022700},
+		{44.892200, 131.266200, 42.569200, 137.022700},
+		// 香港 (新界、港岛、大屿山) 和澳门 (半岛、氹仔和路环), 使用 WGS-84, 不需要加偏
+		{22.500000, 113.960000, 22.150000, 114.450000},
+		{22.360000, 113.830000, 22.150000, 113.960000},
+		{22.217000, 113.528000, 22.180000, 113.560000},
+		{22.170000, 113.545000, 22.110000, 113.600000},
+		// 朝鲜北部, 沿鸭绿江和图们江阶梯状近似
+		{40.500000, 124.600000, 39.374200, 126.000000},
+		{41.000000, 126.000000, 39.374200, 126.900000},
+		{41.350000, 126.900000, 39.374200, 128.300000},
+		{42.000000, 128.300000, 39.374200, 129.700000},
+		{42.300000, 129.700000, 39.374200, 135.000200},
+	}
+)
+
+// InMainlandChina 粗略判断一个坐标是否位于中国大陆范围内。坐标系之间的偏移不影响判断结果。
+// 只有中国大陆范围内的坐标才需要做 GCJ-02 加偏
+func InMainlandChina(p Point) bool {
+	in := false
+	for _, r := range mainlandRegions {
+		if r.contains(p) {
+			in = true
+			break
+		}
+	}
+	if !in {
+		return false
+	}
+	for _, r := range mainlandExcludes {
+		if r.contains(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// EarthRadius 地球平均半径, 单位为米
+const EarthRadius = 6371008.8
+
+// Distance 使用 haversine 公式计算两个坐标之间的球面距离, 单位为米。两个坐标应当属于同一坐标系
+func Distance(a, b Point) float64 {
+	rad := func(d float64) float64 {
+		return d * math.Pi / 180
+	}
+	lat1, lat2 := rad(a.Lat), rad(b.Lat)
+	dLat := lat2 - lat1
+	dLng := rad(b.Lng - a.Lng)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
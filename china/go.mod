@@ -8,6 +8,7 @@ require (
 	github.com/Andrew-M-C/go.util/slices v0.0.0-20260112083547-2bd245af81b5
 	github.com/Andrew-M-C/go.util/sync v0.0.0-20260112083547-2bd245af81b5
 	github.com/fatih/color v1.18.0
+	github.com/mozillazg/go-pinyin v0.21.0
 	github.com/smartystreets/goconvey v1.8.1
 )
 
@@ -0,0 +1,129 @@
+package numeral
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// FormatMoney 将以 "分" 为单位的金额格式化为财务大写金额, 如 100500 格式化为 "壹仟零伍元整",
+// 100530 格式化为 "壹仟零伍元叁角整", 100505 格式化为 "壹仟零伍元零伍分"
+func FormatMoney(fen int64) string {
+	if fen < 0 {
+		return "负" + formatMoney(uint64(-fen))
+	}
+	return formatMoney(uint64(fen))
+}
+
+// FormatMoneyYuan 将以 "元" 为单位的金额四舍五入到分之后格式化为财务大写金额
+func FormatMoneyYuan(yuan float64) string {
+	return FormatMoney(int64(math.Round(yuan * 100)))
+}
+
+func formatMoney(fen uint64) string {
+	if fen == 0 {
+		return "零元整"
+	}
+
+	yuan, jiao, fen := fen/100, fen/10%10, fen%10
+	b := strings.Builder{}
+	if yuan > 0 {
+		b.WriteString(formatInteger(yuan, upperDigits, upperUnits, false))
+		b.WriteString("元")
+	}
+
+	switch {
+	case jiao == 0 && fen == 0:
+		b.WriteString("整")
+	case jiao == 0:
+		if yuan > 0 {
+			b.WriteString(upperDigits[0])
+		}
+		b.WriteString(upperDigits[fen])
+		b.WriteString("分")
+	case fen == 0:
+		b.WriteString(upperDigits[jiao])
+		b.WriteString("角整")
+	default:
+		b.WriteString(upperDigits[jiao])
+		b.WriteString("角")
+		b.WriteString(upperDigits[fen])
+		b.WriteString("分")
+	}
+	return b.String()
+}
+
+// ParseMoney 解析财务大写金额, 返回以 "分" 为单位的金额。支持 "人民币" 前缀、"圆" 和 "正" 等写法,
+// 小写数字同样可以解析, 如 "一百元五角"
+func ParseMoney(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "人民币")
+	neg := false
+	if strings.HasPrefix(s, "负") {
+		neg, s = true, strings.TrimPrefix(s, "负")
+	}
+	s = strings.TrimRight(s, "整正")
+	if s == "" {
+		return 0, errors.New("empty amount")
+	}
+
+	var yuan, jiao, fen uint64
+	rest := s
+	if before, after, found := cutAny(rest, "元", "圆"); found {
+		n, err := parseUint(before)
+		if err != nil {
+			return 0, fmt.Errorf("invalid yuan part: %w", err)
+		}
+		yuan, rest = n, after
+	}
+	rest = strings.TrimPrefix(rest, "零")
+	if before, after, found := strings.Cut(rest, "角"); found {
+		n, err := parseMoneyDigit(before)
+		if err != nil {
+			return 0, fmt.Errorf("invalid jiao part: %w", err)
+		}
+		jiao, rest = n, after
+	}
+	rest = strings.TrimPrefix(rest, "零")
+	if before, after, found := strings.Cut(rest, "分"); found {
+		n, err := parseMoneyDigit(before)
+		if err != nil {
+			return 0, fmt.Errorf("invalid fen part: %w", err)
+		}
+		fen, rest = n, after
+	}
+	if rest != "" {
+		return 0, fmt.Errorf("unexpected '%s'", rest)
+	}
+
+	total := yuan*100 + jiao*10 + fen
+	if total > math.MaxInt64 {
+		return 0, fmt.Errorf("'%s' out of range", s)
+	}
+	if neg {
+		return -int64(total), nil
+	}
+	return int64(total), nil
+}
+
+func parseMoneyDigit(s string) (uint64, error) {
+	runes := []rune(s)
+	if len(runes) != 1 {
+		return 0, fmt.Errorf("invalid digit '%s'", s)
+	}
+	d, exist := digitValues[runes[0]]
+	if !exist {
+		return 0, fmt.Errorf("invalid digit '%s'", s)
+	}
+	return d, nil
+}
+
+func cutAny(s string, seps ...string) (before, after string, found bool) {
+	for _, sep := range seps {
+		if before, after, found = strings.Cut(s, sep); found {
+			return
+		}
+	}
+	return s, "", false
+}
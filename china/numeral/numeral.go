@@ -0,0 +1,277 @@
+// Package numeral 实现中文数字的格式化与解析, 包括小写数字 (一千零五)、财务大写金额 (壹仟零伍元整)
+// 以及万/亿混合写法 (1.2亿)
+package numeral
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	lowerDigits = []string{"零", "一", "二", "三", "四", "五", "六", "七", "八", "九"}
+	upperDigits = []string{"零", "壹", "贰", "叁", "肆", "伍", "陆", "柒", "捌", "玖"}
+
+	lowerUnits = []string{"", "十", "百", "千"}
+	upperUnits = []string{"", "拾", "佰", "仟"}
+)
+
+// FormatInt 将整数格式化为中文小写数字, 如 1005 格式化为 "一千零五", 15 格式化为 "十五"
+func FormatInt(n int64) string {
+	if n < 0 {
+		return "负" + FormatUint(uint64(-n))
+	}
+	return FormatUint(uint64(n))
+}
+
+// FormatUint 将无符号整数格式化为中文小写数字
+func FormatUint(n uint64) string {
+	return formatInteger(n, lowerDigits, lowerUnits, true)
+}
+
+// FormatIntUpper 将整数格式化为中文大写数字, 如 1005 格式化为 "壹仟零伍", 15 格式化为 "壹拾伍"
+func FormatIntUpper(n int64) string {
+	if n < 0 {
+		return "负" + formatInteger(uint64(-n), upperDigits, upperUnits, false)
+	}
+	return formatInteger(uint64(n), upperDigits, upperUnits, false)
+}
+
+// FormatFloat 将浮点数格式化为中文小写数字, 如 3.14 格式化为 "三点一四"。prec 表示小数位数,
+// 含义与 strconv.FormatFloat 相同, -1 表示使用最少的位数
+func FormatFloat(f float64, prec int) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'f', prec, 64)
+	}
+	s := strconv.FormatFloat(math.Abs(f), 'f', prec, 64)
+	intPart, fracPart, _ := strings.Cut(s, ".")
+
+	b := strings.Builder{}
+	if f < 0 {
+		b.WriteString("负")
+	}
+	n, _ := strconv.ParseUint(intPart, 10, 64)
+	b.WriteString(FormatUint(n))
+	if fracPart != "" {
+		b.WriteString("点")
+		for _, r := range fracPart {
+			b.WriteString(lowerDigits[r-'0'])
+		}
+	}
+	return b.String()
+}
+
+// formatInteger 按照中文读法格式化整数。omitLeadingOne 表示 10 ~ 19 开头时是否省略 "一", 如 "十五"
+func formatInteger(n uint64, digits, units []string, omitLeadingOne bool) string {
+	if n == 0 {
+		return digits[0]
+	}
+	s := formatLarge(n, digits, units)
+	if omitLeadingOne && strings.HasPrefix(s, digits[1]+units[1]) {
+		s = strings.TrimPrefix(s, digits[1])
+	}
+	return s
+}
+
+// formatLarge 按照万、亿逐级格式化。超过一万亿的部分按照 "三万四千亿"、"九百二十二亿……亿" 的方式嵌套
+func formatLarge(n uint64, digits, units []string) string {
+	type level struct {
+		value uint64
+		unit  string
+	}
+	for _, l := range []level{{100000000, "亿"}, {10000, "万"}} {
+		if n < l.value {
+			continue
+		}
+		high, low := n/l.value, n%l.value
+		s := formatLarge(high, digits, units) + l.unit
+		if low == 0 {
+			return s
+		}
+		// 低位不足最高位时需要补零, 如 "一万零五"、"一亿零一千万" 中的 "零"
+		if low < l.value/10 {
+			s += digits[0]
+		}
+		return s + formatLarge(low, digits, units)
+	}
+	return formatSection(n, digits, units)
+}
+
+// formatSection 格式化 1 ~ 9999 之间的一节数字
+func formatSection(sec uint64, digits, units []string) string {
+	pow := []uint64{1, 10, 100, 1000}
+	b := strings.Builder{}
+	zero := false
+	for i := 3; i >= 0; i-- {
+		d := sec / pow[i] % 10
+		if d == 0 {
+			zero = b.Len() > 0
+			continue
+		}
+		if zero {
+			b.WriteString(digits[0])
+			zero = false
+		}
+		b.WriteString(digits[d])
+		b.WriteString(units[i])
+	}
+	return b.String()
+}
+
+var digitValues = map[rune]uint64{
+	'零': 0, '〇': 0, '○': 0,
+	'一': 1, '壹': 1, '幺': 1,
+	'二': 2, '贰': 2, '两': 2, '貳': 2,
+	'三': 3, '叁': 3, '參': 3,
+	'四': 4, '肆': 4,
+	'五': 5, '伍': 5,
+	'六': 6, '陆': 6, '陸': 6,
+	'七': 7, '柒': 7,
+	'八': 8, '捌': 8,
+	'九': 9, '玖': 9,
+}
+
+var unitValues = map[rune]uint64{
+	'十': 10, '拾': 10,
+	'百': 100, '佰': 100,
+	'千': 1000, '仟': 1000,
+}
+
+// ParseInt 解析中文数字, 同时支持小写和大写, 如 "一千零五"、"壹仟零伍"、"十五"、"负三"。
+// 不带单位的数字按照逐位读法解析, 如 "一九八四" 解析为 1984
+func ParseInt(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	neg := false
+	if strings.HasPrefix(s, "负") {
+		neg, s = true, strings.TrimPrefix(s, "负")
+	}
+	n, err := parseUint(s)
+	if err != nil {
+		return 0, err
+	}
+	if n > math.MaxInt64 {
+		return 0, fmt.Errorf("'%s' out of range", s)
+	}
+	if neg {
+		return -int64(n), nil
+	}
+	return int64(n), nil
+}
+
+// ParseFloat 解析带小数的中文数字, 如 "三点一四"、"负零点五"
+func ParseFloat(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	neg := false
+	if strings.HasPrefix(s, "负") {
+		neg, s = true, strings.TrimPrefix(s, "负")
+	}
+	intPart, fracPart, hasFrac := strings.Cut(s, "点")
+
+	n, err := parseUint(intPart)
+	if err != nil {
+		return 0, err
+	}
+	b := strings.Builder{}
+	b.WriteString(strconv.FormatUint(n, 10))
+	if hasFrac {
+		if fracPart == "" {
+			return 0, errors.New("missing fraction digits")
+		}
+		b.WriteByte('.')
+		for _, r := range fracPart {
+			d, exist := digitValues[r]
+			if !exist {
+				return 0, fmt.Errorf("invalid fraction character '%c'", r)
+			}
+			b.WriteByte(byte('0' + d))
+		}
+	}
+
+	f, err := strconv.ParseFloat(b.String(), 64)
+	if err != nil {
+		return 0, err
+	}
+	if neg {
+		f = -f
+	}
+	return f, nil
+}
+
+func parseUint(s string) (uint64, error) {
+	if s == "" {
+		return 0, errors.New("empty number")
+	}
+	if isDigitSequence(s) {
+		return parseDigitSequence(s)
+	}
+
+	// parts 保存已经乘上节权位的各节数值及其实际的权位。遇到万、亿时, 前面权位不超过它的各节
+	// 都属于它的系数, 因此 "三万四千亿"、"一万亿" 以及嵌套的 "……亿……亿" 都可以正确解析
+	type part struct {
+		value, magnitude uint64
+	}
+	var parts []part
+	var section, number uint64
+
+	for _, r := range s {
+		if d, exist := digitValues[r]; exist {
+			number = d
+			continue
+		}
+		if u, exist := unitValues[r]; exist {
+			// "十五" 中省略了开头的 "一"
+			if number == 0 && u == 10 {
+				number = 1
+			}
+			section += number * u
+			number = 0
+			continue
+		}
+
+		var mag uint64
+		switch r {
+		case '万', '萬':
+			mag = 10000
+		case '亿', '億':
+			mag = 100000000
+		default:
+			return 0, fmt.Errorf("invalid character '%c'", r)
+		}
+		curr, maxMag := section+number, uint64(1)
+		section, number = 0, 0
+		for len(parts) > 0 && parts[len(parts)-1].magnitude <= mag {
+			last := parts[len(parts)-1]
+			curr += last.value
+			maxMag = max(maxMag, last.magnitude)
+			parts = parts[:len(parts)-1]
+		}
+		parts = append(parts, part{value: curr * mag, magnitude: maxMag * mag})
+	}
+
+	total := section + number
+	for _, p := range parts {
+		total += p.value
+	}
+	return total, nil
+}
+
+func isDigitSequence(s string) bool {
+	n := 0
+	for _, r := range s {
+		if _, exist := digitValues[r]; !exist {
+			return false
+		}
+		n++
+	}
+	return n > 1
+}
+
+func parseDigitSequence(s string) (uint64, error) {
+	b := strings.Builder{}
+	for _, r := range s {
+		b.WriteByte(byte('0' + digitValues[r]))
+	}
+	return strconv.ParseUint(b.String(), 10, 64)
+}
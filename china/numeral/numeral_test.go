@@ -0,0 +1,193 @@
+package numeral_test
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/Andrew-M-C/go.util/china/numeral"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	cv = convey.Convey
+	so = convey.So
+	eq = convey.ShouldEqual
+
+	isNil = convey.ShouldBeNil
+	isErr = convey.ShouldBeError
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(m.Run())
+}
+
+func TestInteger(t *testing.T) {
+	cases := map[int64]string{
+		0:             "零",
+		5:             "五",
+		10:            "十",
+		15:            "十五",
+		110:           "一百一十",
+		1005:          "一千零五",
+		1050:          "一千零五十",
+		10005:         "一万零五",
+		100000:        "十万",
+		120000000:     "一亿二千万",
+		100001000:     "一亿零一千",
+		100000005:     "一亿零五",
+		10000100:      "一千万零一百",
+		1000000000000: "一万亿",
+		3400000000000: "三万四千亿",
+		-203:          "负二百零三",
+	}
+
+	cv("小写格式化与解析", t, func() {
+		for n, s := range cases {
+			so(numeral.FormatInt(n), eq, s)
+			res, err := numeral.ParseInt(s)
+			so(err, isNil)
+			so(res, eq, n)
+		}
+	})
+
+	cv("大写格式化与解析", t, func() {
+		so(numeral.FormatIntUpper(15), eq, "壹拾伍")
+		so(numeral.FormatIntUpper(1005), eq, "壹仟零伍")
+
+		n, err := numeral.ParseInt("壹万零伍佰")
+		so(err, isNil)
+		so(n, eq, 10500)
+	})
+
+	cv("其他写法", t, func() {
+		n, err := numeral.ParseInt("两千")
+		so(err, isNil)
+		so(n, eq, 2000)
+
+		n, err = numeral.ParseInt("一九八四")
+		so(err, isNil)
+		so(n, eq, 1984)
+
+		n, err = numeral.ParseInt("二〇二六")
+		so(err, isNil)
+		so(n, eq, 2026)
+
+		_, err = numeral.ParseInt("一千零五块")
+		so(err, isErr)
+		_, err = numeral.ParseInt("")
+		so(err, isErr)
+	})
+
+	cv("边界值", t, func() {
+		s := numeral.FormatInt(math.MaxInt64)
+		n, err := numeral.ParseInt(s)
+		so(err, isNil)
+		so(n, eq, int64(math.MaxInt64))
+
+		s = numeral.FormatInt(math.MinInt64 + 1)
+		n, err = numeral.ParseInt(s)
+		so(err, isNil)
+		so(n, eq, int64(math.MinInt64+1))
+	})
+}
+
+func TestFloat(t *testing.T) {
+	cv("小数", t, func() {
+		so(numeral.FormatFloat(3.14, -1), eq, "三点一四")
+		so(numeral.FormatFloat(-0.5, 2), eq, "负零点五零")
+		so(numeral.FormatFloat(12, 0), eq, "十二")
+
+		f, err := numeral.ParseFloat("三点一四")
+		so(err, isNil)
+		so(f, eq, 3.14)
+
+		f, err = numeral.ParseFloat("负十二点零五")
+		so(err, isNil)
+		so(f, eq, -12.05)
+
+		_, err = numeral.ParseFloat("三点")
+		so(err, isErr)
+	})
+}
+
+func TestMoney(t *testing.T) {
+	cases := map[int64]string{
+		0:          "零元整",
+		100:        "壹元整",
+		100500:     "壹仟零伍元整",
+		100530:     "壹仟零伍元叁角整",
+		100505:     "壹仟零伍元零伍分",
+		100535:     "壹仟零伍元叁角伍分",
+		5:          "伍分",
+		50:         "伍角整",
+		1000000000: "壹仟万元整",
+		1500000000: "壹仟伍佰万元整",
+		-1200:      "负壹拾贰元整",
+	}
+
+	cv("大写金额格式化与解析", t, func() {
+		for fen, s := range cases {
+			so(numeral.FormatMoney(fen), eq, s)
+			res, err := numeral.ParseMoney(s)
+			so(err, isNil)
+			so(res, eq, fen)
+		}
+	})
+
+	cv("以元为单位", t, func() {
+		so(numeral.FormatMoneyYuan(1005.3), eq, "壹仟零伍元叁角整")
+		so(numeral.FormatMoneyYuan(0.015), eq, "贰分")
+	})
+
+	cv("其他写法", t, func() {
+		fen, err := numeral.ParseMoney("人民币壹万圆正")
+		so(err, isNil)
+		so(fen, eq, 1000000)
+
+		fen, err = numeral.ParseMoney("一百元五角")
+		so(err, isNil)
+		so(fen, eq, 10050)
+
+		_, err = numeral.ParseMoney("壹佰元伍")
+		so(err, isErr)
+		_, err = numeral.ParseMoney("壹佰元拾角")
+		so(err, isErr)
+	})
+}
+
+func TestMixed(t *testing.T) {
+	cv("格式化", t, func() {
+		so(numeral.FormatMixed(120000000, 2), eq, "1.2亿")
+		so(numeral.FormatMixed(35000, 1), eq, "3.5万")
+		so(numeral.FormatMixed(1234, 2), eq, "1234")
+		so(numeral.FormatMixed(12345, 0), eq, "1万")
+		so(numeral.FormatMixed(-2.5e12, 1), eq, "-2.5万亿")
+		so(numeral.FormatMixed(99999999, 1), eq, "1亿")
+	})
+
+	cv("解析", t, func() {
+		f, err := numeral.ParseMixed("1.2亿")
+		so(err, isNil)
+		so(f, eq, 1.2e8)
+
+		f, err = numeral.ParseMixed("3.5万")
+		so(err, isNil)
+		so(f, eq, 35000)
+
+		f, err = numeral.ParseMixed("-2万亿")
+		so(err, isNil)
+		so(f, eq, -2e12)
+
+		f, err = numeral.ParseMixed("1,200")
+		so(err, isNil)
+		so(f, eq, 1200)
+
+		f, err = numeral.ParseMixed("一点二亿")
+		so(err, isNil)
+		so(f, eq, 1.2e8)
+
+		_, err = numeral.ParseMixed("abc万")
+		so(err, isErr)
+	})
+}
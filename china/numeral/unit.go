@@ -0,0 +1,79 @@
+package numeral
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var mixedUnits = []struct {
+	unit  string
+	value float64
+}{
+	{"万亿", 1e12},
+	{"亿", 1e8},
+	{"万", 1e4},
+}
+
+// FormatMixed 将数字格式化为阿拉伯数字与万/亿混合的写法, 如 120000000 格式化为 "1.2亿",
+// 35000 格式化为 "3.5万"。prec 表示最多保留的小数位数, 末尾的零会被去掉。小于一万的数字不带单位
+func FormatMixed(f float64, prec int) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	if prec < 0 {
+		prec = 0
+	}
+
+	abs := math.Abs(f)
+	for i, u := range mixedUnits {
+		if abs < u.value {
+			continue
+		}
+		// 四舍五入之后可能需要进位到更高的单位, 如 99999999 保留一位小数为 "1亿" 而不是 "10000万"
+		if i > 0 && roundTo(abs/u.value, prec) >= 1e4 {
+			u = mixedUnits[i-1]
+		}
+		return trimFloat(f/u.value, prec) + u.unit
+	}
+	return trimFloat(f, prec)
+}
+
+// ParseMixed 解析阿拉伯数字与万/亿混合的写法, 如 "1.2亿"、"3.5万"、"-2万亿"、"1,200"。
+// 单位之前的部分也可以是中文数字, 如 "一点二亿"
+func ParseMixed(s string) (float64, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	mul := 1.0
+	for _, u := range mixedUnits {
+		if strings.HasSuffix(s, u.unit) {
+			s, mul = strings.TrimSuffix(s, u.unit), u.value
+			break
+		}
+	}
+	s = strings.TrimSpace(s)
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		if f, err = ParseFloat(s); err != nil {
+			return 0, fmt.Errorf("invalid number '%s': %w", s, err)
+		}
+	}
+	return f * mul, nil
+}
+
+func roundTo(f float64, prec int) float64 {
+	pow := math.Pow10(prec)
+	return math.Round(f*pow) / pow
+}
+
+func trimFloat(f float64, prec int) string {
+	s := strconv.FormatFloat(roundTo(f, prec), 'f', prec, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
@@ -0,0 +1,336 @@
+package phone
+
+// reference: 中华人民共和国长途电话区号表。仅包含大陆地区, 以地级行政区为单位
+func init() {
+	areaCodes := map[string][]string{
+		"010":  {"11"},
+		"021":  {"31"},
+		"022":  {"12"},
+		"023":  {"50"},
+		"024":  {"2101"},
+		"025":  {"3201"},
+		"027":  {"4201"},
+		"028":  {"5101", "5114", "5120"},
+		"029":  {"6101", "6104"},
+		"020":  {"4401"},
+		"0311": {"1301"},
+		"0315": {"1302"},
+		"0335": {"1303"},
+		"0310": {"1304"},
+		"0319": {"1305"},
+		"0312": {"1306"},
+		"0313": {"1307"},
+		"0314": {"1308"},
+		"0317": {"1309"},
+		"0316": {"1310"},
+		"0318": {"1311"},
+		"0351": {"1401"},
+		"0352": {"1402"},
+		"0353": {"1403"},
+		"0355": {"1404"},
+		"0356": {"1405"},
+		"0349": {"1406"},
+		"0354": {"1407"},
+		"0359": {"1408"},
+		"0350": {"1409"},
+		"0357": {"1410"},
+		"0358": {"1411"},
+		"0471": {"1501"},
+		"0472": {"1502"},
+		"0473": {"1503"},
+		"0476": {"1504"},
+		"0475": {"1505"},
+		"0477": {"1506"},
+		"0470": {"1507"},
+		"0478": {"1508"},
+		"0474": {"1509"},
+		"0482": {"1522"},
+		"0479": {"1525"},
+		"0483": {"1529"},
+		"0411": {"2102"},
+		"0412": {"2103"},
+		"0413": {"2104"},
+		"0414": {"2105"},
+		"0415": {"2106"},
+		"0416": {"2107"},
+		"0417": {"2108"},
+		"0418": {"2109"},
+		"0419": {"2110"},
+		"0427": {"2111"},
+		"0410": {"2112"},
+		"0421": {"2113"},
+		"0429": {"2114"},
+		"0431": {"2201"},
+		"0432": {"2202"},
+		"0434": {"2203"},
+		"0437": {"2204"},
+		"0435": {"2205"},
+		"0439": {"2206"},
+		"0438": {"2207"},
+		"0436": {"2208"},
+		"0433": {"2224"},
+		"0451": {"2301"},
+		"0452": {"2302"},
+		"0467": {"2303"},
+		"0468": {"2304"},
+		"0469": {"2305"},
+		"0459": {"2306"},
+		"0458": {"2307"},
+		"0454": {"2308"},
+		"0464": {"2309"},
+		"0453": {"2310"},
+		"0456": {"2311"},
+		"0455": {"2312"},
+		"0457": {"2327"},
+		"0510": {"3202"},
+		"0516": {"3203"},
+		"0519": {"3204"},
+		"0512": {"3205"},
+		"0513": {"3206"},
+		"0518": {"3207"},
+		"0517": {"3208"},
+		"0515": {"3209"},
+		"0514": {"3210"},
+		"0511": {"3211"},
+		"0523": {"3212"},
+		"0527": {"3213"},
+		"0571": {"3301"},
+		"0574": {"3302"},
+		"0577": {"3303"},
+		"0573": {"3304"},
+		"0572": {"3305"},
+		"0575": {"3306"},
+		"0579": {"3307"},
+		"0570": {"3308"},
+		"0580": {"3309"},
+		"0576": {"3310"},
+		"0578": {"3311"},
+		"0551": {"3401"},
+		"0553": {"3402"},
+		"0552": {"3403"},
+		"0554": {"3404"},
+		"0555": {"3405"},
+		"0561": {"3406"},
+		"0562": {"3407"},
+		"0556": {"3408"},
+		"0559": {"3410"},
+		"0550": {"3411"},
+		"0558": {"3412", "3416"},
+		"0557": {"3413"},
+		"0564": {"3415"},
+		"0566": {"3417"},
+		"0563": {"3418"},
+		"0591": {"3501"},
+		"0592": {"3502"},
+		"0594": {"3503"},
+		"0598": {"3504"},
+		"0595": {"3505"},
+		"0596": {"3506"},
+		"0599": {"3507"},
+		"0597": {"3508"},
+		"0593": {"3509"},
+		"0791": {"3601"},
+		"0798": {"3602"},
+		"0799": {"3603"},
+		"0792": {"3604"},
+		"0790": {"3605"},
+		"0701": {"3606"},
+		"0797": {"3607"},
+		"0796": {"3608"},
+		"0795": {"3609"},
+		"0794": {"3610"},
+		"0793": {"3611"},
+		"0531": {"3701"},
+		"0532": {"3702"},
+		"0533": {"3703"},
+		"0632": {"3704"},
+		"0546": {"3705"},
+		"0535": {"3706"},
+		"0536": {"3707"},
+		"0537": {"3708"},
+		"0538": {"3709"},
+		"0631": {"3710"},
+		"0633": {"3711"},
+		"0539": {"3713"},
+		"0534": {"3714"},
+		"0635": {"3715"},
+		"0543": {"3716"},
+		"0530": {"3717"},
+		"0371": {"4101"},
+		"0378": {"4102"},
+		"0379": {"4103"},
+		"0375": {"4104"},
+		"0372": {"4105"},
+		"0392": {"4106"},
+		"0373": {"4107"},
+		"0391": {"4108", "419001"},
+		"0393": {"4109"},
+		"0374": {"4110"},
+		"0395": {"4111"},
+		"0398": {"4112"},
+		"0377": {"4113"},
+		"0370": {"4114"},
+		"0376": {"4115"},
+		"0394": {"4116"},
+		"0396": {"4117"},
+		"0714": {"4202"},
+		"0719": {"4203", "429021"},
+		"0717": {"4205"},
+		"0710": {"4206"},
+		"0711": {"4207"},
+		"0724": {"4208"},
+		"0712": {"4209"},
+		"0716": {"4210"},
+		"0713": {"4211"},
+		"0715": {"4212"},
+		"0722": {"4213"},
+		"0718": {"4228"},
+		"0728": {"429004", "429005", "429006"},
+		"0731": {"4301", "4302", "4303"},
+		"0734": {"4304"},
+		"0739": {"4305"},
+		"0730": {"4306"},
+		"0736": {"4307"},
+		"0744": {"4308"},
+		"0737": {"4309"},
+		"0735": {"4310"},
+		"0746": {"4311"},
+		"0745": {"4312"},
+		"0738": {"4313"},
+		"0743": {"4331"},
+		"0751": {"4402"},
+		"0755": {"4403"},
+		"0756": {"4404"},
+		"0754": {"4405"},
+		"0757": {"4406"},
+		"0750": {"4407"},
+		"0759": {"4408"},
+		"0668": {"4409"},
+		"0758": {"4412"},
+		"0752": {"4413"},
+		"0753": {"4414"},
+		"0660": {"4415"},
+		"0762": {"4416"},
+		"0662": {"4417"},
+		"0763": {"4418"},
+		"0769": {"4419"},
+		"0760": {"4420"},
+		"0768": {"4451"},
+		"0663": {"4452"},
+		"0766": {"4453"},
+		"0771": {"4501", "4514"},
+		"0772": {"4502", "4513"},
+		"0773": {"4503"},
+		"0774": {"4504", "4511"},
+		"0779": {"4505"},
+		"0770": {"4506"},
+		"0777": {"4507"},
+		"0775": {"4508", "4509"},
+		"0776": {"4510"},
+		"0778": {"4512"},
+		"0898": {"46"},
+		"0813": {"5103"},
+		"0812": {"5104"},
+		"0830": {"5105"},
+		"0838": {"5106"},
+		"0816": {"5107"},
+		"0839": {"5108"},
+		"0825": {"5109"},
+		"0832": {"5110"},
+		"0833": {"5111"},
+		"0817": {"5113"},
+		"0831": {"5115"},
+		"0826": {"5116"},
+		"0818": {"5117"},
+		"0835": {"5118"},
+		"0827": {"5119"},
+		"0837": {"5132"},
+		"0836": {"5133"},
+		"0834": {"5134"},
+		"0851": {"5201"},
+		"0858": {"5202"},
+		"0852": {"5203"},
+		"0853": {"5204"},
+		"0857": {"5205"},
+		"0856": {"5206"},
+		"0859": {"5223"},
+		"0855": {"5226"},
+		"0854": {"5227"},
+		"0871": {"5301"},
+		"0874": {"5303"},
+		"0877": {"5304"},
+		"0875": {"5305"},
+		"0870": {"5306"},
+		"0888": {"5307"},
+		"0879": {"5308"},
+		"0883": {"5309"},
+		"0878": {"5323"},
+		"0873": {"5325"},
+		"0876": {"5326"},
+		"0691": {"5328"},
+		"0872": {"5329"},
+		"0692": {"5331"},
+		"0886": {"5333"},
+		"0887": {"5334"},
+		"0891": {"5401"},
+		"0892": {"5402"},
+		"0895": {"5403"},
+		"0894": {"5404"},
+		"0893": {"5405"},
+		"0896": {"5406"},
+		"0897": {"5425"},
+		"0919": {"6102"},
+		"0917": {"6103"},
+		"0913": {"6105"},
+		"0911": {"6106"},
+		"0916": {"6107"},
+		"0912": {"6108"},
+		"0915": {"6109"},
+		"0914": {"6110"},
+		"0931": {"6201"},
+		"0937": {"6202", "6209"},
+		"0935": {"6203", "6206"},
+		"0943": {"6204"},
+		"0938": {"6205"},
+		"0936": {"6207"},
+		"0933": {"6208"},
+		"0934": {"6210"},
+		"0932": {"6211"},
+		"0939": {"6212"},
+		"0930": {"6229"},
+		"0941": {"6230"},
+		"0971": {"6301"},
+		"0972": {"6302"},
+		"0970": {"6322"},
+		"0973": {"6323"},
+		"0974": {"6325"},
+		"0975": {"6326"},
+		"0976": {"6327"},
+		"0977": {"6328"},
+		"0951": {"6401"},
+		"0952": {"6402"},
+		"0953": {"6403"},
+		"0954": {"6404"},
+		"0955": {"6405"},
+		"0991": {"6501"},
+		"0990": {"6502"},
+		"0995": {"6504"},
+		"0902": {"6505"},
+		"0994": {"6523"},
+		"0909": {"6527"},
+		"0996": {"6528"},
+		"0997": {"6529"},
+		"0908": {"6530"},
+		"0998": {"6531"},
+		"0903": {"6532"},
+		"0999": {"6540"},
+		"0901": {"6542"},
+		"0906": {"6543"},
+		"0993": {"659001"},
+	}
+	for area, divisions := range areaCodes {
+		if err := SetAreaCode(area, divisions...); err != nil {
+			panic(err)
+		}
+	}
+}
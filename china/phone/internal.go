@@ -0,0 +1,13 @@
+package phone
+
+import (
+	syncutil "github.com/Andrew-M-C/go.util/sync"
+)
+
+var internal = struct {
+	segments  syncutil.Map[string, SegmentInfo]
+	areaCodes syncutil.Map[string, []string]
+}{
+	segments:  syncutil.NewMap[string, SegmentInfo](),
+	areaCodes: syncutil.NewMap[string, []string](),
+}
@@ -0,0 +1,209 @@
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Andrew-M-C/go.util/china/admindivision"
+)
+
+// Landline 表示一个中国大陆固定电话号码
+type Landline struct {
+	areaCode  string
+	number    string
+	extension string
+}
+
+// ParseLandline 解析一个固定电话号码, 支持以下格式:
+//
+//   - "010-12345678", "0755 1234 5678", "(0755)12345678"
+//   - "+86 755 12345678", "0086-10-12345678"
+//   - "075512345678", 不带分隔符时按照区号规则自动切分
+//   - "0755-12345678-123", 最后一段为分机号
+//
+// 区号必须已登记, 否则返回错误
+func ParseLandline(s string) (Landline, error) {
+	s = strings.TrimSpace(s)
+	international := false
+	switch {
+	case strings.HasPrefix(s, "+86"):
+		s, international = s[3:], true
+	case strings.HasPrefix(s, "0086"):
+		s, international = s[4:], true
+	}
+
+	// 括号视为区号分隔符, 空白字符视为普通分隔符
+	s = strings.NewReplacer(
+		"(", "", "（", "",
+		")", "-", "）", "-",
+		" ", "", "\t", "", "　", "",
+	).Replace(s)
+	parts := slices.DeleteFunc(strings.Split(s, "-"), func(p string) bool {
+		return p == ""
+	})
+	if len(parts) == 0 {
+		return Landline{}, errors.New("empty number")
+	}
+	if international && !strings.HasPrefix(parts[0], "0") {
+		parts[0] = "0" + parts[0]
+	}
+	for _, p := range parts {
+		if err := validateDigits(p); err != nil {
+			return Landline{}, err
+		}
+	}
+
+	l := Landline{}
+	switch len(parts) {
+	case 1:
+		l.areaCode, l.number = splitAreaCode(parts[0])
+	case 2:
+		l.areaCode, l.number = parts[0], parts[1]
+	case 3:
+		l.areaCode, l.number, l.extension = parts[0], parts[1], parts[2]
+	default:
+		return Landline{}, errors.New("too many parts")
+	}
+
+	if err := l.validate(); err != nil {
+		return Landline{}, err
+	}
+	return l, nil
+}
+
+func splitAreaCode(s string) (area, number string) {
+	if len(s) < 4 || s[0] != '0' {
+		return "", s
+	}
+	if s[1] == '1' || s[1] == '2' {
+		return s[:3], s[3:]
+	}
+	return s[:4], s[4:]
+}
+
+func (l Landline) validate() error {
+	switch {
+	case l.areaCode == "":
+		return errors.New("missing area code")
+	case l.areaCode[0] != '0':
+		return fmt.Errorf("invalid area code '%s'", l.areaCode)
+	case len(l.areaCode) != 3 && len(l.areaCode) != 4:
+		return fmt.Errorf("invalid area code '%s'", l.areaCode)
+	}
+	if _, exist := internal.areaCodes.Load(l.areaCode); !exist {
+		return fmt.Errorf("unknown area code '%s'", l.areaCode)
+	}
+
+	// 三位区号的城市均为八位号码, 其余城市为七位或八位号码
+	if len(l.number) != 8 && (len(l.number) != 7 || len(l.areaCode) == 3) {
+		return fmt.Errorf("invalid number length %d", len(l.number))
+	}
+	if l.number[0] == '0' || l.number[0] == '1' {
+		return fmt.Errorf("invalid number prefix '%c'", l.number[0])
+	}
+	if len(l.extension) > 6 {
+		return fmt.Errorf("invalid extension length %d", len(l.extension))
+	}
+	return nil
+}
+
+// AreaCode 返回带前导零的区号, 如 "0755"
+func (l Landline) AreaCode() string {
+	return l.areaCode
+}
+
+// Number 返回不含区号的本地号码
+func (l Landline) Number() string {
+	return l.number
+}
+
+// Extension 返回分机号, 没有分机号时返回空字符串
+func (l Landline) Extension() string {
+	return l.extension
+}
+
+// Divisions 返回区号所覆盖的行政区划链。部分区号由多个地级行政区共用, 因此可能返回多个
+func (l Landline) Divisions() [][]*admindivision.Division {
+	return AreaCodeDivisions(l.areaCode)
+}
+
+// E164 返回 E.164 格式的号码 (不含分机号), 如 "+8675512345678"
+func (l Landline) E164() string {
+	if l.areaCode == "" {
+		return ""
+	}
+	return "+86" + l.areaCode[1:] + l.number
+}
+
+// Masked 返回脱敏后的号码, 如 "0755-****5678"
+func (l Landline) Masked() string {
+	if l.areaCode == "" {
+		return ""
+	}
+	return l.areaCode + "-" + Mask(l.number, 0, 4)
+}
+
+func (l Landline) String() string {
+	if l.areaCode == "" {
+		return "<no landline>"
+	}
+	s := l.areaCode + "-" + l.number
+	if l.extension != "" {
+		s += "-" + l.extension
+	}
+	return s
+}
+
+// AreaCodeDivisions 查询一个区号所覆盖的行政区划链
+func AreaCodeDivisions(areaCode string) [][]*admindivision.Division {
+	codes, _ := internal.areaCodes.Load(areaCode)
+	if len(codes) == 0 {
+		return nil
+	}
+	res := make([][]*admindivision.Division, 0, len(codes))
+	for _, c := range codes {
+		if chain := admindivision.SearchDivisionByCode(c); len(chain) > 0 {
+			res = append(res, chain)
+		}
+	}
+	return res
+}
+
+// AreaCodes 返回当前所有已登记的区号, 按字典序排列
+func AreaCodes() []string {
+	var res []string
+	internal.areaCodes.Range(func(area string, _ []string) bool {
+		res = append(res, area)
+		return true
+	})
+	slices.Sort(res)
+	return res
+}
+
+// SetAreaCode 添加或更新一个区号及其覆盖的行政区划代码 (省级两位或地级四位)
+func SetAreaCode(areaCode string, divisionCodes ...string) error {
+	if len(areaCode) != 3 && len(areaCode) != 4 || areaCode[0] != '0' {
+		return fmt.Errorf("invalid area code '%s'", areaCode)
+	}
+	if err := validateDigits(areaCode); err != nil {
+		return err
+	}
+	if len(divisionCodes) == 0 {
+		return errors.New("missing division code")
+	}
+	for _, c := range divisionCodes {
+		chain := admindivision.SearchDivisionByCode(c)
+		if admindivision.JoinDivisionCodes(chain) != c {
+			return fmt.Errorf("unknown division code '%s'", c)
+		}
+	}
+	internal.areaCodes.Store(areaCode, slices.Clone(divisionCodes))
+	return nil
+}
+
+// DeleteAreaCode 删除一个区号
+func DeleteAreaCode(areaCode string) {
+	internal.areaCodes.Delete(areaCode)
+}
@@ -0,0 +1,87 @@
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Mobile 表示一个中国大陆手机号码
+type Mobile struct {
+	raw string
+}
+
+// NormalizeMobile 将手机号规范化为 11 位数字, 去除 +86/0086 前缀以及空格、横线等分隔符
+func NormalizeMobile(s string) (string, error) {
+	s = stripSeparators(strings.TrimSpace(s))
+	switch {
+	case strings.HasPrefix(s, "+86"):
+		s = s[3:]
+	case strings.HasPrefix(s, "0086"):
+		s = s[4:]
+	case strings.HasPrefix(s, "86") && len(s) == 13:
+		s = s[2:]
+	}
+
+	if err := validateDigits(s); err != nil {
+		return "", err
+	}
+	if len(s) != 11 {
+		return "", errors.New("invalid length")
+	}
+	if s[0] != '1' || s[1] < '3' {
+		return "", fmt.Errorf("invalid mobile prefix '%s'", s[:2])
+	}
+	return s, nil
+}
+
+// ParseMobile 解析一个手机号, 要求号段已登记
+func ParseMobile(s string) (Mobile, error) {
+	num, err := NormalizeMobile(s)
+	if err != nil {
+		return Mobile{}, err
+	}
+	if _, exist := LookupSegment(num); !exist {
+		return Mobile{}, fmt.Errorf("unknown segment '%s'", num[:3])
+	}
+	return Mobile{raw: num}, nil
+}
+
+// Segment 返回号码所属的号段信息
+func (m Mobile) Segment() SegmentInfo {
+	info, _ := LookupSegment(m.raw)
+	return info
+}
+
+// Carrier 返回号码所属的运营商
+func (m Mobile) Carrier() Carrier {
+	return m.Segment().Carrier
+}
+
+// Virtual 是否虚拟运营商号段
+func (m Mobile) Virtual() bool {
+	return m.Segment().Virtual
+}
+
+// E164 返回 E.164 格式的号码, 如 "+8613812345678"
+func (m Mobile) E164() string {
+	if m.raw == "" {
+		return ""
+	}
+	return "+86" + m.raw
+}
+
+// Masked 返回脱敏后的号码, 如 "138****5678"
+func (m Mobile) Masked() string {
+	if m.raw == "" {
+		return ""
+	}
+	return Mask(m.raw, 3, 4)
+}
+
+func (m Mobile) String() string {
+	if m.raw == "" {
+		return "<no mobile>"
+	}
+	return m.raw
+}
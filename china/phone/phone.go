@@ -0,0 +1,88 @@
+// Package phone 实现中国大陆手机号码和固定电话号码的解析、运营商识别和脱敏逻辑
+package phone
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Carrier 表示基础电信运营商
+type Carrier int
+
+const (
+	// UnknownCarrier 未知运营商, 号段未登记时返回
+	UnknownCarrier Carrier = iota
+	// ChinaMobile 中国移动
+	ChinaMobile
+	// ChinaUnicom 中国联通
+	ChinaUnicom
+	// ChinaTelecom 中国电信
+	ChinaTelecom
+	// ChinaBroadnet 中国广电
+	ChinaBroadnet
+)
+
+func (c Carrier) String() string {
+	switch c {
+	case ChinaMobile:
+		return "中国移动"
+	case ChinaUnicom:
+		return "中国联通"
+	case ChinaTelecom:
+		return "中国电信"
+	case ChinaBroadnet:
+		return "中国广电"
+	default:
+		return "未知运营商"
+	}
+}
+
+// Mask 对字符串做脱敏处理, 保留开头 head 个和结尾 tail 个字符, 其余字符替换为 '*'。
+// 如果字符串过短, 则全部替换
+func Mask(s string, head, tail int) string {
+	runes := []rune(s)
+	if head < 0 {
+		head = 0
+	}
+	if tail < 0 {
+		tail = 0
+	}
+	if head+tail >= len(runes) {
+		return strings.Repeat("*", len(runes))
+	}
+	b := strings.Builder{}
+	b.WriteString(string(runes[:head]))
+	b.WriteString(strings.Repeat("*", len(runes)-head-tail))
+	b.WriteString(string(runes[len(runes)-tail:]))
+	return b.String()
+}
+
+// MaskMobile 对手机号做脱敏处理, 形如 "138****5678"。如果不是合法的手机号, 则按照通用规则脱敏
+func MaskMobile(s string) string {
+	m, err := ParseMobile(s)
+	if err != nil {
+		return Mask(s, 3, 4)
+	}
+	return m.Masked()
+}
+
+// stripSeparators 去掉号码中常见的分隔符
+func stripSeparators(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '\t', '(', ')', '（', '）', ' ', '　':
+			return -1
+		default:
+			return r
+		}
+	}, s)
+}
+
+func validateDigits(s string) error {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("invalid character '%c'", r)
+		}
+	}
+	return nil
+}
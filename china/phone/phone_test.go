@@ -0,0 +1,167 @@
+package phone_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Andrew-M-C/go.util/china/admindivision"
+	"github.com/Andrew-M-C/go.util/china/phone"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	cv = convey.Convey
+	so = convey.So
+	eq = convey.ShouldEqual
+
+	isNil   = convey.ShouldBeNil
+	isErr   = convey.ShouldBeError
+	isTrue  = convey.ShouldBeTrue
+	isFalse = convey.ShouldBeFalse
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(m.Run())
+}
+
+func TestMobile(t *testing.T) {
+	cv("格式规范化", t, func() {
+		for _, s := range []string{
+			"13822345678",
+			"+86 138 2234 5678",
+			"+86-138-2234-5678",
+			"0086 13822345678",
+			"8613822345678",
+			"(+86)138-2234-5678",
+		} {
+			num, err := phone.NormalizeMobile(s)
+			so(err, isNil)
+			so(num, eq, "13822345678")
+		}
+
+		_, err := phone.NormalizeMobile("1381234567")
+		so(err, isErr)
+		_, err = phone.NormalizeMobile("12822345678")
+		so(err, isErr)
+		_, err = phone.NormalizeMobile("1381234567a")
+		so(err, isErr)
+	})
+
+	cv("运营商识别", t, func() {
+		m, err := phone.ParseMobile("13822345678")
+		so(err, isNil)
+		so(m.Carrier(), eq, phone.ChinaMobile)
+		so(m.Virtual(), isFalse)
+		so(m.E164(), eq, "+8613822345678")
+
+		m, err = phone.ParseMobile("18622345678")
+		so(err, isNil)
+		so(m.Carrier(), eq, phone.ChinaUnicom)
+
+		m, err = phone.ParseMobile("19922345678")
+		so(err, isNil)
+		so(m.Carrier(), eq, phone.ChinaTelecom)
+
+		m, err = phone.ParseMobile("19222345678")
+		so(err, isNil)
+		so(m.Carrier(), eq, phone.ChinaBroadnet)
+
+		m, err = phone.ParseMobile("13492345678")
+		so(err, isNil)
+		so(m.Carrier(), eq, phone.ChinaTelecom)
+		m, err = phone.ParseMobile("13422345678")
+		so(err, isNil)
+		so(m.Carrier(), eq, phone.ChinaMobile)
+
+		m, err = phone.ParseMobile("17051234567")
+		so(err, isNil)
+		so(m.Carrier(), eq, phone.ChinaMobile)
+		so(m.Virtual(), isTrue)
+		m, err = phone.ParseMobile("17001234567")
+		so(err, isNil)
+		so(m.Carrier(), eq, phone.ChinaTelecom)
+		so(m.Virtual(), isTrue)
+	})
+
+	cv("运行时更新号段", t, func() {
+		_, err := phone.ParseMobile("14022345678")
+		so(err, isErr)
+
+		err = phone.SetSegment("140", phone.ChinaMobile, false)
+		so(err, isNil)
+		m, err := phone.ParseMobile("14022345678")
+		so(err, isNil)
+		so(m.Carrier(), eq, phone.ChinaMobile)
+
+		phone.DeleteSegment("140")
+		_, err = phone.ParseMobile("14022345678")
+		so(err, isErr)
+
+		so(phone.SetSegment("240", phone.ChinaMobile, false), isErr)
+		so(phone.SetSegment("14", phone.ChinaMobile, false), isErr)
+	})
+
+	cv("脱敏", t, func() {
+		so(phone.MaskMobile("+86 138-1234-5678"), eq, "138****5678")
+		so(phone.Mask("张三丰", 1, 0), eq, "张**")
+		so(phone.Mask("abc", 2, 2), eq, "***")
+	})
+}
+
+func TestLandline(t *testing.T) {
+	cv("各种格式", t, func() {
+		for _, s := range []string{
+			"0755-22345678",
+			"0755 2234 5678",
+			"(0755)22345678",
+			"（0755）22345678",
+			"075522345678",
+			"+86 755 22345678",
+			"0086-755-22345678",
+		} {
+			l, err := phone.ParseLandline(s)
+			so(err, isNil)
+			so(l.String(), eq, "0755-22345678")
+			so(l.E164(), eq, "+8675522345678")
+		}
+	})
+
+	cv("区号和分机", t, func() {
+		l, err := phone.ParseLandline("010-87654321-8001")
+		so(err, isNil)
+		so(l.AreaCode(), eq, "010")
+		so(l.Number(), eq, "87654321")
+		so(l.Extension(), eq, "8001")
+		so(l.Masked(), eq, "010-****4321")
+
+		divs := l.Divisions()
+		so(len(divs), eq, 1)
+		so(admindivision.DescribeDivisionChain(divs[0], "/"), eq, "北京市")
+
+		l, err = phone.ParseLandline("0728-2234567")
+		so(err, isNil)
+		divs = l.Divisions()
+		so(len(divs), eq, 3)
+		so(admindivision.DescribeDivisionChain(divs[0], "/"), eq, "湖北省/仙桃市")
+	})
+
+	cv("非法号码", t, func() {
+		_, err := phone.ParseLandline("010-2234567")
+		so(err, isErr)
+		_, err = phone.ParseLandline("0755-01234567")
+		so(err, isErr)
+		_, err = phone.ParseLandline("0000-22345678")
+		so(err, isErr)
+		_, err = phone.ParseLandline("22345678")
+		so(err, isErr)
+	})
+
+	cv("区号表均可解析", t, func() {
+		areas := phone.AreaCodes()
+		so(len(areas) > 300, isTrue)
+		for _, area := range areas {
+			divs := phone.AreaCodeDivisions(area)
+			so(len(divs) > 0, isTrue)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package phone
+
+import "fmt"
+
+// SegmentInfo 表示一个手机号段的信息
+type SegmentInfo struct {
+	// Prefix 号段前缀, 三位或四位
+	Prefix string
+	// Carrier 基础运营商。对于虚拟运营商号段, 表示其所依托的基础运营商
+	Carrier Carrier
+	// Virtual 是否为虚拟运营商号段
+	Virtual bool
+}
+
+// LookupSegment 查询手机号所属的号段, 四位号段优先于三位号段
+func LookupSegment(mobile string) (SegmentInfo, bool) {
+	if len(mobile) >= 4 {
+		if info, exist := internal.segments.Load(mobile[:4]); exist {
+			return info, true
+		}
+	}
+	if len(mobile) >= 3 {
+		if info, exist := internal.segments.Load(mobile[:3]); exist {
+			return info, true
+		}
+	}
+	return SegmentInfo{}, false
+}
+
+// SetSegment 添加或更新一个号段。号段放号调整时, 可以在运行时调用此函数更新, 无需升级本包
+func SetSegment(prefix string, carrier Carrier, virtual bool) error {
+	if err := validateSegmentPrefix(prefix); err != nil {
+		return err
+	}
+	internal.segments.Store(prefix, SegmentInfo{
+		Prefix:  prefix,
+		Carrier: carrier,
+		Virtual: virtual,
+	})
+	return nil
+}
+
+// DeleteSegment 删除一个号段
+func DeleteSegment(prefix string) {
+	internal.segments.Delete(prefix)
+}
+
+// Segments 返回当前所有已登记号段
+func Segments() []SegmentInfo {
+	var res []SegmentInfo
+	internal.segments.Range(func(_ string, info SegmentInfo) bool {
+		res = append(res, info)
+		return true
+	})
+	return res
+}
+
+func validateSegmentPrefix(prefix string) error {
+	if len(prefix) != 3 && len(prefix) != 4 {
+		return fmt.Errorf("invalid segment prefix length %d", len(prefix))
+	}
+	if err := validateDigits(prefix); err != nil {
+		return err
+	}
+	if prefix[0] != '1' {
+		return fmt.Errorf("invalid segment prefix '%s'", prefix)
+	}
+	return nil
+}
+
+func init() {
+	add := func(carrier Carrier, virtual bool, prefixes ...string) {
+		for _, p := range prefixes {
+			_ = SetSegment(p, carrier, virtual)
+		}
+	}
+
+	// 中国移动
+	add(ChinaMobile, false,
+		"134", "135", "136", "137", "138", "139", "147", "148",
+		"150", "151", "152", "157", "158", "159",
+		"172", "178", "182", "183", "184", "187", "188",
+		"195", "197", "198",
+	)
+	// 中国联通
+	add(ChinaUnicom, false,
+		"130", "131", "132", "145", "146", "155", "156",
+		"166", "175", "176", "185", "186", "196",
+	)
+	// 中国电信, 其中 1349 为卫星电话号段
+	add(ChinaTelecom, false,
+		"1349", "133", "149", "153", "173", "174", "177",
+		"180", "181", "189", "190", "191", "193", "199",
+	)
+	// 中国广电
+	add(ChinaBroadnet, false, "192")
+
+	// 虚拟运营商
+	add(ChinaMobile, true, "165", "1703", "1705", "1706")
+	add(ChinaUnicom, true, "167", "171", "1704", "1707", "1708", "1709")
+	add(ChinaTelecom, true, "162", "1700", "1701", "1702")
+}
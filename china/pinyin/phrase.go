@@ -0,0 +1,64 @@
+package pinyin
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	syncutil "github.com/Andrew-M-C/go.util/sync"
+)
+
+var internal = struct {
+	phrases      syncutil.Map[string, []string]
+	maxPhraseLen atomic.Int32
+}{
+	phrases: syncutil.NewMap[string, []string](),
+}
+
+// AddPhrase 添加或更新一个词组的读音, 用于处理多音字。pinyin 为带声调符号的拼音, 每个汉字对应一个,
+// 也可以传入一个以空格分隔的字符串。例如:
+//
+//	AddPhrase("重庆", "chóng", "qìng")
+//	AddPhrase("重庆", "chóng qìng")
+func AddPhrase(phrase string, pinyin ...string) error {
+	if len(pinyin) == 1 {
+		pinyin = strings.Fields(pinyin[0])
+	}
+	runes := []rune(phrase)
+	if len(runes) < 2 {
+		return fmt.Errorf("phrase '%s' too short", phrase)
+	}
+	if len(runes) != len(pinyin) {
+		return fmt.Errorf("phrase '%s' has %d characters but %d pinyin", phrase, len(runes), len(pinyin))
+	}
+	for _, r := range runes {
+		if !IsHan(r) {
+			return fmt.Errorf("invalid character '%c' in phrase '%s'", r, phrase)
+		}
+	}
+
+	internal.phrases.Store(phrase, pinyin)
+	for {
+		curr := internal.maxPhraseLen.Load()
+		if int32(len(runes)) <= curr || internal.maxPhraseLen.CompareAndSwap(curr, int32(len(runes))) {
+			break
+		}
+	}
+	return nil
+}
+
+// DeletePhrase 删除一个词组
+func DeletePhrase(phrase string) {
+	internal.phrases.Delete(phrase)
+}
+
+// matchPhrase 按照最长匹配原则查找以 runes 开头的词组, 返回词组读音和匹配的字符数
+func matchPhrase(runes []rune) ([]string, int) {
+	n := min(int(internal.maxPhraseLen.Load()), len(runes))
+	for ; n >= 2; n-- {
+		if p, exist := internal.phrases.Load(string(runes[:n])); exist {
+			return p, n
+		}
+	}
+	return nil, 0
+}
@@ -0,0 +1,155 @@
+package pinyin
+
+func init() {
+	phrases := map[string]string{
+		// 地名
+		"重庆":  "chóng qìng",
+		"长沙":  "cháng shā",
+		"长春":  "cháng chūn",
+		"长治":  "cháng zhì",
+		"长安":  "cháng ān",
+		"长宁":  "cháng níng",
+		"长寿":  "cháng shòu",
+		"长丰":  "cháng fēng",
+		"长乐":  "cháng lè",
+		"长兴":  "cháng xīng",
+		"长泰":  "cháng tài",
+		"长汀":  "cháng tīng",
+		"长垣":  "cháng yuán",
+		"长葛":  "cháng gě",
+		"长岛":  "cháng dǎo",
+		"长清":  "cháng qīng",
+		"长白":  "cháng bái",
+		"长阳":  "cháng yáng",
+		"长武":  "cháng wǔ",
+		"长顺":  "cháng shùn",
+		"长岭":  "cháng lǐng",
+		"长海":  "cháng hǎi",
+		"长子":  "zhǎng zǐ",
+		"厦门":  "xià mén",
+		"蚌埠":  "bèng bù",
+		"六安":  "lù ān",
+		"六合":  "lù hé",
+		"佛山":  "fó shān",
+		"成都":  "chéng dū",
+		"昌都":  "chāng dū",
+		"丰都":  "fēng dū",
+		"花都":  "huā dū",
+		"都匀":  "dū yún",
+		"都安":  "dū ān",
+		"都昌":  "dū chāng",
+		"都兰":  "dū lán",
+		"都江堰": "dū jiāng yàn",
+		"丽水":  "lí shuǐ",
+		"台州":  "tāi zhōu",
+		"天台":  "tiān tāi",
+		"乐清":  "yuè qīng",
+		"乐亭":  "lào tíng",
+		"番禺":  "pān yú",
+		"西藏":  "xī zàng",
+		"藏族":  "zàng zú",
+		"景颇":  "jǐng pō",
+		"郭勒":  "guō lè",
+		"勒苏":  "lè sū",
+		"阿勒泰": "ā lè tài",
+		"喀什":  "kā shí",
+		"铅山":  "yán shān",
+		"洪洞":  "hóng tóng",
+		"尉氏":  "wèi shì",
+		"尉犁":  "yù lí",
+		"荥阳":  "xíng yáng",
+		"荥经":  "yíng jīng",
+		"浚县":  "xùn xiàn",
+		"单县":  "shàn xiàn",
+		"蔚县":  "yù xiàn",
+		"繁峙":  "fán shì",
+		"东阿":  "dōng ē",
+		"大埔":  "dà bù",
+		"莘县":  "shēn xiàn",
+		"涡阳":  "guō yáng",
+		"歙县":  "shè xiàn",
+		"枞阳":  "zōng yáng",
+		"盱眙":  "xū yí",
+		"济源":  "jǐ yuán",
+		"朝阳":  "cháo yáng",
+
+		// 常用词
+		"银行": "yín háng",
+		"行业": "háng yè",
+		"行长": "háng zhǎng",
+		"内行": "nèi háng",
+		"外行": "wài háng",
+		"长度": "cháng dù",
+		"长期": "cháng qī",
+		"长城": "cháng chéng",
+		"长江": "cháng jiāng",
+		"长途": "cháng tú",
+		"长短": "cháng duǎn",
+		"长久": "cháng jiǔ",
+		"长远": "cháng yuǎn",
+		"延长": "yán cháng",
+		"擅长": "shàn cháng",
+		"特长": "tè cháng",
+		"漫长": "màn cháng",
+		"重复": "chóng fù",
+		"重新": "chóng xīn",
+		"重阳": "chóng yáng",
+		"重叠": "chóng dié",
+		"重建": "chóng jiàn",
+		"重启": "chóng qǐ",
+		"重试": "chóng shì",
+		"重置": "chóng zhì",
+		"音乐": "yīn yuè",
+		"乐器": "yuè qì",
+		"乐队": "yuè duì",
+		"乐曲": "yuè qǔ",
+		"首都": "shǒu dū",
+		"都市": "dū shì",
+		"会计": "kuài jì",
+		"还款": "huán kuǎn",
+		"归还": "guī huán",
+		"还原": "huán yuán",
+		"睡觉": "shuì jiào",
+		"觉得": "jué de",
+		"了解": "liǎo jiě",
+		"便宜": "pián yi",
+		"调整": "tiáo zhěng",
+		"调节": "tiáo jié",
+		"空调": "kōng tiáo",
+		"出差": "chū chāi",
+		"参差": "cēn cī",
+		"人参": "rén shēn",
+		"朝气": "zhāo qì",
+		"宝藏": "bǎo zàng",
+		"大夫": "dài fu",
+		"单于": "chán yú",
+		"头发": "tóu fa",
+		"理发": "lǐ fà",
+		"着急": "zháo jí",
+		"睡着": "shuì zháo",
+		"着陆": "zhuó lù",
+		"传记": "zhuàn jì",
+		"处理": "chǔ lǐ",
+		"相处": "xiāng chǔ",
+		"几乎": "jī hū",
+		"暖和": "nuǎn huo",
+		"为了": "wèi le",
+		"因为": "yīn wèi",
+		"薄荷": "bò he",
+		"佛教": "fó jiào",
+		"勒索": "lè suǒ",
+		"种植": "zhòng zhí",
+		"种地": "zhòng dì",
+		"效率": "xiào lǜ",
+		"血液": "xuè yè",
+		"主角": "zhǔ jué",
+		"角色": "jué sè",
+		"查询": "chá xún",
+		"解放": "jiě fàng",
+	}
+	for phrase, pinyin := range phrases {
+		if err := AddPhrase(phrase, pinyin); err != nil {
+			panic(err)
+		}
+	}
+}
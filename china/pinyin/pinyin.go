@@ -0,0 +1,120 @@
+// Package pinyin 实现汉字转拼音逻辑, 支持带声调、数字声调、无声调、声母和首字母等多种风格,
+// 并按照内置和自定义的词组表处理常见的多音字
+package pinyin
+
+import (
+	"strings"
+	"unicode"
+
+	dict "github.com/mozillazg/go-pinyin"
+)
+
+// Style 表示拼音的输出风格
+type Style int
+
+const (
+	// ToneMark 带声调符号, 如 "zhōng"
+	ToneMark Style = iota
+	// ToneNumber 声调以数字形式放在末尾, 轻声不标注, 如 "zhong1"。ü 以 v 表示
+	ToneNumber
+	// NoTone 不带声调, 如 "zhong"。ü 以 v 表示
+	NoTone
+	// Initial 只保留声母, 如 "zh"。零声母音节 (如 "ai"、"yi"、"wu") 返回空字符串
+	Initial
+	// FirstLetter 只保留首字母, 如 "z"
+	FirstLetter
+)
+
+func (s Style) String() string {
+	switch s {
+	case ToneMark:
+		return "声调符号"
+	case ToneNumber:
+		return "数字声调"
+	case NoTone:
+		return "无声调"
+	case Initial:
+		return "声母"
+	case FirstLetter:
+		return "首字母"
+	default:
+		return "未知风格"
+	}
+}
+
+// Convert 将字符串转换为拼音。每个汉字对应返回值中的一个元素, 连续的非汉字字符原样作为一个元素返回。
+// 多音字优先按照词组表识别, 词组表中不存在时取最常用的读音
+func Convert(s string, style Style) []string {
+	var res []string
+	runes := []rune(s)
+	other := strings.Builder{}
+	flushOther := func() {
+		if other.Len() > 0 {
+			res = append(res, other.String())
+			other.Reset()
+		}
+	}
+
+	for i := 0; i < len(runes); {
+		if p, n := matchPhrase(runes[i:]); n > 0 {
+			flushOther()
+			for _, syllable := range p {
+				res = append(res, applyStyle(syllable, style))
+			}
+			i += n
+			continue
+		}
+		readings := lookup(runes[i])
+		if len(readings) == 0 {
+			other.WriteRune(runes[i])
+			i++
+			continue
+		}
+		flushOther()
+		res = append(res, applyStyle(readings[0], style))
+		i++
+	}
+
+	flushOther()
+	return res
+}
+
+// Join 将字符串转换为拼音, 并以 sep 连接。非汉字字符原样保留
+func Join(s string, style Style, sep string) string {
+	return strings.Join(Convert(s, style), sep)
+}
+
+// Heteronyms 返回一个汉字的全部读音, 最常用的读音排在最前面。非汉字返回空
+func Heteronyms(r rune, style Style) []string {
+	readings := lookup(r)
+	if len(readings) == 0 {
+		return nil
+	}
+	res := make([]string, 0, len(readings))
+	seen := make(map[string]struct{}, len(readings))
+	for _, p := range readings {
+		p = applyStyle(p, style)
+		if _, exist := seen[p]; exist {
+			continue
+		}
+		seen[p] = struct{}{}
+		res = append(res, p)
+	}
+	return res
+}
+
+// IsHan 判断一个字符是否为汉字
+func IsHan(r rune) bool {
+	return unicode.Is(unicode.Han, r)
+}
+
+func lookup(r rune) []string {
+	if !IsHan(r) {
+		return nil
+	}
+	s, exist := dict.PinyinDict[int(r)]
+	if !exist || s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
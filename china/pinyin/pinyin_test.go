@@ -0,0 +1,78 @@
+package pinyin_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Andrew-M-C/go.util/china/pinyin"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	cv = convey.Convey
+	so = convey.So
+	eq = convey.ShouldEqual
+
+	isNil = convey.ShouldBeNil
+	isErr = convey.ShouldBeError
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(m.Run())
+}
+
+func TestStyle(t *testing.T) {
+	cv("各种风格", t, func() {
+		so(pinyin.Join("中国", pinyin.ToneMark, " "), eq, "zhōng guó")
+		so(pinyin.Join("中国", pinyin.ToneNumber, " "), eq, "zhong1 guo2")
+		so(pinyin.Join("中国", pinyin.NoTone, ""), eq, "zhongguo")
+		so(pinyin.Join("中国", pinyin.Initial, ","), eq, "zh,g")
+		so(pinyin.Join("中国", pinyin.FirstLetter, ""), eq, "zg")
+	})
+
+	cv("ü 和零声母", t, func() {
+		so(pinyin.Join("绿", pinyin.ToneMark, ""), eq, "lǜ")
+		so(pinyin.Join("绿", pinyin.ToneNumber, ""), eq, "lv4")
+		so(pinyin.Join("绿", pinyin.NoTone, ""), eq, "lv")
+		so(pinyin.Join("爱我", pinyin.Initial, ","), eq, ",")
+		so(pinyin.Join("爱我", pinyin.FirstLetter, ""), eq, "aw")
+	})
+
+	cv("非汉字", t, func() {
+		res := pinyin.Convert("A股上涨3.5%", pinyin.NoTone)
+		so(res, convey.ShouldResemble, []string{"A", "gu", "shang", "zhang", "3.5%"})
+	})
+
+	cv("全部读音", t, func() {
+		so(pinyin.Heteronyms('长', pinyin.ToneMark), convey.ShouldResemble, []string{"zhǎng", "cháng"})
+		so(pinyin.Heteronyms('A', pinyin.ToneMark), isNil)
+	})
+}
+
+func TestPhrase(t *testing.T) {
+	cv("内置多音词组", t, func() {
+		so(pinyin.Join("重庆", pinyin.NoTone, ""), eq, "chongqing")
+		so(pinyin.Join("长沙", pinyin.NoTone, ""), eq, "changsha")
+		so(pinyin.Join("厦门", pinyin.NoTone, ""), eq, "xiamen")
+		so(pinyin.Join("银行行长", pinyin.ToneMark, " "), eq, "yín háng háng zhǎng")
+		so(pinyin.Join("重要", pinyin.ToneMark, " "), eq, "zhòng yào")
+		so(pinyin.Join("重新开始", pinyin.ToneMark, " "), eq, "chóng xīn kāi shǐ")
+	})
+
+	cv("最长匹配", t, func() {
+		so(pinyin.Join("都江堰", pinyin.NoTone, " "), eq, "du jiang yan")
+		so(pinyin.Join("都是", pinyin.NoTone, " "), eq, "dou shi")
+	})
+
+	cv("自定义词组", t, func() {
+		so(pinyin.Join("曾经", pinyin.NoTone, ""), eq, "cengjing")
+		so(pinyin.AddPhrase("曾国藩", "zēng guó fān"), isNil)
+		so(pinyin.Join("曾国藩", pinyin.NoTone, ""), eq, "zengguofan")
+		pinyin.DeletePhrase("曾国藩")
+		so(pinyin.Join("曾国藩", pinyin.NoTone, ""), eq, "cengguofan")
+
+		so(pinyin.AddPhrase("曾", "zēng"), isErr)
+		so(pinyin.AddPhrase("曾国藩", "zēng", "guó"), isErr)
+		so(pinyin.AddPhrase("曾A", "zēng", "a"), isErr)
+	})
+}
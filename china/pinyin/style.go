@@ -0,0 +1,86 @@
+package pinyin
+
+import (
+	"strconv"
+	"strings"
+)
+
+type toneMark struct {
+	base rune
+	tone int
+}
+
+var toneMarks = map[rune]toneMark{
+	'ā': {'a', 1}, 'á': {'a', 2}, 'ǎ': {'a', 3}, 'à': {'a', 4},
+	'ē': {'e', 1}, 'é': {'e', 2}, 'ě': {'e', 3}, 'è': {'e', 4},
+	'ī': {'i', 1}, 'í': {'i', 2}, 'ǐ': {'i', 3}, 'ì': {'i', 4},
+	'ō': {'o', 1}, 'ó': {'o', 2}, 'ǒ': {'o', 3}, 'ò': {'o', 4},
+	'ū': {'u', 1}, 'ú': {'u', 2}, 'ǔ': {'u', 3}, 'ù': {'u', 4},
+	'ǖ': {'ü', 1}, 'ǘ': {'ü', 2}, 'ǚ': {'ü', 3}, 'ǜ': {'ü', 4},
+	'ń': {'n', 2}, 'ň': {'n', 3}, 'ǹ': {'n', 4},
+	'ḿ': {'m', 2},
+}
+
+// 部分音节 (如 "ê̄"、"m̀") 以组合附加符号表示声调
+var combiningToneMarks = map[rune]int{
+	'\u0304': 1,
+	'\u0301': 2,
+	'\u030c': 3,
+	'\u0300': 4,
+}
+
+var initials = []string{
+	"zh", "ch", "sh",
+	"b", "p", "m", "f", "d", "t", "n", "l", "g", "k", "h",
+	"j", "q", "x", "r", "z", "c", "s",
+}
+
+// splitTone 将带声调符号的音节拆分为不带声调的音节 (保留 ü) 和声调, 轻声的声调为 0
+func splitTone(syllable string) (string, int) {
+	b := strings.Builder{}
+	tone := 0
+	for _, r := range syllable {
+		if m, exist := toneMarks[r]; exist {
+			b.WriteRune(m.base)
+			tone = m.tone
+			continue
+		}
+		if t, exist := combiningToneMarks[r]; exist {
+			tone = t
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), tone
+}
+
+func applyStyle(syllable string, style Style) string {
+	if style == ToneMark {
+		return syllable
+	}
+
+	plain, tone := splitTone(syllable)
+	plain = strings.ReplaceAll(plain, "ü", "v")
+
+	switch style {
+	case ToneNumber:
+		if tone == 0 {
+			return plain
+		}
+		return plain + strconv.Itoa(tone)
+	case Initial:
+		for _, i := range initials {
+			if strings.HasPrefix(plain, i) && plain != i {
+				return i
+			}
+		}
+		return ""
+	case FirstLetter:
+		for _, r := range plain {
+			return string(r)
+		}
+		return ""
+	default:
+		return plain
+	}
+}
@@ -0,0 +1,138 @@
+package uscc
+
+import "fmt"
+
+// Authority 登记管理部门码, 即统一社会信用代码的第一位
+type Authority byte
+
+const (
+	// OrganizationEstablishment 机构编制
+	OrganizationEstablishment Authority = '1'
+	// ForeignAffairs 外交
+	ForeignAffairs Authority = '2'
+	// Justice 司法行政
+	Justice Authority = '3'
+	// Culture 文化
+	Culture Authority = '4'
+	// CivilAffairs 民政
+	CivilAffairs Authority = '5'
+	// Tourism 旅游
+	Tourism Authority = '6'
+	// Religion 宗教
+	Religion Authority = '7'
+	// TradeUnion 工会
+	TradeUnion Authority = '8'
+	// MarketRegulation 工商 (市场监督管理)
+	MarketRegulation Authority = '9'
+	// MilitaryEstablishment 中央军委改革和编制办公室
+	MilitaryEstablishment Authority = 'A'
+	// Agriculture 农业
+	Agriculture Authority = 'N'
+	// OtherAuthority 其他
+	OtherAuthority Authority = 'Y'
+)
+
+var authorityNames = map[Authority]string{
+	OrganizationEstablishment: "机构编制",
+	ForeignAffairs:            "外交",
+	Justice:                   "司法行政",
+	Culture:                   "文化",
+	CivilAffairs:              "民政",
+	Tourism:                   "旅游",
+	Religion:                  "宗教",
+	TradeUnion:                "工会",
+	MarketRegulation:          "工商",
+	MilitaryEstablishment:     "中央军委改革和编制办公室",
+	Agriculture:               "农业",
+	OtherAuthority:            "其他",
+}
+
+func (a Authority) String() string {
+	if s, exist := authorityNames[a]; exist {
+		return s
+	}
+	return fmt.Sprintf("非法值 '%c'", a)
+}
+
+// Category 机构类别码, 即统一社会信用代码的第二位。其含义依赖于登记管理部门
+type Category struct {
+	Authority Authority
+	Code      byte
+}
+
+// reference: GB 32100-2015 附录 A
+var categoryNames = map[Authority]map[byte]string{
+	OrganizationEstablishment: {
+		'1': "机关",
+		'2': "事业单位",
+		'3': "中央编办直接管理机构编制的群众团体",
+		'9': "其他",
+	},
+	ForeignAffairs: {
+		'1': "外国常驻新闻机构",
+		'9': "其他",
+	},
+	Justice: {
+		'1': "律师执业机构",
+		'2': "公证处",
+		'3': "基层法律服务所",
+		'4': "司法鉴定机构",
+		'5': "仲裁委员会",
+		'9': "其他",
+	},
+	Culture: {
+		'1': "外国在华文化中心",
+		'9': "其他",
+	},
+	CivilAffairs: {
+		'1': "社会团体",
+		'2': "民办非企业单位",
+		'3': "基金会",
+		'9': "其他",
+	},
+	Tourism: {
+		'1': "外国旅游部门常驻代表机构",
+		'2': "港澳台地区旅游部门常驻内地 (大陆) 代表机构",
+		'9': "其他",
+	},
+	Religion: {
+		'1': "宗教活动场所",
+		'2': "宗教院校",
+		'9': "其他",
+	},
+	TradeUnion: {
+		'1': "基层工会",
+		'9': "其他",
+	},
+	MarketRegulation: {
+		'1': "企业",
+		'2': "个体工商户",
+		'3': "农民专业合作社",
+	},
+	MilitaryEstablishment: {
+		'1': "军队事业单位",
+		'9': "其他",
+	},
+	Agriculture: {
+		'1': "组级集体经济组织",
+		'2': "村级集体经济组织",
+		'3': "乡镇级集体经济组织",
+		'9': "其他",
+	},
+	OtherAuthority: {
+		'1': "其他",
+	},
+}
+
+// Valid 判断机构类别是否合法
+func (c Category) Valid() bool {
+	_, exist := categoryNames[c.Authority][c.Code]
+	return exist
+}
+
+func (c Category) String() string {
+	if s, exist := categoryNames[c.Authority][c.Code]; exist {
+		return s
+	}
+	return fmt.Sprintf("非法值 '%c%c'", c.Authority, c.Code)
+}
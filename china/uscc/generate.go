@@ -0,0 +1,99 @@
+package uscc
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/Andrew-M-C/go.util/china/admindivision"
+)
+
+// Generate 按照指定的机构类别和六位行政区划码生成一个合法的统一社会信用代码, 其中的组织机构代码随机生成,
+// 且满足 GB 11714 的校验规则。一般用于测试
+func Generate(cat Category, divisionCode string) (Code, error) {
+	if !cat.Valid() {
+		return Code{}, fmt.Errorf("invalid category '%c%c'", cat.Authority, cat.Code)
+	}
+	if len(divisionCode) != 6 {
+		return Code{}, fmt.Errorf("invalid division code '%s'", divisionCode)
+	}
+	if err := validateDivisionCode(divisionCode); err != nil {
+		return Code{}, err
+	}
+
+	b := strings.Builder{}
+	b.Grow(codeLength)
+	b.WriteByte(byte(cat.Authority))
+	b.WriteByte(cat.Code)
+	b.WriteString(divisionCode)
+
+	org := randomOrganizationBody()
+	check, _ := organizationCodeChecksum(org)
+	b.WriteString(org)
+	b.WriteByte(check)
+
+	check, _ = checksum(b.String())
+	b.WriteByte(check)
+	return Code{raw: b.String()}, nil
+}
+
+// Random 随机生成一个合法的统一社会信用代码, 一般用于测试
+func Random() Code {
+	authorities := make([]Authority, 0, len(categoryNames))
+	for a := range categoryNames {
+		authorities = append(authorities, a)
+	}
+	slices.Sort(authorities)
+	auth := authorities[rand.IntN(len(authorities))]
+
+	codes := make([]byte, 0, len(categoryNames[auth]))
+	for c := range categoryNames[auth] {
+		codes = append(codes, c)
+	}
+	slices.Sort(codes)
+	cat := Category{
+		Authority: auth,
+		Code:      codes[rand.IntN(len(codes))],
+	}
+
+	c, err := Generate(cat, randomDivisionCode())
+	if err != nil {
+		// 不应该发生, 数据均来自内部表
+		panic(err)
+	}
+	return c
+}
+
+// randomOrganizationBody 随机生成组织机构代码的前八位, 只使用统一社会信用代码字符集中的字符
+func randomOrganizationBody() string {
+	b := make([]byte, 8)
+	for i := range b {
+		b[i] = charset[rand.IntN(len(charset))]
+	}
+	return string(b)
+}
+
+// randomDivisionCode 随机选择一个未撤销的行政区划, 返回六位代码
+func randomDivisionCode() string {
+	div := pickActive(admindivision.Provinces())
+	for {
+		sub := pickActive(div.SubDivisions())
+		if sub == nil {
+			break
+		}
+		div = sub
+	}
+	code := div.FullCode()
+	return code + strings.Repeat("0", 6-len(code))
+}
+
+func pickActive(divisions []*admindivision.Division) *admindivision.Division {
+	divisions = slices.DeleteFunc(divisions, func(d *admindivision.Division) bool {
+		return d.Deprecated()
+	})
+	if len(divisions) == 0 {
+		return nil
+	}
+	return divisions[rand.IntN(len(divisions))]
+}
@@ -0,0 +1,243 @@
+// Package uscc 实现统一社会信用代码 (GB 32100-2015) 的校验、解析和生成逻辑
+package uscc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Andrew-M-C/go.util/china/admindivision"
+)
+
+const (
+	// 统一社会信用代码使用的字符集, 不使用 I、O、Z、S、V
+	charset = "0123456789ABCDEFGHJKLMNPQRTUWXY"
+
+	codeLength = 18
+)
+
+// DetailInfo 表示统一社会信用代码的详细信息, 便于一次性获取
+type DetailInfo struct {
+	Authority        Authority
+	Category         Category
+	Division         []*admindivision.Division
+	OrganizationCode string
+}
+
+func (inf DetailInfo) String() string {
+	return fmt.Sprintf(
+		"{登记管理部门: %v, 机构类别: %v, 登记管理机关: %s, 组织机构代码: %s}",
+		inf.Authority,
+		inf.Category,
+		admindivision.DescribeDivisionChain(inf.Division, ""),
+		inf.OrganizationCode,
+	)
+}
+
+// Code 表示一个统一社会信用代码
+type Code struct {
+	raw string
+}
+
+// New 解析并校验一个统一社会信用代码。输入会去掉首尾空白并转为大写
+func New(s string) (Code, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if err := validateCharset(s); err != nil {
+		return Code{}, err
+	}
+	if err := validateChecksum(s); err != nil {
+		return Code{}, err
+	}
+	if err := validateCategory(s); err != nil {
+		return Code{}, err
+	}
+	if err := validateDivisionCode(s[2:8]); err != nil {
+		return Code{}, err
+	}
+	return Code{raw: s}, nil
+}
+
+// Validate 判断一个统一社会信用代码是否合法
+func Validate(s string) bool {
+	_, err := New(s)
+	return err == nil
+}
+
+// DetailInfo 返回详细描述
+func (c Code) DetailInfo() DetailInfo {
+	if c.raw == "" {
+		return DetailInfo{}
+	}
+	return DetailInfo{
+		Authority:        c.Authority(),
+		Category:         c.Category(),
+		Division:         c.Division(),
+		OrganizationCode: c.OrganizationCode(),
+	}
+}
+
+// Authority 返回登记管理部门
+func (c Code) Authority() Authority {
+	if c.raw == "" {
+		return 0
+	}
+	return Authority(c.raw[0])
+}
+
+// Category 返回机构类别
+func (c Code) Category() Category {
+	if c.raw == "" {
+		return Category{}
+	}
+	return Category{
+		Authority: Authority(c.raw[0]),
+		Code:      c.raw[1],
+	}
+}
+
+// DivisionCode 返回登记管理机关的六位行政区划码
+func (c Code) DivisionCode() string {
+	if c.raw == "" {
+		return ""
+	}
+	return c.raw[2:8]
+}
+
+// Division 返回登记管理机关所在的行政区划链
+func (c Code) Division() []*admindivision.Division {
+	if c.raw == "" {
+		return nil
+	}
+	return admindivision.SearchDivisionByCode(c.raw[2:8])
+}
+
+// OrganizationCode 返回主体标识码, 也就是九位的组织机构代码 (GB 11714)
+func (c Code) OrganizationCode() string {
+	if c.raw == "" {
+		return ""
+	}
+	return c.raw[8:17]
+}
+
+// ValidOrganizationCode 判断其中的组织机构代码是否符合 GB 11714 的校验规则。
+// 部分早期赋码的主体 (如个体工商户) 不一定满足此规则, 因此不作为 New 的校验条件
+func (c Code) ValidOrganizationCode() bool {
+	if c.raw == "" {
+		return false
+	}
+	code := c.raw[8:17]
+	check, err := organizationCodeChecksum(code[:8])
+	if err != nil {
+		return false
+	}
+	return code[8] == check
+}
+
+func (c Code) String() string {
+	if c.raw == "" {
+		return "<no code>"
+	}
+	return c.raw
+}
+
+func validateCharset(s string) error {
+	if len(s) != codeLength {
+		return errors.New("invalid length")
+	}
+	for _, r := range s {
+		if strings.IndexRune(charset, r) < 0 {
+			return fmt.Errorf("invalid character '%c'", r)
+		}
+	}
+	return nil
+}
+
+func validateCategory(s string) error {
+	cat := Category{
+		Authority: Authority(s[0]),
+		Code:      s[1],
+	}
+	if _, exist := authorityNames[cat.Authority]; !exist {
+		return fmt.Errorf("invalid registration authority '%c'", s[0])
+	}
+	if !cat.Valid() {
+		return fmt.Errorf("invalid organization category '%c' for authority '%c'", s[1], s[0])
+	}
+	return nil
+}
+
+func validateDivisionCode(code string) error {
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("invalid division code '%s'", code)
+		}
+	}
+	// SearchDivisionByCode 会返回能够解析的部分区划链, 因此需要区划链覆盖完整的代码。
+	// 不设区县的地级市 (如东莞市) 下有代码为 00 的虚拟区划, 因此两边都去掉末尾的 "00" 再比较
+	chain := admindivision.SearchDivisionByCode(code)
+	if len(chain) == 0 || trimDivisionCode(admindivision.JoinDivisionCodes(chain)) != trimDivisionCode(code) {
+		return fmt.Errorf("unknown division code '%s'", code)
+	}
+	return nil
+}
+
+// trimDivisionCode 去掉区划代码末尾表示上级区划的 "00", 如 440100 -> 4401
+func trimDivisionCode(code string) string {
+	for len(code) > 2 && strings.HasSuffix(code, "00") {
+		code = code[:len(code)-2]
+	}
+	return code
+}
+
+// reference: GB 32100-2015 附录 B
+func validateChecksum(s string) error {
+	check, err := checksum(s[:codeLength-1])
+	if err != nil {
+		return err
+	}
+	if s[codeLength-1] != check {
+		return errors.New("checksum failed")
+	}
+	return nil
+}
+
+// checksum 计算前 17 位的校验码
+func checksum(s string) (byte, error) {
+	weights := []int{1, 3, 9, 27, 19, 26, 16, 17, 20, 29, 25, 13, 8, 24, 10, 30, 28}
+	sum := 0
+	for i := 0; i < len(weights); i++ {
+		v := strings.IndexByte(charset, s[i])
+		if v < 0 {
+			return 0, fmt.Errorf("invalid character '%c'", s[i])
+		}
+		sum += v * weights[i]
+	}
+	remain := (len(charset) - sum%len(charset)) % len(charset)
+	return charset[remain], nil
+}
+
+// organizationCodeChecksum 计算组织机构代码 (GB 11714) 前八位的校验码
+func organizationCodeChecksum(s string) (byte, error) {
+	weights := []int{3, 7, 9, 10, 5, 8, 4, 2}
+	sum := 0
+	for i := 0; i < len(weights); i++ {
+		var v int
+		switch r := s[i]; {
+		case r >= '0' && r <= '9':
+			v = int(r - '0')
+		case r >= 'A' && r <= 'Z':
+			v = int(r-'A') + 10
+		default:
+			return 0, fmt.Errorf("invalid character '%c'", r)
+		}
+		sum += v * weights[i]
+	}
+	switch remain := 11 - sum%11; remain {
+	case 10:
+		return 'X', nil
+	case 11:
+		return '0', nil
+	default:
+		return byte('0' + remain), nil
+	}
+}
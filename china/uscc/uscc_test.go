@@ -0,0 +1,110 @@
+package uscc_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Andrew-M-C/go.util/china/admindivision"
+	"github.com/Andrew-M-C/go.util/china/uscc"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	cv = convey.Convey
+	so = convey.So
+	eq = convey.ShouldEqual
+
+	isNil    = convey.ShouldBeNil
+	isErr    = convey.ShouldBeError
+	isTrue   = convey.ShouldBeTrue
+	isFalse  = convey.ShouldBeFalse
+	notEmpty = convey.ShouldNotBeEmpty
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(m.Run())
+}
+
+func TestGeneral(t *testing.T) {
+	cv("基础逻辑", t, func() {
+		c, err := uscc.New("91350100M000100Y43")
+		so(err, isNil)
+		t.Log(c.DetailInfo())
+
+		so(c.Authority(), eq, uscc.MarketRegulation)
+		so(c.Category().String(), eq, "企业")
+		so(c.DivisionCode(), eq, "350100")
+		so(admindivision.DescribeDivisionChain(c.Division(), "/"), eq, "福建省/福州市")
+		so(c.OrganizationCode(), eq, "M000100Y4")
+	})
+
+	cv("小写和空白", t, func() {
+		c, err := uscc.New(" 91350100m000100y43\n")
+		so(err, isNil)
+		so(c.String(), eq, "91350100M000100Y43")
+	})
+
+	cv("非法输入", t, func() {
+		_, err := uscc.New("91350100M000100Y4")
+		so(err, isErr)
+		_, err = uscc.New("91350100M000100Y44")
+		so(err, isErr)
+		_, err = uscc.New("91350100M0001O0Y43")
+		so(err, isErr)
+		so(uscc.Validate("B1350100M000100Y43"), isFalse)
+
+		// 区划代码未知时, 无论校验码是什么都不合法
+		for _, check := range "0123456789ABCDEFGHJKLMNPQRTUWXY" {
+			so(uscc.Validate("91449999M000100Y4"+string(check)), isFalse)
+		}
+	})
+
+	cv("零值", t, func() {
+		c := uscc.Code{}
+		so(c.String(), eq, "<no code>")
+		so(c.Division(), isNil)
+		so(c.ValidOrganizationCode(), isFalse)
+	})
+}
+
+func TestGenerate(t *testing.T) {
+	cv("指定类别和区划", t, func() {
+		cat := uscc.Category{Authority: uscc.CivilAffairs, Code: '3'}
+		c, err := uscc.Generate(cat, "440106")
+		so(err, isNil)
+		t.Log(c, c.DetailInfo())
+
+		so(uscc.Validate(c.String()), isTrue)
+		so(c.ValidOrganizationCode(), isTrue)
+		so(c.Category().String(), eq, "基金会")
+		so(admindivision.DescribeDivisionChain(c.Division(), "/"), eq, "广东省/广州市/天河区")
+	})
+
+	cv("非法参数", t, func() {
+		_, err := uscc.Generate(uscc.Category{Authority: uscc.MarketRegulation, Code: '9'}, "440106")
+		so(err, isErr)
+		_, err = uscc.Generate(uscc.Category{Authority: uscc.MarketRegulation, Code: '1'}, "4401")
+		so(err, isErr)
+
+		// 不设区县的地级市
+		c, err := uscc.Generate(uscc.Category{Authority: uscc.MarketRegulation, Code: '1'}, "441900")
+		so(err, isNil)
+		so(admindivision.DescribeDivisionChain(c.Division(), "/"), eq, "广东省/东莞市")
+
+		// 省级存在但县级不存在的区划代码
+		_, err = uscc.Generate(uscc.Category{Authority: uscc.MarketRegulation, Code: '1'}, "449999")
+		so(err, isErr)
+		_, err = uscc.Generate(uscc.Category{Authority: uscc.MarketRegulation, Code: '1'}, "440199")
+		so(err, isErr)
+	})
+
+	cv("随机生成", t, func() {
+		for range 1000 {
+			c := uscc.Random()
+			_, err := uscc.New(c.String())
+			so(err, isNil)
+			so(c.ValidOrganizationCode(), isTrue)
+			so(c.Division(), notEmpty)
+		}
+	})
+}
@@ -94,7 +94,7 @@ type HTMLResult struct {
 	Images  map[string]struct{} // 引用的所有图片链接
 }
 
-// GetHTML 下载 html 静态内容和网站设置的cookie
+// GetHTML 下载 html 静态内容和网站设置的cookie。页面编码由浏览器检测并解码, 返回的内容为 UTF-8
 func GetHTML(ctx context.Context, targetURL string, opts ...Option) (*HTMLResult, error) {
 	o := mergeOptions(opts...)
 
@@ -103,6 +103,20 @@ func GetHTML(ctx context.Context, targetURL string, opts ...Option) (*HTMLResult
 		return nil, errors.New("请先使用 NewBrowser 创建浏览器")
 	}
 
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, fmt.Errorf("解析 URL 失败 (%w)", err)
+	}
+	if o.limiter != nil {
+		start := time.Now()
+		release, err := o.limiter.Acquire(ctx, u.Host)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		o.debug("限流等待 %v", time.Since(start))
+	}
+
 	// 存储渲染后的 HTML
 	var htmlContent string
 	var cookies []*network.Cookie
@@ -119,7 +133,7 @@ func GetHTML(ctx context.Context, targetURL string, opts ...Option) (*HTMLResult
 
 	// 执行浏览器操作
 	start := time.Now()
-	err := chromedp.Run(ctx,
+	err = chromedp.Run(ctx,
 		// 添加事件监听器
 		chromedp.ActionFunc(func(ctx context.Context) error {
 			chromedp.ListenTarget(ctx, func(ev interface{}) {
@@ -158,6 +172,18 @@ func GetHTML(ctx context.Context, targetURL string, opts ...Option) (*HTMLResult
 			}
 			return nil
 		}),
+		// 将 jar 中的 cookie 写入浏览器
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			if o.jar == nil {
+				return nil
+			}
+			for _, c := range o.jar.Cookies(u) {
+				if err := network.SetCookie(c.Name, c.Value).WithURL(targetURL).Do(ctx); err != nil {
+					return fmt.Errorf("设置 cookie '%s' 失败 (%w)", c.Name, err)
+				}
+			}
+			return nil
+		}),
 		chromedp.Navigate(targetURL),
 		// 同时处理超时和正常流程
 		chromedp.ActionFunc(func(ctx context.Context) error {
@@ -199,6 +225,10 @@ func GetHTML(ctx context.Context, targetURL string, opts ...Option) (*HTMLResult
 		return nil, fmt.Errorf("执行 chrome 操作失败 (%w)", err)
 	}
 
+	if o.jar != nil {
+		saveCookiesToJar(o.jar, u, cookies)
+	}
+
 	if len(targetURL) > 50 {
 		o.debug("耗时 %v - %s...", time.Since(start), targetURL[:50])
 	} else {
@@ -2,7 +2,11 @@
 package crawler
 
 import (
+	"context"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/chromedp/cdproto/network"
@@ -34,6 +38,9 @@ type options struct {
 
 	// 额外的 HTTP header 配置
 	headers http.Header
+
+	limiter Limiter
+	jar     http.CookieJar
 }
 
 func mergeOptions(opts ...Option) *options {
@@ -92,6 +99,27 @@ func WithLanguage(l string) Option {
 	}
 }
 
+// Limiter 按照 key (目标 host) 限流, net/http 包的 *LimiterRegistry 实现了该接口,
+// 可以与 HTTP 客户端共享同一组限流器
+type Limiter interface {
+	Acquire(ctx context.Context, key string) (release func(), err error)
+}
+
+// WithLimiter 设置限流器, 访问页面前按照目标 host 等待限流
+func WithLimiter(l Limiter) Option {
+	return func(o *options) {
+		o.limiter = l
+	}
+}
+
+// WithCookieJar 设置 cookie jar, 访问页面前将 jar 中的 cookie 写入浏览器, 页面加载后将浏览器中的 cookie
+// 保存到 jar 中。可以使用 net/http/cookiejar 包实现持久化, 并与 HTTP 客户端共享
+func WithCookieJar(jar http.CookieJar) Option {
+	return func(o *options) {
+		o.jar = jar
+	}
+}
+
 // ChromeCookiesToStandard 将 chromedp 的 cookie 类型转为 Go 标准库的类型
 func ChromeCookiesToStandard(cookies []*network.Cookie) []*http.Cookie {
 	res := make([]*http.Cookie, 0, len(cookies))
@@ -117,6 +145,59 @@ func ChromeCookiesToStandard(cookies []*network.Cookie) []*http.Cookie {
 	return res
 }
 
+// saveCookiesToJar 将浏览器中的 cookie 保存到 jar 中。浏览器中 host-only 的 cookie 的域名没有前导 '.',
+// 会话 cookie 没有过期时间。浏览器返回的 cookie 还包括子页面和重定向之后的域名下的 cookie, 因此按照
+// 各自的域名、路径构造 URL 分组保存, 而不是全部归到请求的 URL 下
+func saveCookiesToJar(jar http.CookieJar, u *url.URL, cookies []*network.Cookie) {
+	cookies = slices.DeleteFunc(slices.Clone(cookies), func(c *network.Cookie) bool { return c == nil })
+	res := ChromeCookiesToStandard(cookies)
+
+	type group struct {
+		u       *url.URL
+		cookies []*http.Cookie
+	}
+	var groups []*group
+	groupByURL := map[string]*group{}
+
+	for i, c := range cookies {
+		std := res[i]
+		if !strings.HasPrefix(c.Domain, ".") {
+			std.Domain = ""
+		}
+		if c.Session || c.Expires <= 0 {
+			std.Expires = time.Time{}
+		}
+
+		cu := cookieURL(u, c)
+		g, exist := groupByURL[cu.String()]
+		if !exist {
+			g = &group{u: cu}
+			groupByURL[cu.String()] = g
+			groups = append(groups, g)
+		}
+		g.cookies = append(g.cookies, std)
+	}
+	for _, g := range groups {
+		jar.SetCookies(g.u, g.cookies)
+	}
+}
+
+// cookieURL 根据 cookie 的域名、路径和 Secure 属性构造 cookie 所属的 URL, 没有域名时使用请求的 URL
+func cookieURL(u *url.URL, c *network.Cookie) *url.URL {
+	host := strings.TrimPrefix(c.Domain, ".")
+	if host == "" {
+		return u
+	}
+	res := &url.URL{Scheme: "http", Host: host, Path: c.Path}
+	if c.Secure {
+		res.Scheme = "https"
+	}
+	if res.Path == "" {
+		res.Path = "/"
+	}
+	return res
+}
+
 func convertSameSite(sameSite network.CookieSameSite) http.SameSite {
 	switch sameSite {
 	case network.CookieSameSiteStrict:
@@ -2,8 +2,10 @@ package crawler_test
 
 import (
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/Andrew-M-C/go.util/crawler"
 	"github.com/smartystreets/goconvey/convey"
 )
 
@@ -18,3 +20,16 @@ var (
 func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
+
+func TestDecodeHTML(t *testing.T) {
+	cv("GBK 网页解码后提取文本", t, func() {
+		// "中文网页" 的 GBK 编码
+		b := []byte("<html><body><p>\xd6\xd0\xce\xc4\xcd\xf8\xd2\xb3</p></body></html>")
+		s, err := crawler.DecodeHTML(b, "text/html")
+		so(err, isNil)
+
+		text, err := crawler.ExtractText(s)
+		so(err, isNil)
+		so(strings.TrimSpace(text), convey.ShouldEqual, "中文网页")
+	})
+}
@@ -0,0 +1,17 @@
+package crawler
+
+import (
+	"fmt"
+
+	"github.com/Andrew-M-C/go.util/net/charset"
+)
+
+// DecodeHTML 自动检测 HTML 字节的编码 (Content-Type、meta 标签、BOM 以及统计推测) 并转换为 UTF-8 字符串,
+// 用于在 ExtractText 之前处理 GBK、Big5 等非 UTF-8 网页。GetHTML 返回的内容已经由浏览器解码, 无需再转换
+func DecodeHTML(b []byte, contentType string) (string, error) {
+	res, r, err := charset.DecodeToUTF8(b, contentType)
+	if err != nil {
+		return "", fmt.Errorf("按照 %s 编码解码失败 (%w)", r.Name, err)
+	}
+	return string(res), nil
+}
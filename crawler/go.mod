@@ -1,10 +1,9 @@
 module github.com/Andrew-M-C/go.util/crawler
 
-go 1.23
-
-toolchain go1.23.5
+go 1.23.5
 
 require (
+	github.com/Andrew-M-C/go.util/net v0.0.0-20261019165558-85d1df2f2e9c
 	github.com/chromedp/cdproto v0.0.0-20250509201441-70372ae9ef75
 	github.com/chromedp/chromedp v0.13.6
 	github.com/smartystreets/goconvey v1.8.1
@@ -21,4 +20,5 @@ require (
 	github.com/jtolds/gls v4.20.0+incompatible // indirect
 	github.com/smarty/assertions v1.15.0 // indirect
 	golang.org/x/sys v0.29.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
 )
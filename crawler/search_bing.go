@@ -56,7 +56,7 @@ func getBingBody(ctx context.Context, keywords string, o *options) (string, erro
 	o.debug("target URL: '%s'", finalTargetURL)
 
 	start := time.Now()
-	res, err := GetHTML(ctx, finalTargetURL)
+	res, err := GetHTML(ctx, finalTargetURL, WithLimiter(o.limiter))
 	ela := time.Since(start)
 	if err != nil {
 		return "", err
@@ -49,7 +49,7 @@ func getGoogleBody(ctx context.Context, keywords string, o *options) (string, er
 	o.debug("target URL: '%s'", finalTargetURL)
 
 	start := time.Now()
-	res, err := GetHTML(ctx, finalTargetURL)
+	res, err := GetHTML(ctx, finalTargetURL, WithLimiter(o.limiter))
 	ela := time.Since(start)
 	if err != nil {
 		return "", err
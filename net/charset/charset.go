@@ -0,0 +1,244 @@
+// Package charset 检测文本 (主要是 HTML、XML 等网页内容) 的字符编码并转换为 UTF-8
+package charset
+
+import (
+	"bytes"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Source 表示编码的来源
+type Source string
+
+const (
+	SourceBOM         Source = "BOM"
+	SourceContentType Source = "Content-Type"
+	SourceMeta        Source = "meta"       // HTML <meta charset> 或 <meta http-equiv>
+	SourceXML         Source = "xml"        // XML 声明 <?xml encoding="..."?>
+	SourceStatistics  Source = "statistics" // 统计推测
+)
+
+// Result 表示检测结果
+type Result struct {
+	Encoding encoding.Encoding
+	Name     string // 小写的编码名称, 如 "utf-8"、"gb18030"、"big5"
+	Source   Source
+}
+
+// IsUTF8 表示是否为 UTF-8 (包括 ASCII), 无需转换
+func (r Result) IsUTF8() bool {
+	return r.Name == "utf-8"
+}
+
+var (
+	utf8Result = Result{Encoding: unicode.UTF8, Name: "utf-8"}
+
+	metaRegex = regexp.MustCompile(`(?i)<meta\s[^>]*charset\s*=\s*["']?\s*([a-z0-9_\-:.]+)`)
+	xmlRegex  = regexp.MustCompile(`(?i)^\s*<\?xml\s[^>]*encoding\s*=\s*["']([a-z0-9_\-:.]+)["']`)
+)
+
+// 检测 meta 标签时读取的最大长度
+const maxPrescan = 4096
+
+// Detect 按照以下优先级检测 b 的编码: BOM、contentType 中的 charset 参数、HTML meta 标签、XML 声明,
+// 都没有时根据内容统计推测。声明的编码无法识别时忽略该声明
+func Detect(b []byte, contentType string) Result {
+	if r, ok := detectBOM(b); ok {
+		return r
+	}
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		if r, ok := lookup(params["charset"], SourceContentType); ok {
+			return r
+		}
+	}
+	head := b[:min(len(b), maxPrescan)]
+	if m := xmlRegex.FindSubmatch(head); m != nil {
+		if r, ok := lookup(string(m[1]), SourceXML); ok {
+			return r
+		}
+	}
+	if m := metaRegex.FindSubmatch(head); m != nil {
+		if r, ok := lookup(string(m[1]), SourceMeta); ok {
+			// 按照 HTML 标准, meta 中声明的 UTF-16 实际为 UTF-8
+			if strings.HasPrefix(r.Name, "utf-16") {
+				r.Encoding, r.Name = unicode.UTF8, "utf-8"
+			}
+			return r
+		}
+	}
+	return guess(b)
+}
+
+// DecodeToUTF8 检测编码并转换为 UTF-8, BOM 会被去除
+func DecodeToUTF8(b []byte, contentType string) ([]byte, Result, error) {
+	r := Detect(b, contentType)
+	res, err := Decode(b, r.Encoding)
+	return res, r, err
+}
+
+// Decode 使用指定的编码将 b 转换为 UTF-8, BOM 会被去除
+func Decode(b []byte, enc encoding.Encoding) ([]byte, error) {
+	if enc == nil || enc == encoding.Nop {
+		return b, nil
+	}
+	if enc == unicode.UTF8 {
+		return bytes.TrimPrefix(b, utf8BOM), nil
+	}
+	res, _, err := transform.Bytes(unicode.BOMOverride(enc.NewDecoder()), b)
+	return res, err
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+func detectBOM(b []byte) (Result, bool) {
+	switch {
+	case bytes.HasPrefix(b, utf8BOM):
+		r := utf8Result
+		r.Source = SourceBOM
+		return r, true
+	case bytes.HasPrefix(b, []byte{0xFE, 0xFF}):
+		return Result{Encoding: unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM), Name: "utf-16be", Source: SourceBOM}, true
+	case bytes.HasPrefix(b, []byte{0xFF, 0xFE}):
+		return Result{Encoding: unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), Name: "utf-16le", Source: SourceBOM}, true
+	}
+	return Result{}, false
+}
+
+// Lookup 按照名称查找编码, 支持 WHATWG 标准中的名称和别名 (如 gb2312、gbk、big5、shift_jis)。
+// GBK 和 GB2312 统一使用其超集 GB18030 解码
+func Lookup(name string) (encoding.Encoding, string, bool) {
+	r, ok := lookup(name, "")
+	return r.Encoding, r.Name, ok
+}
+
+func lookup(name string, src Source) (Result, bool) {
+	name = strings.ToLower(strings.Trim(strings.TrimSpace(name), `"'`))
+	if name == "" {
+		return Result{}, false
+	}
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return Result{}, false
+	}
+	canonical, _ := htmlindex.Name(enc)
+	canonical = strings.ToLower(canonical)
+	if canonical == "gbk" {
+		enc, canonical = simplifiedchinese.GB18030, "gb18030"
+	}
+	return Result{Encoding: enc, Name: canonical, Source: src}, true
+}
+
+// -------- 统计推测 --------
+
+type candidate struct {
+	name string
+	enc  encoding.Encoding
+}
+
+var candidates = []candidate{
+	{"gb18030", simplifiedchinese.GB18030},
+	{"big5", traditionalchinese.Big5},
+	{"shift_jis", japanese.ShiftJIS},
+	{"euc-jp", japanese.EUCJP},
+	{"euc-kr", korean.EUCKR},
+}
+
+// guess 根据内容推测编码: 合法的 UTF-8 直接返回 UTF-8, 否则尝试常见的 CJK 编码, 按照解码失败的字符数量和
+// 常用字符的数量打分, 都不合适时使用 windows-1252
+func guess(b []byte) Result {
+	sample := b[:min(len(b), 64*1024)]
+	if utf8.Valid(trimIncompleteRune(sample)) {
+		r := utf8Result
+		r.Source = SourceStatistics
+		return r
+	}
+
+	best, bestScore := -1, 0
+	for i, c := range candidates {
+		s, err := c.enc.NewDecoder().Bytes(sample)
+		if err != nil {
+			continue
+		}
+		if score := scoreText(string(s), c.name); score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		enc, _ := htmlindex.Get("windows-1252")
+		return Result{Encoding: enc, Name: "windows-1252", Source: SourceStatistics}
+	}
+	c := candidates[best]
+	return Result{Encoding: c.enc, Name: c.name, Source: SourceStatistics}
+}
+
+// trimIncompleteRune 去掉采样截断导致的不完整的 UTF-8 字符
+func trimIncompleteRune(b []byte) []byte {
+	for i := 0; i < utf8.UTFMax && len(b) > 0; i++ {
+		r, size := utf8.DecodeLastRune(b)
+		if r != utf8.RuneError || size > 1 {
+			return b
+		}
+		b = b[:len(b)-1]
+	}
+	return b
+}
+
+func scoreText(s, name string) int {
+	score := 0
+	for _, r := range s {
+		switch {
+		case r == utf8.RuneError:
+			score -= 20
+		case r < 0x80:
+			// ASCII 不影响评分
+		case r >= 0x3040 && r <= 0x30FF: // 日文假名
+			if name == "shift_jis" || name == "euc-jp" {
+				score += 3
+			}
+		case r >= 0xAC00 && r <= 0xD7A3: // 韩文
+			if name == "euc-kr" {
+				score += 2
+			}
+		case name == "gb18030" && strings.ContainsRune(commonSimplified, r):
+			score += 3
+		case name == "big5" && strings.ContainsRune(commonTraditional, r):
+			score += 3
+		case r >= 0x4E00 && r <= 0x9FFF:
+			score++
+		default:
+			// 解码出的生僻符号大概率是错误的编码
+			score -= 2
+		}
+	}
+	return score
+}
+
+// 常用简体汉字
+const commonSimplified = "的一是在不了有和人这中大为上个国我以要他时来用们生到作地于出就分对成会可主发年动同工" +
+	"也能下过子说产种面而方后多定行学法所民得经十三之进着等部度家电力里如水化高自二理起小物现实加量都两体制机当使点" +
+	"从业本去把性好应开它合还因由其些然前外天政四日那社义事平形相全表间样与关各重新线内数正心反你明看原又么利比或但" +
+	"质气第向道命此变条只没结解问意建月公无系军很情者最立代想已通并提直题党程展五果料象员革位入常文总次品式活设及管" +
+	"特件长求老头基资边流路级少图山统接知较将组见计别她手角期根论运农指几九区强放决西被干做必战先回则任取据处队南给" +
+	"色光门即保治北造百规热领七海口东导器压志世金增争济阶油思术极交受联什认六共权收证改清己美再采转更单风切打白教速" +
+	"花带安场身车例真务具万每目至达走积示议声报斗完类八离华名确才科张信马节话米整空元况今集温传土许步群广石记需段研" +
+	"界拉林律叫且究观越织装影算低持音众书布复容儿须际商非验连断深难近矿千周委素技备半办青省列习响约支般史感劳便团往" +
+	"酸历市克何除消构府称太准精值号率族维划选标写存候毛亲快效斯院查江型眼王按格养易置派层片始却专状育厂京识适属圆包" +
+	"火住调满县局照参红细引听该铁价严龙飞网页新闻首登录搜索请"
+
+// 常用繁体汉字
+const commonTraditional = "的一是在不了有和人這中大為上個國我以要他時來用們生到作地於出就分對成會可主發年動同工" +
+	"也能下過子說產種面而方後多定行學法所民得經十三之進著等部度家電力裡如水化高自二理起小物現實加量都兩體制機當使點" +
+	"從業本去把性好應開它合還因由其些然前外天政四日那社義事平形相全表間樣與關各重新線內數正心反你明看原又麼利比或但" +
+	"質氣第向道命此變條只沒結解問意建月公無系軍很情者最立代想已通並提直題黨程展五果料象員革位入常文總次品式活設及管" +
+	"網頁新聞首頁登錄搜尋請臺灣"
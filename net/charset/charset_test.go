@@ -0,0 +1,90 @@
+package charset_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Andrew-M-C/go.util/net/charset"
+	"github.com/smartystreets/goconvey/convey"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+var (
+	cv = convey.Convey
+	so = convey.So
+	eq = convey.ShouldEqual
+
+	isNil = convey.ShouldBeNil
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(m.Run())
+}
+
+func encode(enc encoding.Encoding, s string) []byte {
+	b, err := enc.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestDetect(t *testing.T) {
+	const text = "中华人民共和国的首都是北京, 这是一个新闻网页的内容"
+	gbk := encode(simplifiedchinese.GBK, text)
+
+	cv("声明的编码", t, func() {
+		r := charset.Detect(gbk, "text/html; charset=GB2312")
+		so(r.Name, eq, "gb18030")
+		so(r.Source, eq, charset.SourceContentType)
+
+		r = charset.Detect([]byte(`<html><head><meta charset="gbk"></head>`), "text/html")
+		so(r.Name, eq, "gb18030")
+		so(r.Source, eq, charset.SourceMeta)
+
+		r = charset.Detect([]byte(`<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=big5">`), "")
+		so(r.Name, eq, "big5")
+		so(r.Source, eq, charset.SourceMeta)
+
+		r = charset.Detect([]byte(`<?xml version="1.0" encoding="Shift_JIS"?><a/>`), "application/xml")
+		so(r.Name, eq, "shift_jis")
+		so(r.Source, eq, charset.SourceXML)
+
+		r = charset.Detect([]byte("\xEF\xBB\xBFhello"), "text/plain; charset=gbk")
+		so(r.Name, eq, "utf-8")
+		so(r.Source, eq, charset.SourceBOM)
+
+		// 无法识别的声明被忽略
+		r = charset.Detect([]byte("hello"), "text/plain; charset=unknown-charset")
+		so(r.Name, eq, "utf-8")
+		so(r.Source, eq, charset.SourceStatistics)
+	})
+
+	cv("统计推测", t, func() {
+		cases := map[string][]byte{
+			"utf-8":     []byte(text),
+			"gb18030":   gbk,
+			"big5":      encode(traditionalchinese.Big5, "中華人民共和國的首都是北京, 這是一個新聞網頁的內容"),
+			"shift_jis": encode(japanese.ShiftJIS, "これは日本語のテキストです。カタカナもあります"),
+		}
+		for name, b := range cases {
+			r := charset.Detect(b, "text/html")
+			so(r.Name, eq, name)
+			so(r.Source, eq, charset.SourceStatistics)
+		}
+	})
+
+	cv("转换为 UTF-8", t, func() {
+		b, r, err := charset.DecodeToUTF8(gbk, "")
+		so(err, isNil)
+		so(r.Name, eq, "gb18030")
+		so(string(b), eq, text)
+
+		b, _, err = charset.DecodeToUTF8(append([]byte{0xFF, 0xFE}, 'h', 0, 'i', 0), "")
+		so(err, isNil)
+		so(string(b), eq, "hi")
+	})
+}
@@ -1,20 +1,34 @@
 module github.com/Andrew-M-C/go.util/net
 
-go 1.22.0
-
-toolchain go1.23.1
+go 1.23.5
 
 require (
 	github.com/Andrew-M-C/go-bytesize v0.0.0-20230105080248-c93b078d58b3
 	github.com/Andrew-M-C/go.jsonvalue v1.4.2
+	github.com/Andrew-M-C/go.util/context v0.0.0-20260119114102-eace2b0720d0
+	github.com/Andrew-M-C/go.util/csv v0.0.0-20260119114102-eace2b0720d0
+	github.com/Andrew-M-C/go.util/errors v0.0.0-20260119114102-eace2b0720d0
+	github.com/Andrew-M-C/go.util/log v0.0.0-20260119114102-eace2b0720d0
 	github.com/smartystreets/goconvey v1.8.1
 	golang.org/x/net v0.34.0
 	golang.org/x/text v0.21.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
+	github.com/Andrew-M-C/go.objectid v1.0.3 // indirect
+	github.com/Andrew-M-C/go.util/runtime v0.0.0-20240221044053-8b90aa4683c0 // indirect
+	github.com/Andrew-M-C/go.util/time v0.0.0-20240221044053-8b90aa4683c0 // indirect
+	github.com/fatih/color v1.18.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
 	github.com/gopherjs/gopherjs v1.17.2 // indirect
 	github.com/jtolds/gls v4.20.0+incompatible // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/shopspring/decimal v1.4.0 // indirect
 	github.com/smarty/assertions v1.15.0 // indirect
+	github.com/valyala/fastrand v1.1.0 // indirect
+	go.mongodb.org/mongo-driver v1.17.1 // indirect
+	golang.org/x/exp v0.0.0-20250106191152-7588d65b2ba8 // indirect
+	golang.org/x/sys v0.29.0 // indirect
 )
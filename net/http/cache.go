@@ -0,0 +1,367 @@
+package http
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedResponse 表示一个缓存的响应
+type CachedResponse struct {
+	StatusCode int               `json:"status_code"`
+	Header     http.Header       `json:"header"`
+	Body       []byte            `json:"body"`
+	Vary       map[string]string `json:"vary,omitempty"`       // 缓存时请求中 Vary 指定的请求头的值
+	StoredAt   time.Time         `json:"stored_at"`            // 缓存 (或重新验证) 的时间
+	ExpiresAt  time.Time         `json:"expires_at,omitempty"` // 过期时间, 零值表示每次都需要重新验证
+}
+
+// CacheStore 响应缓存的存储接口, 需要并发安全
+type CacheStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, rsp *CachedResponse)
+	Delete(key string)
+}
+
+// WithCache 为 GET 和 HEAD 请求启用响应缓存。缓存遵循 Cache-Control 的 max-age、no-cache、no-store
+// 以及 Expires, 过期后使用 If-None-Match/If-Modified-Since 重新验证。响应指定了 Vary 时, Vary 指定的
+// 请求头的值不同的请求分别缓存。命中缓存时 RequestProgress.CacheHit 返回 true
+func WithCache(store CacheStore) RequestOption {
+	return func(ro *requestOption) {
+		ro.cache = store
+	}
+}
+
+// cacheMiddleware 缓存中间件, 位于中间件链的最内层
+func (o *requestOption) cacheMiddleware(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			return next(req)
+		}
+		reqCC := parseCacheControl(req.Header)
+		if _, noStore := reqCC["no-store"]; noStore {
+			return next(req)
+		}
+
+		baseKey := req.Method + " " + req.URL.String()
+		key, entry, ok := o.cacheLookup(baseKey, req)
+		if ok {
+			_, noCache := reqCC["no-cache"]
+			if !noCache && time.Now().Before(entry.ExpiresAt) {
+				o.debugf("cache hit: %s", key)
+				return o.cacheHit(req, entry), nil
+			}
+			req = req.Clone(req.Context())
+			if etag := entry.Header.Get("ETag"); etag != "" {
+				req.Header.Set("If-None-Match", etag)
+			}
+			if lm := entry.Header.Get("Last-Modified"); lm != "" {
+				req.Header.Set("If-Modified-Since", lm)
+			}
+		}
+
+		rsp, err := next(req)
+		if err != nil {
+			return rsp, err
+		}
+		if ok && rsp.StatusCode == http.StatusNotModified {
+			_ = rsp.Body.Close()
+			for k, v := range rsp.Header {
+				entry.Header[k] = v
+			}
+			entry.StoredAt = time.Now()
+			entry.ExpiresAt = cacheExpiresAt(rsp.Header, entry.StoredAt)
+			o.cacheStore(baseKey, req, entry)
+			o.debugf("cache revalidated: %s", key)
+			return o.cacheHit(req, entry), nil
+		}
+
+		if !cacheable(rsp) {
+			if ok {
+				o.cache.Delete(key)
+				if key != baseKey {
+					// 最近的变体同时保存在 baseKey 中
+					if base, exist := o.cache.Get(baseKey); exist && base.varyMatches(req) {
+						o.cache.Delete(baseKey)
+					}
+				}
+			}
+			return rsp, nil
+		}
+		body, err := io.ReadAll(rsp.Body)
+		_ = rsp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		rsp.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := time.Now()
+		entry = &CachedResponse{
+			StatusCode: rsp.StatusCode,
+			Header:     rsp.Header.Clone(),
+			Body:       body,
+			StoredAt:   now,
+			ExpiresAt:  cacheExpiresAt(rsp.Header, now),
+		}
+		for _, v := range rsp.Header.Values("Vary") {
+			for _, h := range strings.Split(v, ",") {
+				if h = strings.TrimSpace(h); h != "" {
+					if entry.Vary == nil {
+						entry.Vary = map[string]string{}
+					}
+					entry.Vary[http.CanonicalHeaderKey(h)] = req.Header.Get(h)
+				}
+			}
+		}
+		o.cacheStore(baseKey, req, entry)
+		o.debugf("cache stored: %s, expires at %v", baseKey, entry.ExpiresAt)
+		return rsp, nil
+	}
+}
+
+// cacheLookup 查找缓存。baseKey 下保存的是该 URL 最近的响应, 它的 Vary 指明了区分变体的请求头,
+// 其他变体保存在由这些请求头的值生成的 key 下。返回值 key 为命中的变体对应的 key
+func (o *requestOption) cacheLookup(baseKey string, req *http.Request) (string, *CachedResponse, bool) {
+	base, ok := o.cache.Get(baseKey)
+	if !ok {
+		return baseKey, nil, false
+	}
+	if len(base.Vary) == 0 {
+		return baseKey, base, true
+	}
+	key := cacheVaryKey(baseKey, base.Vary, req)
+	if base.varyMatches(req) {
+		return key, base, true
+	}
+	if entry, ok := o.cache.Get(key); ok && entry.varyMatches(req) {
+		return key, entry, true
+	}
+	return key, nil, false
+}
+
+// cacheStore 保存缓存, 有 Vary 时同时保存在 baseKey 和变体的 key 下
+func (o *requestOption) cacheStore(baseKey string, req *http.Request, entry *CachedResponse) {
+	o.cache.Set(baseKey, entry)
+	if _, wildcard := entry.Vary["*"]; len(entry.Vary) > 0 && !wildcard {
+		o.cache.Set(cacheVaryKey(baseKey, entry.Vary, req), entry)
+	}
+}
+
+func cacheVaryKey(baseKey string, vary map[string]string, req *http.Request) string {
+	names := make([]string, 0, len(vary))
+	for k := range vary {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+
+	b := strings.Builder{}
+	b.WriteString(baseKey)
+	b.WriteString(" vary")
+	for _, k := range names {
+		b.WriteString(" ")
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(strconv.Quote(req.Header.Get(k)))
+	}
+	return b.String()
+}
+
+func (o *requestOption) cacheHit(req *http.Request, entry *CachedResponse) *http.Response {
+	if o.progress != nil {
+		o.progress.cacheHit = true
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(entry.StatusCode) + " " + http.StatusText(entry.StatusCode),
+		StatusCode:    entry.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        entry.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+		ContentLength: int64(len(entry.Body)),
+		Request:       req,
+	}
+}
+
+func (c *CachedResponse) varyMatches(req *http.Request) bool {
+	if _, ok := c.Vary["*"]; ok {
+		return false
+	}
+	for k, v := range c.Vary {
+		if req.Header.Get(k) != v {
+			return false
+		}
+	}
+	return true
+}
+
+func parseCacheControl(h http.Header) map[string]string {
+	res := map[string]string{}
+	for _, v := range h.Values("Cache-Control") {
+		for _, d := range strings.Split(v, ",") {
+			k, val, _ := strings.Cut(strings.TrimSpace(d), "=")
+			if k != "" {
+				res[strings.ToLower(k)] = strings.Trim(val, `"`)
+			}
+		}
+	}
+	return res
+}
+
+// cacheable 判断响应是否可以缓存: 200 状态码, 没有 no-store, 并且有过期时间或者验证器
+func cacheable(rsp *http.Response) bool {
+	if rsp.StatusCode != http.StatusOK {
+		return false
+	}
+	cc := parseCacheControl(rsp.Header)
+	if _, noStore := cc["no-store"]; noStore {
+		return false
+	}
+	if rsp.Header.Get("ETag") != "" || rsp.Header.Get("Last-Modified") != "" {
+		return true
+	}
+	return cacheExpiresAt(rsp.Header, time.Now()).After(time.Now())
+}
+
+func cacheExpiresAt(h http.Header, now time.Time) time.Time {
+	cc := parseCacheControl(h)
+	if _, noCache := cc["no-cache"]; noCache {
+		return time.Time{}
+	}
+	if v, ok := cc["max-age"]; ok {
+		sec, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || sec <= 0 {
+			return time.Time{}
+		}
+		return now.Add(time.Duration(sec) * time.Second)
+	}
+	if v := h.Get("Expires"); v != "" {
+		if t, err := http.ParseTime(v); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// -------- 内存 LRU 缓存 --------
+
+type memoryCache struct {
+	lock     sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+type memoryCacheItem struct {
+	key string
+	rsp *CachedResponse
+}
+
+// NewMemoryCache 新建一个内存 LRU 缓存, capacity 为最多缓存的响应数量
+func NewMemoryCache(capacity int) CacheStore {
+	return &memoryCache{
+		capacity: max(capacity, 1),
+		items:    map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+func (c *memoryCache) Get(key string) (*CachedResponse, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	rsp := *e.Value.(*memoryCacheItem).rsp
+	rsp.Header = rsp.Header.Clone()
+	return &rsp, true
+}
+
+func (c *memoryCache) Set(key string, rsp *CachedResponse) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, ok := c.items[key]; ok {
+		e.Value.(*memoryCacheItem).rsp = rsp
+		c.order.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.order.PushFront(&memoryCacheItem{key: key, rsp: rsp})
+	for c.order.Len() > c.capacity {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.items, e.Value.(*memoryCacheItem).key)
+	}
+}
+
+func (c *memoryCache) Delete(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.order.Remove(e)
+		delete(c.items, key)
+	}
+}
+
+// -------- 磁盘缓存 --------
+
+type diskCache struct {
+	dir string
+}
+
+// NewDiskCache 新建一个磁盘缓存, 每个响应以 JSON 格式保存在 dir 下的一个文件中
+func NewDiskCache(dir string) (CacheStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return diskCache{dir: dir}, nil
+}
+
+func (c diskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c diskCache) Get(key string) (*CachedResponse, bool) {
+	b, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	rsp := &CachedResponse{}
+	if err := json.Unmarshal(b, rsp); err != nil {
+		return nil, false
+	}
+	return rsp, true
+}
+
+func (c diskCache) Set(key string, rsp *CachedResponse) {
+	b, err := json.Marshal(rsp)
+	if err != nil {
+		return
+	}
+	// 先写临时文件再重命名, 避免并发读到不完整的内容
+	p := c.path(key)
+	tmp := p + "." + strconv.FormatInt(time.Now().UnixNano(), 36) + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		_ = os.Remove(tmp)
+	}
+}
+
+func (c diskCache) Delete(key string) {
+	_ = os.Remove(c.path(key))
+}
@@ -0,0 +1,147 @@
+package http_test
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Andrew-M-C/go.util/net/http"
+)
+
+func TestCache(t *testing.T) {
+	var lock sync.Mutex
+	counts := map[string]int{}
+	revalidated := 0
+	svr := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		counts[r.URL.Path]++
+
+		switch r.URL.Path {
+		case "/max-age":
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		case "/etag":
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				revalidated++
+				w.WriteHeader(nethttp.StatusNotModified)
+				return
+			}
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "X-Lang")
+		}
+		_, _ = fmt.Fprintf(w, "%s-%d-%s", r.URL.Path, counts[r.URL.Path], r.Header.Get("X-Lang"))
+	}))
+	defer svr.Close()
+
+	get := func(store http.CacheStore, path string, opts ...http.RequestOption) (string, bool) {
+		hit := false
+		opts = append(opts, http.WithCache(store), http.WithProgressCallback(func(p *http.RequestProgress) {
+			hit = p.CacheHit()
+		}))
+		b, err := http.Raw(context.Background(), svr.URL+path, opts...)
+		so(err, isNil)
+		return string(b), hit
+	}
+	lang := func(l string) http.RequestOption {
+		return http.WithRequestHeader(nethttp.Header{"X-Lang": []string{l}})
+	}
+
+	stores := map[string]func() http.CacheStore{
+		"内存缓存": func() http.CacheStore { return http.NewMemoryCache(16) },
+		"磁盘缓存": func() http.CacheStore {
+			s, err := http.NewDiskCache(t.TempDir())
+			so(err, isNil)
+			return s
+		},
+	}
+	for name, newStore := range stores {
+		cv(name, t, func() {
+			lock.Lock()
+			counts, revalidated = map[string]int{}, 0
+			lock.Unlock()
+			store := newStore()
+
+			cv("max-age", func() {
+				b, hit := get(store, "/max-age")
+				so(b, eq, "/max-age-1-")
+				so(hit, eq, false)
+				b, hit = get(store, "/max-age")
+				so(b, eq, "/max-age-1-")
+				so(hit, eq, true)
+				so(counts["/max-age"], eq, 1)
+
+				// 请求指定 no-cache 时需要重新请求
+				b, _ = get(store, "/max-age", http.WithRequestHeader(nethttp.Header{
+					"Cache-Control": []string{"no-cache"},
+				}))
+				so(b, eq, "/max-age-2-")
+			})
+
+			cv("ETag 重新验证", func() {
+				b, hit := get(store, "/etag")
+				so(b, eq, "/etag-1-")
+				so(hit, eq, false)
+				b, hit = get(store, "/etag")
+				so(b, eq, "/etag-1-")
+				so(hit, eq, true)
+				so(counts["/etag"], eq, 2)
+				so(revalidated, eq, 1)
+			})
+
+			cv("no-store", func() {
+				b, _ := get(store, "/no-store")
+				so(b, eq, "/no-store-1-")
+				b, hit := get(store, "/no-store")
+				so(b, eq, "/no-store-2-")
+				so(hit, eq, false)
+			})
+
+			cv("Vary", func() {
+				b, _ := get(store, "/vary", lang("zh"))
+				so(b, eq, "/vary-1-zh")
+				b, hit := get(store, "/vary", lang("zh"))
+				so(b, eq, "/vary-1-zh")
+				so(hit, eq, true)
+				b, hit = get(store, "/vary", lang("en"))
+				so(b, eq, "/vary-2-en")
+				so(hit, eq, false)
+
+				// 不同的变体互不覆盖
+				b, hit = get(store, "/vary", lang("zh"))
+				so(b, eq, "/vary-1-zh")
+				so(hit, eq, true)
+				b, hit = get(store, "/vary", lang("en"))
+				so(b, eq, "/vary-2-en")
+				so(hit, eq, true)
+				so(counts["/vary"], eq, 2)
+			})
+		})
+	}
+
+	cv("LRU 淘汰", t, func() {
+		store := http.NewMemoryCache(2)
+		rsp := &http.CachedResponse{StatusCode: 200}
+		store.Set("a", rsp)
+		store.Set("b", rsp)
+		_, _ = store.Get("a")
+		store.Set("c", rsp)
+
+		_, ok := store.Get("a")
+		so(ok, eq, true)
+		_, ok = store.Get("b")
+		so(ok, eq, false)
+		_, ok = store.Get("c")
+		so(ok, eq, true)
+
+		store.Delete("c")
+		_, ok = store.Get("c")
+		so(ok, eq, false)
+	})
+}
@@ -0,0 +1,194 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Client 可复用的 HTTP 客户端, 保存一组默认的请求选项, 如 base URL、请求头、超时时间和 transport 等。
+// 每次请求时, 调用方传入的选项在默认选项之后生效, 其中请求头替换同名的默认请求头。包级别的 JSON、XML、
+// Raw、DownloadFile 等函数相当于使用一个没有默认选项的 Client
+type Client struct {
+	opts []RequestOption
+}
+
+var defaultClient = &Client{}
+
+// NewClient 新建一个客户端, opts 作为该客户端每次请求的默认选项
+func NewClient(opts ...RequestOption) *Client {
+	return &Client{opts: slices.Clone(opts)}
+}
+
+// With 基于当前客户端的默认选项, 追加新的默认选项, 返回一个新的客户端
+func (c *Client) With(opts ...RequestOption) *Client {
+	return &Client{opts: append(slices.Clone(c.opts), opts...)}
+}
+
+// mergeOptions 先应用客户端的默认选项, 再应用本次请求的选项。本次请求指定的请求头替换同名的默认请求头,
+// 而不是追加在后面
+func (c *Client) mergeOptions(opts []RequestOption, marshaler marshalerType) *requestOption {
+	if len(c.opts) == 0 {
+		return mergeOptions(opts, marshaler)
+	}
+	var defaults http.Header
+	all := make([]RequestOption, 0, len(c.opts)+len(opts)+2)
+	all = append(all, c.opts...)
+	all = append(all, func(ro *requestOption) {
+		defaults, ro.header = ro.header, http.Header{}
+	})
+	all = append(all, opts...)
+	all = append(all, func(ro *requestOption) {
+		for k, v := range defaults {
+			if _, exist := ro.header[k]; !exist {
+				ro.header[k] = v
+			}
+		}
+	})
+	return mergeOptions(all, marshaler)
+}
+
+// Request 发起一个请求, 但是返回 *http.Response。适合 HTTP SSE 场景适配
+func (c *Client) Request(ctx context.Context, targetURL string, opts ...RequestOption) (*http.Response, error) {
+	o := c.mergeOptions(opts, json.Marshal)
+	return raw(ctx, targetURL, o)
+}
+
+// Raw 发起一个请求, 但是返回 []byte。文本类型 (text/*、JSON、XML 等) 的响应会自动检测编码并转换为
+// UTF-8, 参见 WithResponseCharset
+func (c *Client) Raw(ctx context.Context, targetURL string, opts ...RequestOption) ([]byte, error) {
+	o := c.mergeOptions(opts, json.Marshal)
+	httpRsp, err := raw(ctx, targetURL, o)
+	if err != nil {
+		return nil, err
+	}
+	defer httpRsp.Body.Close()
+
+	b, err := readBody(o, httpRsp.Header.Get("Content-Encoding"), httpRsp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if o.rspCharset != nil || isTextContent(httpRsp.Header.Get("Content-Type")) {
+		b = decodeIfNecessary(o, b, httpRsp)
+	}
+	return b, nil
+}
+
+// JSON 发起一个 JSON 请求, 并将响应反序列化到 rsp 中, rsp 应为指针。如果响应为 2xx、304 或者是 HEAD
+// 请求, 则允许响应体为空 (如 201、202 等创建或受理类接口), 此时 rsp 保持不变
+func (c *Client) JSON(ctx context.Context, targetURL string, rsp any, opts ...RequestOption) error {
+	o := c.mergeOptions(opts, json.Marshal)
+	if o.body != nil && o.header.Get("Content-Type") != "" {
+		o.header.Set("Content-Type", "application/json")
+	}
+	httpRsp, b, err := rawAndRead(ctx, targetURL, o)
+	if err != nil {
+		return packError(httpRsp, b, err)
+	}
+	if len(b) == 0 {
+		if noContentExpected(o, httpRsp) {
+			o.debugf("no content in response, status %v", httpRsp.Status)
+			return nil
+		}
+		return ErrEmptyBody
+	}
+
+	o.debugf("response: '%s'", b)
+	o.debugf("response header: %+v", httpRsp.Header)
+
+	b = decodeIfNecessary(o, b, httpRsp)
+	if err := json.Unmarshal(b, rsp); err != nil {
+		return fmt.Errorf("json.Unmarshal error (%w)", err)
+	}
+	return nil
+}
+
+// XMLGetRspBody 发起一个 XML 请求并返回包体字节。与 JSON 相同, 特定情况下允许响应体为空
+func (c *Client) XMLGetRspBody(ctx context.Context, targetURL string, opts ...RequestOption) ([]byte, error) {
+	o := c.mergeOptions(opts, xml.Marshal)
+	if o.body != nil {
+		o.header.Set("Content-Type", "application/xml")
+	}
+	httpRsp, b, err := rawAndRead(ctx, targetURL, o)
+	if err != nil {
+		return nil, packError(httpRsp, b, err)
+	}
+	if len(b) == 0 {
+		if noContentExpected(o, httpRsp) {
+			o.debugf("no content in response, status %v", httpRsp.Status)
+			return nil, nil
+		}
+		return nil, ErrEmptyBody
+	}
+
+	o.debugf("response: '%s'", b)
+	o.debugf("response header: %+v", httpRsp.Header)
+
+	b = decodeIfNecessary(o, b, httpRsp)
+	return b, nil
+}
+
+// XML 发起一个 XML 请求, 并将响应反序列化到 rsp 中, rsp 应为指针
+func (c *Client) XML(ctx context.Context, targetURL string, rsp any, opts ...RequestOption) error {
+	b, err := c.XMLGetRspBody(ctx, targetURL, opts...)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	if err := xml.Unmarshal(b, rsp); err != nil {
+		return fmt.Errorf("xml.Unmarshal error (%w)", err)
+	}
+	return nil
+}
+
+// httpClient 按照选项构建本次请求使用的 http.Client
+func (o *requestOption) httpClient() *http.Client {
+	cli := &http.Client{Transport: http.DefaultTransport}
+	if o.client != nil {
+		c := *o.client
+		cli = &c
+	}
+	if o.transport != nil {
+		cli.Transport = o.transport
+	}
+	if o.timeout > 0 {
+		cli.Timeout = o.timeout
+	}
+	if o.jar != nil {
+		cli.Jar = o.jar
+	}
+	base := cli.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	cli.Transport = o.wrapMiddlewares(base)
+	return cli
+}
+
+// resolveURL 如果指定了 base URL 并且目标 URL 不是绝对地址, 则将两者拼接
+func (o *requestOption) resolveURL(targetURL string) string {
+	if o.baseURL == "" || strings.Contains(targetURL, "://") {
+		return targetURL
+	}
+	if targetURL == "" {
+		return o.baseURL
+	}
+	return strings.TrimRight(o.baseURL, "/") + "/" + strings.TrimLeft(targetURL, "/")
+}
+
+// noContentExpected 判断响应体为空时是否视为没有内容, 而不是错误
+func noContentExpected(o *requestOption, rsp *http.Response) bool {
+	if o.method == http.MethodHead {
+		return true
+	}
+	if rsp.StatusCode == http.StatusNotModified {
+		return true
+	}
+	return rsp.StatusCode >= 200 && rsp.StatusCode < 300
+}
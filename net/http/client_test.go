@@ -0,0 +1,243 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Andrew-M-C/go.util/net/http"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestClient(t *testing.T) {
+	svr := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if strings.HasSuffix(r.URL.Path, "/slow") {
+			time.Sleep(200 * time.Millisecond)
+		}
+		_ = json.NewEncoder(w).Encode(testSt{
+			Int: len(r.Header.Values("X-Test")),
+			Str: r.URL.Path + "|" + r.Header.Get("X-Client"),
+		})
+	}))
+	defer svr.Close()
+
+	cli := http.NewClient(
+		http.WithBaseURL(svr.URL+"/api/"),
+		http.WithRequestHeader(nethttp.Header{"X-Client": []string{"demo"}}),
+		http.WithDebugger(t.Logf),
+	)
+
+	cv("默认选项", t, func() {
+		rsp := testSt{}
+		err := cli.JSON(context.Background(), "/users", &rsp,
+			http.WithRequestHeader(nethttp.Header{"X-Test": []string{"1"}}),
+		)
+		so(err, isNil)
+		so(rsp.Str, eq, "/api/users|demo")
+		so(rsp.Int, eq, 1)
+
+		// 默认选项不会因为多次请求而累积
+		err = cli.JSON(context.Background(), "users", &rsp)
+		so(err, isNil)
+		so(rsp.Int, eq, 0)
+
+		// 绝对地址不拼接 base URL
+		err = cli.JSON(context.Background(), svr.URL+"/other", &rsp)
+		so(err, isNil)
+		so(rsp.Str, eq, "/other|demo")
+	})
+
+	cv("派生客户端", t, func() {
+		sub := cli.With(http.WithRequestHeader(nethttp.Header{"X-Test": []string{"1", "2"}}))
+		b, err := sub.Raw(context.Background(), "sub")
+		so(err, isNil)
+
+		rsp := testSt{}
+		so(json.Unmarshal(b, &rsp), isNil)
+		so(rsp.Int, eq, 2)
+		so(rsp.Str, eq, "/api/sub|demo")
+
+		// 请求时指定的请求头替换默认请求头
+		err = sub.JSON(context.Background(), "sub", &rsp, http.WithRequestHeader(nethttp.Header{
+			"X-Test":   []string{"3"},
+			"X-Client": []string{"override"},
+		}))
+		so(err, isNil)
+		so(rsp.Int, eq, 1)
+		so(rsp.Str, eq, "/api/sub|override")
+	})
+
+	cv("超时", t, func() {
+		_, err := cli.Raw(context.Background(), "slow", http.WithTimeout(50*time.Millisecond))
+		so(err, isErr)
+
+		_, err = cli.Raw(context.Background(), "slow", http.WithTimeout(time.Second))
+		so(err, isNil)
+	})
+
+	cv("自定义 transport", t, func() {
+		var count int64
+		tr := roundTripFunc(func(r *nethttp.Request) (*nethttp.Response, error) {
+			atomic.AddInt64(&count, 1)
+			return nethttp.DefaultTransport.RoundTrip(r)
+		})
+		rsp, err := http.JSON[testSt](context.Background(), svr.URL+"/tr",
+			http.WithClient(&nethttp.Client{}), http.WithTransport(tr),
+		)
+		so(err, isNil)
+		so(rsp.Str, eq, "/tr|")
+		so(atomic.LoadInt64(&count), eq, 1)
+	})
+}
+
+type roundTripFunc func(*nethttp.Request) (*nethttp.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *nethttp.Request) (*nethttp.Response, error) {
+	return f(r)
+}
+
+func TestStatus(t *testing.T) {
+	type errBody struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+
+	svr := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		switch r.URL.Path {
+		case "/created":
+			w.WriteHeader(nethttp.StatusCreated)
+			_, _ = w.Write([]byte(`{"int":201}`))
+		case "/no-content":
+			w.WriteHeader(nethttp.StatusNoContent)
+		case "/empty":
+			w.WriteHeader(nethttp.StatusOK)
+		case "/accepted":
+			w.WriteHeader(nethttp.StatusAccepted)
+		case "/not-found":
+			w.WriteHeader(nethttp.StatusNotFound)
+		default:
+			w.WriteHeader(nethttp.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":10001,"message":"invalid param"}`))
+		}
+	}))
+	defer svr.Close()
+	ctx := context.Background()
+
+	cv("2xx 均视为成功", t, func() {
+		rsp, err := http.JSON[testSt](ctx, svr.URL+"/created")
+		so(err, isNil)
+		so(rsp.Int, eq, 201)
+
+		rsp, err = http.JSON[testSt](ctx, svr.URL+"/no-content")
+		so(err, isNil)
+		so(rsp.Int, eq, 0)
+
+		// 2xx 的空响应体视为没有内容
+		rsp, err = http.JSON[testSt](ctx, svr.URL+"/empty")
+		so(err, isNil)
+		so(rsp.Int, eq, 0)
+
+		rsp, err = http.JSON[testSt](ctx, svr.URL+"/accepted", http.WithMethod("POST"))
+		so(err, isNil)
+		so(rsp.Int, eq, 0)
+
+		b, err := http.XMLGetRspBody(ctx, svr.URL+"/accepted")
+		so(err, isNil)
+		so(len(b), eq, 0)
+	})
+
+	cv("指定成功状态码", t, func() {
+		_, err := http.JSON[testSt](ctx, svr.URL+"/created", http.WithAcceptedStatus(nethttp.StatusOK))
+		so(err, isErr)
+
+		e, ok := http.UnwrapError(err)
+		so(ok, eq, true)
+		so(e.Detail().StatusCode, eq, nethttp.StatusCreated)
+
+		// 非 2xx 状态码被指定为成功时, 空响应体仍然视为错误
+		_, err = http.JSON[testSt](ctx, svr.URL+"/not-found", http.WithAcceptedStatus(nethttp.StatusNotFound))
+		so(errors.Is(err, http.ErrEmptyBody), eq, true)
+	})
+
+	cv("解析错误响应体", t, func() {
+		_, err := http.JSON[testSt](ctx, svr.URL+"/bad")
+		so(err, isErr)
+
+		body, ok := http.ErrorBody[errBody](err)
+		so(ok, eq, true)
+		so(body.Code, eq, 10001)
+		so(body.Message, eq, "invalid param")
+
+		e, _ := http.UnwrapError(err)
+		var m map[string]any
+		so(e.Detail().DecodeJSON(&m), isNil)
+		so(m["message"], eq, "invalid param")
+
+		_, ok = http.ErrorBody[errBody](errors.New("other"))
+		so(ok, eq, false)
+	})
+}
+
+func TestCharset(t *testing.T) {
+	const text = `<html><head><meta charset="gb2312"></head><body>中文网页内容</body></html>`
+	gbk, _ := simplifiedchinese.GBK.NewEncoder().String(text)
+	binary := []byte{0xD6, 0xD0, 0xCE, 0xC4, 0x00, 0xFF}
+	svr := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		switch r.URL.Path {
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(gbk))
+		case "/binary":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write(binary)
+		}
+	}))
+	defer svr.Close()
+	ctx := context.Background()
+
+	cv("文本自动转换为 UTF-8", t, func() {
+		b, err := http.Raw(ctx, svr.URL+"/html")
+		so(err, isNil)
+		so(string(b), eq, text)
+	})
+
+	cv("二进制内容不转换", t, func() {
+		b, err := http.Raw(ctx, svr.URL+"/binary")
+		so(err, isNil)
+		so(b, resemble, binary)
+	})
+}
+
+func TestQuery(t *testing.T) {
+	svr := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		_, _ = w.Write([]byte(r.URL.RawQuery))
+	}))
+	defer svr.Close()
+	ctx := context.Background()
+
+	type query struct {
+		Keyword string   `url:"q"`
+		Page    int      `url:"page,omitempty"`
+		IDs     []int    `url:"ids,comma"`
+		Tags    []string `url:"tag,omitempty"`
+	}
+
+	cv("结构体 query 参数", t, func() {
+		b, err := http.Raw(ctx, svr.URL+"/?a=1",
+			http.WithQuery(query{Keyword: "go", IDs: []int{1, 2}}),
+			http.WithQuery(url.Values{"b": {"2"}}),
+		)
+		so(err, isNil)
+		so(string(b), eq, "a=1&b=2&ids=1%2C2&q=go")
+
+		_, err = http.Raw(ctx, svr.URL, http.WithQuery(123))
+		so(err, isErr)
+	})
+}
@@ -0,0 +1,386 @@
+// Package cookiejar 实现可持久化的 http.CookieJar, 遵循 RFC 6265 和公共后缀 (public suffix) 规则,
+// 可以用于 net/http 包的请求 (WithCookieJar) 以及 crawler 包的浏览器 (crawler.WithCookieJar)
+package cookiejar
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var errEmptyHost = errors.New("empty host")
+
+// Cookie 表示 jar 中保存的一个 cookie
+type Cookie struct {
+	Name       string        `json:"name"`
+	Value      string        `json:"value"`
+	Domain     string        `json:"domain"` // 不带前导 '.' 的域名
+	Path       string        `json:"path"`
+	Expires    time.Time     `json:"expires,omitempty"` // Persistent 为 false 时无意义
+	Secure     bool          `json:"secure,omitempty"`
+	HttpOnly   bool          `json:"http_only,omitempty"`
+	SameSite   http.SameSite `json:"same_site,omitempty"`
+	HostOnly   bool          `json:"host_only,omitempty"` // 为 true 时只发送给与 Domain 完全一致的 host
+	Persistent bool          `json:"persistent"`          // 为 false 表示会话 cookie
+	Creation   time.Time     `json:"creation"`
+}
+
+func (c *Cookie) id() string {
+	return c.Domain + ";" + c.Path + ";" + c.Name
+}
+
+func (c *Cookie) expired(now time.Time) bool {
+	return c.Persistent && !c.Expires.After(now)
+}
+
+// Standard 转换为标准库的 *http.Cookie
+func (c *Cookie) Standard() *http.Cookie {
+	res := &http.Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+		SameSite: c.SameSite,
+	}
+	if !c.HostOnly {
+		res.Domain = c.Domain
+	}
+	if c.Persistent {
+		res.Expires = c.Expires
+	}
+	return res
+}
+
+// Jar 可持久化的 cookie jar, 实现了 http.CookieJar, 并发安全
+type Jar struct {
+	opts *options
+
+	lock    sync.Mutex
+	entries map[string]map[string]*Cookie // eTLD+1 -> Cookie.id() -> cookie
+}
+
+var _ http.CookieJar = (*Jar)(nil)
+
+// New 新建一个 jar。如果指定了 WithStore, 会从中加载已保存的 cookie
+func New(opts ...Option) (*Jar, error) {
+	j := &Jar{
+		opts:    mergeOptions(opts),
+		entries: map[string]map[string]*Cookie{},
+	}
+	if j.opts.store == nil {
+		return j, nil
+	}
+	cookies, err := j.opts.store.Load()
+	if err != nil {
+		return nil, err
+	}
+	j.add(cookies)
+	return j, nil
+}
+
+// SetCookies 实现 http.CookieJar
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return
+	}
+	host, err := canonicalHost(u.Host)
+	if err != nil {
+		return
+	}
+	key := j.jarKey(host)
+	defPath := defaultPath(u.Path)
+	now := time.Now()
+
+	j.lock.Lock()
+	changed := false
+	for i, c := range cookies {
+		e, remove, ok := j.newEntry(c, now, defPath, host)
+		if !ok {
+			continue
+		}
+		// 同一批次的 cookie 按照先后顺序区分创建时间, 保证 Cookies 返回顺序稳定
+		e.Creation = now.Add(time.Duration(i))
+		submap := j.entries[key]
+		if remove {
+			if _, exist := submap[e.id()]; exist {
+				delete(submap, e.id())
+				changed = true
+			}
+			continue
+		}
+		if submap == nil {
+			submap = map[string]*Cookie{}
+			j.entries[key] = submap
+		}
+		if old, exist := submap[e.id()]; exist {
+			e.Creation = old.Creation
+		}
+		submap[e.id()] = e
+		changed = true
+	}
+	j.lock.Unlock()
+
+	if changed {
+		j.autoSave()
+	}
+}
+
+// Cookies 实现 http.CookieJar, 按照 RFC 6265 的顺序返回需要发送给 u 的 cookie
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil
+	}
+	host, err := canonicalHost(u.Host)
+	if err != nil {
+		return nil
+	}
+	key := j.jarKey(host)
+	https := u.Scheme == "https"
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	now := time.Now()
+
+	j.lock.Lock()
+	var selected []*Cookie
+	for id, e := range j.entries[key] {
+		if e.expired(now) {
+			delete(j.entries[key], id)
+			continue
+		}
+		if (e.Secure && !https) || !e.domainMatch(host) || !e.pathMatch(path) {
+			continue
+		}
+		selected = append(selected, e)
+	}
+	j.lock.Unlock()
+
+	slices.SortStableFunc(selected, func(a, b *Cookie) int {
+		if len(a.Path) != len(b.Path) {
+			return len(b.Path) - len(a.Path)
+		}
+		return a.Creation.Compare(b.Creation)
+	})
+	res := make([]*http.Cookie, 0, len(selected))
+	for _, e := range selected {
+		res = append(res, &http.Cookie{Name: e.Name, Value: e.Value})
+	}
+	return res
+}
+
+// All 返回 jar 中所有未过期的 cookie
+func (j *Jar) All() []Cookie {
+	now := time.Now()
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	var res []Cookie
+	for _, submap := range j.entries {
+		for _, e := range submap {
+			if !e.expired(now) {
+				res = append(res, *e)
+			}
+		}
+	}
+	slices.SortFunc(res, func(a, b Cookie) int {
+		return strings.Compare(a.id(), b.id())
+	})
+	return res
+}
+
+// Add 直接添加 cookie, 不做域名校验, 一般用于导入。过期的 cookie 会删除 jar 中对应的条目
+func (j *Jar) Add(cookies ...Cookie) {
+	j.add(cookies)
+	j.autoSave()
+}
+
+func (j *Jar) add(cookies []Cookie) {
+	now := time.Now()
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	for i, c := range cookies {
+		c.Domain = strings.TrimPrefix(strings.ToLower(c.Domain), ".")
+		if c.Path == "" {
+			c.Path = "/"
+		}
+		if c.Creation.IsZero() {
+			c.Creation = now.Add(time.Duration(i))
+		}
+		key := j.jarKey(c.Domain)
+		if c.expired(now) {
+			delete(j.entries[key], c.id())
+			continue
+		}
+		if j.entries[key] == nil {
+			j.entries[key] = map[string]*Cookie{}
+		}
+		j.entries[key][c.id()] = &c
+	}
+}
+
+// Clear 清空所有 cookie
+func (j *Jar) Clear() {
+	j.lock.Lock()
+	j.entries = map[string]map[string]*Cookie{}
+	j.lock.Unlock()
+	j.autoSave()
+}
+
+// Save 将 cookie 保存到 WithStore 指定的存储中。默认只保存持久化 cookie, 参见 WithSessionCookies。
+// jar 发生变化时会自动调用
+func (j *Jar) Save() error {
+	if j.opts.store == nil {
+		return nil
+	}
+	all := j.All()
+	cookies := make([]Cookie, 0, len(all))
+	for _, c := range all {
+		if c.Persistent || j.opts.keepSession {
+			cookies = append(cookies, c)
+		}
+	}
+	return j.opts.store.Save(cookies)
+}
+
+func (j *Jar) autoSave() {
+	if err := j.Save(); err != nil {
+		j.opts.onError(err)
+	}
+}
+
+// newEntry 按照 RFC 6265 第 5.3 节将 c 转换为 jar 中的条目。remove 表示需要删除,
+// ok 为 false 表示 cookie 不合法, 需要忽略
+func (j *Jar) newEntry(c *http.Cookie, now time.Time, defPath, host string) (e *Cookie, remove, ok bool) {
+	if c == nil || c.Name == "" {
+		return nil, false, false
+	}
+	e = &Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+		SameSite: c.SameSite,
+		Creation: now,
+	}
+	if e.Path == "" || e.Path[0] != '/' {
+		e.Path = defPath
+	}
+
+	var domainOK bool
+	e.Domain, e.HostOnly, domainOK = j.domainAndType(host, c.Domain)
+	if !domainOK {
+		return nil, false, false
+	}
+
+	switch {
+	case c.MaxAge < 0:
+		return e, true, true
+	case c.MaxAge > 0:
+		e.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		e.Persistent = true
+	case !c.Expires.IsZero():
+		if !c.Expires.After(now) {
+			return e, true, true
+		}
+		e.Expires = c.Expires
+		e.Persistent = true
+	}
+	return e, false, true
+}
+
+// domainAndType 确定 cookie 的域名以及是否为 host-only
+func (j *Jar) domainAndType(host, domain string) (string, bool, bool) {
+	if domain == "" {
+		return host, true, true
+	}
+	if isIP(host) {
+		// IP 地址不能设置 Domain 属性, 除非与 host 完全一致
+		return host, true, host == domain
+	}
+
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	if domain == "" || domain[len(domain)-1] == '.' {
+		return "", false, false
+	}
+	// 不允许为公共后缀 (如 com、co.uk、github.io) 设置 cookie, 除非 host 就是该公共后缀
+	if ps := j.opts.psl.PublicSuffix(domain); ps == domain {
+		if host != domain {
+			return "", false, false
+		}
+		return host, true, true
+	}
+	if host != domain && !hasDotSuffix(host, domain) {
+		return "", false, false
+	}
+	return domain, false, true
+}
+
+func (e *Cookie) domainMatch(host string) bool {
+	if e.Domain == host {
+		return true
+	}
+	return !e.HostOnly && hasDotSuffix(host, e.Domain)
+}
+
+func (e *Cookie) pathMatch(path string) bool {
+	if path == e.Path {
+		return true
+	}
+	if strings.HasPrefix(path, e.Path) {
+		return e.Path[len(e.Path)-1] == '/' || path[len(e.Path)] == '/'
+	}
+	return false
+}
+
+// jarKey 返回 host 的 eTLD+1, 用于对 cookie 分组
+func (j *Jar) jarKey(host string) string {
+	if isIP(host) {
+		return host
+	}
+	ps := j.opts.psl.PublicSuffix(host)
+	if ps == host || !hasDotSuffix(host, ps) {
+		return host
+	}
+	prefix := host[:len(host)-len(ps)-1]
+	if i := strings.LastIndexByte(prefix, '.'); i >= 0 {
+		prefix = prefix[i+1:]
+	}
+	return prefix + "." + ps
+}
+
+func canonicalHost(host string) (string, error) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+	if host == "" {
+		return "", errEmptyHost
+	}
+	return strings.ToLower(host), nil
+}
+
+func defaultPath(path string) string {
+	if path == "" || path[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndexByte(path, '/')
+	if i == 0 {
+		return "/"
+	}
+	return path[:i]
+}
+
+func hasDotSuffix(s, suffix string) bool {
+	return len(s) > len(suffix) && s[len(s)-len(suffix)-1] == '.' && s[len(s)-len(suffix):] == suffix
+}
+
+func isIP(host string) bool {
+	return net.ParseIP(host) != nil
+}
@@ -0,0 +1,151 @@
+package cookiejar_test
+
+import (
+	"bytes"
+	"context"
+	nethttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Andrew-M-C/go.util/net/http"
+	"github.com/Andrew-M-C/go.util/net/http/cookiejar"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	cv = convey.Convey
+	so = convey.So
+	eq = convey.ShouldEqual
+
+	isNil = convey.ShouldBeNil
+	isErr = convey.ShouldBeError
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(m.Run())
+}
+
+func mustURL(s string) *url.URL {
+	u, _ := url.Parse(s)
+	return u
+}
+
+func names(cookies []*nethttp.Cookie) string {
+	var res []string
+	for _, c := range cookies {
+		res = append(res, c.Name+"="+c.Value)
+	}
+	return strings.Join(res, "; ")
+}
+
+func TestJar(t *testing.T) {
+	cv("域名与路径规则", t, func() {
+		jar, err := cookiejar.New()
+		so(err, isNil)
+
+		jar.SetCookies(mustURL("https://www.example.com/a/b"), []*nethttp.Cookie{
+			{Name: "host", Value: "1"},
+			{Name: "domain", Value: "2", Domain: ".example.com", Path: "/"},
+			{Name: "secure", Value: "3", Secure: true, Path: "/"},
+			{Name: "path", Value: "4", Path: "/a/b"},
+			{Name: "suffix", Value: "5", Domain: "com"},       // 公共后缀, 拒绝
+			{Name: "other", Value: "6", Domain: "other.com"},  // 非本域, 拒绝
+			{Name: "github", Value: "7", Domain: "github.io"}, // 公共后缀, 拒绝
+			{Name: "expired", Value: "8", MaxAge: -1},         // 删除
+		})
+
+		so(names(jar.Cookies(mustURL("https://www.example.com/a/b/c"))), eq, "path=4; host=1; domain=2; secure=3")
+		so(names(jar.Cookies(mustURL("http://www.example.com/a/b"))), eq, "path=4; host=1; domain=2")
+		so(names(jar.Cookies(mustURL("https://sub.example.com/"))), eq, "domain=2")
+		so(names(jar.Cookies(mustURL("https://www.example.com/a/bc"))), eq, "host=1; domain=2; secure=3")
+		so(len(jar.Cookies(mustURL("https://example.org/"))), eq, 0)
+		so(len(jar.All()), eq, 4)
+
+		// 删除
+		jar.SetCookies(mustURL("https://www.example.com/"), []*nethttp.Cookie{
+			{Name: "domain", Domain: "example.com", MaxAge: -1},
+		})
+		so(len(jar.Cookies(mustURL("https://sub.example.com/"))), eq, 0)
+		so(names(jar.Cookies(mustURL("https://www.example.com/"))), eq, "secure=3")
+	})
+
+	cv("持久化", t, func() {
+		dir := t.TempDir()
+		newJSON := func() cookiejar.Store { return cookiejar.NewJSONFileStore(filepath.Join(dir, "cookies.json")) }
+		newDB := func() cookiejar.Store {
+			s, err := cookiejar.NewSimpleDBStore(filepath.Join(dir, "cookies.csv"))
+			so(err, isNil)
+			return s
+		}
+
+		for name, newStore := range map[string]func() cookiejar.Store{"JSON": newJSON, "simpledb": newDB} {
+			jar, err := cookiejar.New(cookiejar.WithStore(newStore()))
+			so(err, isNil)
+			u := mustURL("https://example.com/")
+			jar.SetCookies(u, []*nethttp.Cookie{
+				{Name: "a", Value: "1", MaxAge: 3600, HttpOnly: true},
+				{Name: "b", Value: "2", Expires: time.Now().Add(time.Hour)},
+				{Name: "session", Value: "3"},
+			})
+			jar.SetCookies(u, []*nethttp.Cookie{{Name: "b", MaxAge: -1}})
+
+			jar, err = cookiejar.New(cookiejar.WithStore(newStore()))
+			so(err, isNil)
+			so(names(jar.Cookies(u)), eq, "a=1")
+			all := jar.All()
+			so(len(all), eq, 1)
+			so(all[0].HttpOnly, eq, true)
+			so(all[0].Expires.After(time.Now().Add(59*time.Minute)), eq, true)
+			t.Logf("%s store OK", name)
+		}
+
+		_, err := cookiejar.New(cookiejar.WithStore(cookiejar.NewJSONFileStore(dir)))
+		so(err, isErr)
+	})
+
+	cv("Netscape cookies.txt", t, func() {
+		txt := "# Netscape HTTP Cookie File\n" +
+			".example.com\tTRUE\t/\tFALSE\t4102444800\tsid\tabc\n" +
+			"#HttpOnly_www.example.com\tFALSE\t/\tTRUE\t0\ttoken\txyz\n" +
+			"old.example.com\tFALSE\t/\tFALSE\t1\told\tgone\n"
+		jar, err := cookiejar.New()
+		so(err, isNil)
+		so(jar.ImportNetscape(strings.NewReader(txt)), isNil)
+		so(names(jar.Cookies(mustURL("https://www.example.com/"))), convey.ShouldContainSubstring, "token=xyz")
+		so(names(jar.Cookies(mustURL("http://a.example.com/"))), eq, "sid=abc")
+
+		buff := bytes.Buffer{}
+		so(jar.ExportNetscape(&buff), isNil)
+		so(buff.String(), convey.ShouldContainSubstring, ".example.com\tTRUE\t/\tFALSE\t4102444800\tsid\tabc\n")
+		so(buff.String(), convey.ShouldContainSubstring, "#HttpOnly_www.example.com\tFALSE\t/\tTRUE\t0\ttoken\txyz\n")
+		so(buff.String(), convey.ShouldNotContainSubstring, "old")
+
+		so(jar.ImportNetscape(strings.NewReader("bad line\n")), isErr)
+	})
+
+	cv("与 net/http 配合", t, func() {
+		svr := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			if c, err := r.Cookie("visit"); err == nil {
+				_, _ = w.Write([]byte(c.Value))
+				return
+			}
+			nethttp.SetCookie(w, &nethttp.Cookie{Name: "visit", Value: "again", MaxAge: 60})
+			_, _ = w.Write([]byte("first"))
+		}))
+		defer svr.Close()
+
+		jar, err := cookiejar.New()
+		so(err, isNil)
+		b, err := http.Raw(context.Background(), svr.URL, http.WithCookieJar(jar))
+		so(err, isNil)
+		so(string(b), eq, "first")
+		b, err = http.Raw(context.Background(), svr.URL, http.WithCookieJar(jar))
+		so(err, isNil)
+		so(string(b), eq, "again")
+	})
+}
@@ -0,0 +1,81 @@
+package cookiejar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const httpOnlyPrefix = "#HttpOnly_"
+
+// ImportNetscape 从 Netscape cookies.txt 格式 (curl、wget 以及浏览器插件使用) 导入 cookie
+func (j *Jar) ImportNetscape(r io.Reader) error {
+	var cookies []Cookie
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		httpOnly := false
+		if strings.HasPrefix(line, httpOnlyPrefix) {
+			line = strings.TrimPrefix(line, httpOnlyPrefix)
+			httpOnly = true
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < 7 {
+			return fmt.Errorf("invalid cookies.txt line %d, expect 7 fields but got %d", n, len(fields))
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid expires in cookies.txt line %d (%w)", n, err)
+		}
+		c := Cookie{
+			Domain:   fields[0],
+			HostOnly: !strings.EqualFold(fields[1], "TRUE"),
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Name:     fields[5],
+			Value:    strings.Join(fields[6:], "\t"),
+			HttpOnly: httpOnly,
+		}
+		if expires > 0 {
+			c.Expires = time.Unix(expires, 0)
+			c.Persistent = true
+		}
+		cookies = append(cookies, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read cookies.txt error (%w)", err)
+	}
+	j.Add(cookies...)
+	return nil
+}
+
+// ExportNetscape 以 Netscape cookies.txt 格式导出所有未过期的 cookie, 会话 cookie 的过期时间为 0
+func (j *Jar) ExportNetscape(w io.Writer) error {
+	buff := bufio.NewWriter(w)
+	buff.WriteString("# Netscape HTTP Cookie File\n\n")
+	for _, c := range j.All() {
+		if c.HttpOnly {
+			buff.WriteString(httpOnlyPrefix)
+		}
+		domain, includeSub := c.Domain, "FALSE"
+		if !c.HostOnly {
+			domain, includeSub = "."+c.Domain, "TRUE"
+		}
+		expires := int64(0)
+		if c.Persistent {
+			expires = c.Expires.Unix()
+		}
+		fmt.Fprintf(buff, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain, includeSub, c.Path, strings.ToUpper(strconv.FormatBool(c.Secure)), expires, c.Name, c.Value,
+		)
+	}
+	return buff.Flush()
+}
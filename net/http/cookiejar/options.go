@@ -0,0 +1,64 @@
+package cookiejar
+
+import "golang.org/x/net/publicsuffix"
+
+// PublicSuffixList 公共后缀列表, 与标准库 net/http/cookiejar.PublicSuffixList 一致
+type PublicSuffixList interface {
+	PublicSuffix(domain string) string
+	String() string
+}
+
+// Option 表示 New 的选项
+type Option func(*options)
+
+type options struct {
+	psl         PublicSuffixList
+	store       Store
+	keepSession bool
+	onError     func(error)
+}
+
+func mergeOptions(opts []Option) *options {
+	o := &options{
+		psl:     publicsuffix.List,
+		onError: func(error) {},
+	}
+	for _, f := range opts {
+		if f != nil {
+			f(o)
+		}
+	}
+	return o
+}
+
+// WithPublicSuffixList 指定公共后缀列表, 默认使用 golang.org/x/net/publicsuffix.List
+func WithPublicSuffixList(l PublicSuffixList) Option {
+	return func(o *options) {
+		if l != nil {
+			o.psl = l
+		}
+	}
+}
+
+// WithStore 指定持久化存储, 参见 NewJSONFileStore、NewSimpleDBStore
+func WithStore(s Store) Option {
+	return func(o *options) {
+		o.store = s
+	}
+}
+
+// WithSessionCookies 同时持久化会话 cookie (没有过期时间的 cookie)。默认只持久化有过期时间的 cookie
+func WithSessionCookies() Option {
+	return func(o *options) {
+		o.keepSession = true
+	}
+}
+
+// WithSaveErrorCallback 指定自动保存失败时的回调
+func WithSaveErrorCallback(f func(error)) Option {
+	return func(o *options) {
+		if f != nil {
+			o.onError = f
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package cookiejar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Andrew-M-C/go.util/csv/simpledb"
+)
+
+// Store cookie 的持久化存储
+type Store interface {
+	Load() ([]Cookie, error)
+	Save([]Cookie) error // 保存全部 cookie, 不在参数中的 cookie 需要删除
+}
+
+// -------- JSON 文件 --------
+
+type jsonFileStore struct {
+	path string
+}
+
+// NewJSONFileStore 以 JSON 格式保存在 path 文件中, 文件不存在时视为空
+func NewJSONFileStore(path string) Store {
+	return jsonFileStore{path: path}
+}
+
+func (s jsonFileStore) Load() ([]Cookie, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read cookie file error (%w)", err)
+	}
+	var cookies []Cookie
+	if err := json.Unmarshal(b, &cookies); err != nil {
+		return nil, fmt.Errorf("unmarshal cookie file error (%w)", err)
+	}
+	return cookies, nil
+}
+
+func (s jsonFileStore) Save(cookies []Cookie) error {
+	b, err := json.MarshalIndent(cookies, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	// 先写临时文件再重命名, 避免写入中断导致文件损坏
+	tmp := s.path + "." + strconv.FormatInt(time.Now().UnixNano(), 36) + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// -------- csv/simpledb --------
+
+const (
+	colName     = "name"
+	colValue    = "value"
+	colDomain   = "domain"
+	colPath     = "path"
+	colExpires  = "expires"
+	colSecure   = "secure"
+	colHttpOnly = "http_only"
+	colSameSite = "same_site"
+	colHostOnly = "host_only"
+	colCreation = "creation"
+	colDeleted  = "deleted"
+)
+
+type simpleDBStore struct {
+	lock sync.Mutex
+	db   *simpledb.DB[string, string, string]
+	keys map[string]struct{}
+}
+
+// NewSimpleDBStore 使用 csv/simpledb 保存在 path 文件中, 每个 cookie 一行。由于 simpledb 不支持删除,
+// 被删除的 cookie 会标记 deleted 列
+func NewSimpleDBStore(path string, opts ...simpledb.Option) (Store, error) {
+	db, err := simpledb.NewDB[string, string, string](path, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("open simpledb error (%w)", err)
+	}
+	return &simpleDBStore{db: db, keys: map[string]struct{}{}}, nil
+}
+
+func (s *simpleDBStore) Load() ([]Cookie, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var res []Cookie
+	for key, row := range s.db.LoadWithColumn(colDeleted, "0") {
+		c := Cookie{
+			Name:       row[colName],
+			Value:      row[colValue],
+			Domain:     row[colDomain],
+			Path:       row[colPath],
+			Secure:     row[colSecure] == "1",
+			HttpOnly:   row[colHttpOnly] == "1",
+			HostOnly:   row[colHostOnly] == "1",
+			Persistent: row[colExpires] != "",
+		}
+		if c.Persistent {
+			sec, _ := strconv.ParseInt(row[colExpires], 10, 64)
+			c.Expires = time.Unix(sec, 0)
+		}
+		sameSite, _ := strconv.Atoi(row[colSameSite])
+		c.SameSite = http.SameSite(sameSite)
+		if nano, err := strconv.ParseInt(row[colCreation], 10, 64); err == nil {
+			c.Creation = time.Unix(0, nano)
+		}
+		s.keys[key] = struct{}{}
+		res = append(res, c)
+	}
+	return res, nil
+}
+
+func (s *simpleDBStore) Save(cookies []Cookie) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	keys := make(map[string]struct{}, len(cookies))
+	for _, c := range cookies {
+		key := c.id()
+		keys[key] = struct{}{}
+		row := map[string]string{
+			colName:     c.Name,
+			colValue:    c.Value,
+			colDomain:   c.Domain,
+			colPath:     c.Path,
+			colExpires:  "",
+			colSecure:   boolColumn(c.Secure),
+			colHttpOnly: boolColumn(c.HttpOnly),
+			colSameSite: strconv.Itoa(int(c.SameSite)),
+			colHostOnly: boolColumn(c.HostOnly),
+			colCreation: strconv.FormatInt(c.Creation.UnixNano(), 10),
+			colDeleted:  "0",
+		}
+		if c.Persistent {
+			row[colExpires] = strconv.FormatInt(c.Expires.Unix(), 10)
+		}
+		if err := s.db.Store(key, row); err != nil {
+			return err
+		}
+	}
+	for key := range s.keys {
+		if _, exist := keys[key]; exist {
+			continue
+		}
+		if err := s.db.StoreColumns(key, map[string]string{colDeleted: "1"}); err != nil {
+			return err
+		}
+	}
+	s.keys = keys
+	return nil
+}
+
+func boolColumn(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,463 @@
+package http
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DownloadProgress 表示下载到文件的进度
+type DownloadProgress struct {
+	Total      int64           // 文件总大小, -1 表示未知
+	Downloaded int64           // 已下载大小, 包含之前下载过的部分
+	Chunks     []ChunkProgress // 各个分片的进度
+}
+
+// ChunkProgress 表示一个分片的下载进度
+type ChunkProgress struct {
+	Start      int64 // 分片起始位置
+	End        int64 // 分片结束位置 (包含), -1 表示未知
+	Downloaded int64 // 分片已下载大小
+}
+
+// WithDownloadChunks 指定 DownloadToFile 的并发分片数。仅当服务端支持 Range 请求时生效, 每个分片不小于 64KiB
+func WithDownloadChunks(n int) RequestOption {
+	return func(ro *requestOption) {
+		ro.downloadChunks = n
+	}
+}
+
+// WithDownloadChecksum 指定 DownloadToFile 下载完成后校验文件的哈希值, 如 WithDownloadChecksum(sha256.New, "...")。
+// 哈希值为十六进制字符串, 不区分大小写。校验失败时删除已下载的内容并返回错误
+func WithDownloadChecksum(newHash func() hash.Hash, sum string) RequestOption {
+	return func(ro *requestOption) {
+		ro.downloadHash = newHash
+		ro.downloadSum = strings.ToLower(sum)
+	}
+}
+
+// WithDownloadProgress 指定 DownloadToFile 的进度回调, 回调是串行调用的
+func WithDownloadProgress(cb func(DownloadProgress)) RequestOption {
+	return func(ro *requestOption) {
+		ro.downloadProgressCB = cb
+	}
+}
+
+// ErrRemoteFileChanged 表示续传过程中远程文件发生了变化
+var ErrRemoteFileChanged = errors.New("remote file changed during download")
+
+const minDownloadChunkSize = 64 << 10
+
+// downloadStateInterval 下载过程中保存进度的最小间隔, 进程异常退出时最多损失这段时间的进度
+var downloadStateInterval = time.Second
+
+// DownloadToFile 将文件以流的方式下载到本地 filePath, 不会将文件内容读到内存中。
+//
+// 下载过程中, 内容写在 filePath + ".download" 临时文件中, 进度记录在 filePath + ".download.json" 中,
+// 完成后重命名为 filePath。如果服务端支持 Range 请求并且提供了 ETag 或 Last-Modified, 再次调用时会使用
+// Range/If-Range 从上次中断的位置继续下载; 通过 WithDownloadChunks 还可以并发下载多个分片。
+func DownloadToFile(ctx context.Context, targetURL, filePath string, opts ...RequestOption) error {
+	return defaultClient.DownloadToFile(ctx, targetURL, filePath, opts...)
+}
+
+// DownloadToFile 将文件以流的方式下载到本地 filePath, 参见包函数 DownloadToFile
+func (c *Client) DownloadToFile(ctx context.Context, targetURL, filePath string, opts ...RequestOption) error {
+	d := &fileDownloader{
+		c:         c,
+		ctx:       ctx,
+		targetURL: targetURL,
+		opts:      opts,
+		o:         c.mergeOptions(opts, nil),
+		tmpPath:   filePath + ".download",
+		statePath: filePath + ".download.json",
+	}
+	if err := d.download(); err != nil {
+		return err
+	}
+	if err := d.verify(); err != nil {
+		return err
+	}
+	if err := os.Rename(d.tmpPath, filePath); err != nil {
+		return fmt.Errorf("rename downloaded file error (%w)", err)
+	}
+	_ = os.Remove(d.statePath)
+	return nil
+}
+
+type downloadState struct {
+	URL       string           `json:"url"`
+	Total     int64            `json:"total"`
+	Validator string           `json:"validator"`
+	Chunks    []*downloadChunk `json:"chunks"`
+}
+
+type downloadChunk struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+	Done  int64 `json:"done"`
+}
+
+func (ch *downloadChunk) remaining() bool {
+	return ch.End < 0 || ch.Start+atomic.LoadInt64(&ch.Done) <= ch.End
+}
+
+type fileDownloader struct {
+	c         *Client
+	ctx       context.Context
+	targetURL string
+	opts      []RequestOption
+	o         *requestOption
+
+	tmpPath   string
+	statePath string
+
+	state      *downloadState
+	reportLock sync.Mutex
+
+	stateLock   sync.Mutex
+	stateSaveAt time.Time
+}
+
+func (d *fileDownloader) download() error {
+	// 先请求第一个字节, 判断服务端是否支持 Range 请求
+	rsp, err := d.request(d.ctx, "bytes=0-0", "")
+	if err != nil {
+		if e, ok := UnwrapError(err); ok && e.Detail().StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			// 连第一个字节都不存在, 说明远程文件为空
+			d.o.debugf("remote file is empty")
+			return d.createEmpty()
+		}
+		return err
+	}
+	total, validator, ranged := parseRangeProbe(rsp)
+	if !ranged {
+		d.o.debugf("server does not support range request, status %v", rsp.Status)
+		if rsp.StatusCode != http.StatusOK {
+			// 支持 Range 但是无法获得文件大小, 重新发起完整的请求
+			rsp.Body.Close()
+			if rsp, err = d.request(d.ctx, "", ""); err != nil {
+				return err
+			}
+		}
+		defer rsp.Body.Close()
+		return d.downloadWhole(rsp)
+	}
+	rsp.Body.Close()
+	d.o.debugf("file size %d, validator '%s'", total, validator)
+
+	f, err := d.prepareChunks(total, validator)
+	if err != nil {
+		return err
+	}
+	if d.state.Validator != "" {
+		d.saveState()
+	}
+	err = d.downloadChunks(f)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("close file error (%w)", closeErr)
+	}
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ErrRemoteFileChanged) {
+		d.removeAll()
+		return err
+	}
+	if d.state.Validator != "" {
+		d.saveState()
+	}
+	return err
+}
+
+func (d *fileDownloader) request(ctx context.Context, rangeHeader, ifRange string) (*http.Response, error) {
+	h := http.Header{}
+	if rangeHeader != "" {
+		h.Set("Range", rangeHeader)
+	}
+	if ifRange != "" {
+		h.Set("If-Range", ifRange)
+	}
+	opts := append(slices.Clone(d.opts), WithRequestHeader(h))
+	o := d.c.mergeOptions(opts, nil)
+	rsp, err := raw(ctx, d.targetURL, o)
+	if err != nil {
+		if rsp != nil {
+			defer rsp.Body.Close()
+			b, _ := io.ReadAll(io.LimitReader(rsp.Body, maxErrorBodySize))
+			return nil, packError(rsp, b, err)
+		}
+		return nil, err
+	}
+	return rsp, nil
+}
+
+// parseRangeProbe 解析 Range 探测请求的响应, 返回文件大小和用于 If-Range 的校验值
+func parseRangeProbe(rsp *http.Response) (total int64, validator string, ranged bool) {
+	if rsp.StatusCode != http.StatusPartialContent {
+		return rsp.ContentLength, "", false
+	}
+	// Content-Range: bytes 0-0/12345
+	cr := rsp.Header.Get("Content-Range")
+	_, size, ok := strings.Cut(cr, "/")
+	if !ok {
+		return -1, "", false
+	}
+	total, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || total <= 0 {
+		return -1, "", false
+	}
+	validator = rsp.Header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = rsp.Header.Get("Last-Modified")
+	}
+	return total, validator, true
+}
+
+// downloadWhole 服务端不支持 Range 时, 直接从头下载整个文件
+func (d *fileDownloader) downloadWhole(rsp *http.Response) error {
+	f, err := os.Create(d.tmpPath)
+	if err != nil {
+		return fmt.Errorf("create file error (%w)", err)
+	}
+	defer f.Close()
+	_ = os.Remove(d.statePath)
+
+	total := rsp.ContentLength
+	d.state = &downloadState{
+		URL:    d.targetURL,
+		Total:  total,
+		Chunks: []*downloadChunk{{Start: 0, End: total - 1}},
+	}
+	if total < 0 {
+		d.state.Chunks[0].End = -1
+	}
+	w := &chunkWriter{f: f, chunk: d.state.Chunks[0], d: d}
+	if _, err := io.Copy(w, rsp.Body); err != nil {
+		return fmt.Errorf("read body error (%w)", err)
+	}
+	return nil
+}
+
+// prepareChunks 读取上次的下载进度, 如果无法续传则重新划分分片
+func (d *fileDownloader) prepareChunks(total int64, validator string) (*os.File, error) {
+	if st := d.loadState(); st != nil && validator != "" &&
+		st.URL == d.targetURL && st.Total == total && st.Validator == validator {
+		if f, err := os.OpenFile(d.tmpPath, os.O_RDWR, 0); err == nil {
+			if info, err := f.Stat(); err == nil && info.Size() == total {
+				d.o.debugf("resume download from %s", d.statePath)
+				d.state = st
+				return f, nil
+			}
+			f.Close()
+		}
+	}
+
+	f, err := os.Create(d.tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("create file error (%w)", err)
+	}
+	if err := f.Truncate(total); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("truncate file error (%w)", err)
+	}
+
+	n := int64(max(d.o.downloadChunks, 1))
+	n = max(min(n, total/minDownloadChunkSize), 1)
+	size := (total + n - 1) / n
+	d.state = &downloadState{URL: d.targetURL, Total: total, Validator: validator}
+	for start := int64(0); start < total; start += size {
+		d.state.Chunks = append(d.state.Chunks, &downloadChunk{
+			Start: start,
+			End:   min(start+size, total) - 1,
+		})
+	}
+	d.o.debugf("download in %d chunks", len(d.state.Chunks))
+	return f, nil
+}
+
+func (d *fileDownloader) downloadChunks(f *os.File) error {
+	ctx, cancel := context.WithCancel(d.ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	for _, ch := range d.state.Chunks {
+		if !ch.remaining() {
+			continue
+		}
+		wg.Add(1)
+		go func(ch *downloadChunk) {
+			defer wg.Done()
+			if err := d.downloadChunk(ctx, f, ch); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			if d.state.Validator != "" {
+				d.saveState()
+			}
+		}(ch)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+func (d *fileDownloader) downloadChunk(ctx context.Context, f *os.File, ch *downloadChunk) error {
+	from := ch.Start + atomic.LoadInt64(&ch.Done)
+	rangeHeader := fmt.Sprintf("bytes=%d-%d", from, ch.End)
+	d.o.debugf("download chunk %s", rangeHeader)
+
+	rsp, err := d.request(ctx, rangeHeader, d.state.Validator)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusPartialContent {
+		return ErrRemoteFileChanged
+	}
+
+	w := &chunkWriter{f: f, chunk: ch, d: d}
+	if _, err := io.Copy(w, io.LimitReader(rsp.Body, ch.End-from+1)); err != nil {
+		return fmt.Errorf("read chunk %s error (%w)", rangeHeader, err)
+	}
+	if ch.remaining() {
+		return fmt.Errorf("read chunk %s error (%w)", rangeHeader, io.ErrUnexpectedEOF)
+	}
+	return nil
+}
+
+func (d *fileDownloader) loadState() *downloadState {
+	b, err := os.ReadFile(d.statePath)
+	if err != nil {
+		return nil
+	}
+	st := &downloadState{}
+	if err := json.Unmarshal(b, st); err != nil {
+		d.o.debugf("invalid download state file: %v", err)
+		return nil
+	}
+	return st
+}
+
+// saveState 保存下载进度。分片的进度在下载过程中并发更新, 因此先复制一份
+func (d *fileDownloader) saveState() {
+	d.stateLock.Lock()
+	defer d.stateLock.Unlock()
+
+	st := *d.state
+	st.Chunks = make([]*downloadChunk, 0, len(d.state.Chunks))
+	for _, ch := range d.state.Chunks {
+		st.Chunks = append(st.Chunks, &downloadChunk{
+			Start: ch.Start,
+			End:   ch.End,
+			Done:  atomic.LoadInt64(&ch.Done),
+		})
+	}
+	b, _ := json.Marshal(&st)
+	if err := os.WriteFile(d.statePath, b, 0o644); err != nil {
+		d.o.debugf("save download state error: %v", err)
+	}
+	d.stateSaveAt = time.Now()
+}
+
+// saveStatePeriodically 距离上次保存超过 downloadStateInterval 时保存下载进度
+func (d *fileDownloader) saveStatePeriodically() {
+	if d.state.Validator == "" {
+		return
+	}
+	d.stateLock.Lock()
+	due := time.Since(d.stateSaveAt) >= downloadStateInterval
+	d.stateLock.Unlock()
+	if due {
+		d.saveState()
+	}
+}
+
+// createEmpty 远程文件为空时直接创建空文件
+func (d *fileDownloader) createEmpty() error {
+	f, err := os.Create(d.tmpPath)
+	if err != nil {
+		return fmt.Errorf("create file error (%w)", err)
+	}
+	_ = os.Remove(d.statePath)
+	d.state = &downloadState{URL: d.targetURL}
+	d.report()
+	return f.Close()
+}
+
+func (d *fileDownloader) removeAll() {
+	_ = os.Remove(d.tmpPath)
+	_ = os.Remove(d.statePath)
+}
+
+func (d *fileDownloader) report() {
+	cb := d.o.downloadProgressCB
+	if cb == nil {
+		return
+	}
+	d.reportLock.Lock()
+	defer d.reportLock.Unlock()
+
+	p := DownloadProgress{
+		Total:  d.state.Total,
+		Chunks: make([]ChunkProgress, 0, len(d.state.Chunks)),
+	}
+	for _, ch := range d.state.Chunks {
+		done := atomic.LoadInt64(&ch.Done)
+		p.Downloaded += done
+		p.Chunks = append(p.Chunks, ChunkProgress{Start: ch.Start, End: ch.End, Downloaded: done})
+	}
+	cb(p)
+}
+
+func (d *fileDownloader) verify() error {
+	if d.o.downloadHash == nil {
+		return nil
+	}
+	f, err := os.Open(d.tmpPath)
+	if err != nil {
+		return fmt.Errorf("open file error (%w)", err)
+	}
+	defer f.Close()
+
+	h := d.o.downloadHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("read file error (%w)", err)
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != d.o.downloadSum {
+		_ = f.Close()
+		d.removeAll()
+		return fmt.Errorf("checksum mismatch, expected %s, got %s", d.o.downloadSum, sum)
+	}
+	return nil
+}
+
+// chunkWriter 将分片数据写到文件的对应位置
+type chunkWriter struct {
+	f     *os.File
+	chunk *downloadChunk
+	d     *fileDownloader
+}
+
+func (w *chunkWriter) Write(b []byte) (int, error) {
+	n, err := w.f.WriteAt(b, w.chunk.Start+atomic.LoadInt64(&w.chunk.Done))
+	atomic.AddInt64(&w.chunk.Done, int64(n))
+	w.d.report()
+	w.d.saveStatePeriodically()
+	return n, err
+}
@@ -0,0 +1,205 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand/v2"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Andrew-M-C/go.util/net/http"
+)
+
+// abortWriter 写入超过 limit 字节之后中断连接
+type abortWriter struct {
+	nethttp.ResponseWriter
+	limit int
+}
+
+func (w *abortWriter) Write(b []byte) (int, error) {
+	if len(b) > w.limit {
+		n, _ := w.ResponseWriter.Write(b[:w.limit])
+		w.ResponseWriter.(nethttp.Flusher).Flush()
+		w.limit -= n
+		panic(nethttp.ErrAbortHandler)
+	}
+	w.limit -= len(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func TestDownloadToFile(t *testing.T) {
+	content := make([]byte, 1<<20)
+	for i := range content {
+		content[i] = byte(rand.IntN(256))
+	}
+	sha := sha256.Sum256(content)
+	sum := hex.EncodeToString(sha[:])
+	modTime := time.Now()
+
+	var lock sync.Mutex
+	var ranges []string
+	var abortNext int32
+	release := make(chan struct{})
+	svr := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		lock.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		lock.Unlock()
+
+		switch r.URL.Path {
+		case "/no-range":
+			_, _ = w.Write(content)
+			return
+		case "/empty":
+			// 与 nginx、S3 等相同, 空文件的 Range 请求返回 416
+			if r.Header.Get("Range") != "" {
+				w.Header().Set("Content-Range", "bytes */0")
+				w.WriteHeader(nethttp.StatusRequestedRangeNotSatisfiable)
+			}
+			return
+		case "/slow":
+			// 第二个分片等待测试检查进度文件之后再返回
+			if rg := r.Header.Get("Range"); rg != "bytes=0-0" && !strings.HasPrefix(rg, "bytes=0-") {
+				<-release
+			}
+		}
+		if r.Header.Get("Range") != "bytes=0-0" && atomic.CompareAndSwapInt32(&abortNext, 1, 0) {
+			w = &abortWriter{ResponseWriter: w, limit: 100 << 10}
+		}
+		w.Header().Set("ETag", `"v1"`)
+		nethttp.ServeContent(w, r, "file.bin", modTime, bytes.NewReader(content))
+	}))
+	defer svr.Close()
+
+	dir := t.TempDir()
+	ctx := context.Background()
+	resetRanges := func() {
+		lock.Lock()
+		defer lock.Unlock()
+		ranges = nil
+	}
+
+	cv("并发分片下载并校验", t, func() {
+		resetRanges()
+		target := filepath.Join(dir, "parallel.bin")
+		var last http.DownloadProgress
+		err := http.DownloadToFile(ctx, svr.URL+"/file.bin", target,
+			http.WithDownloadChunks(4),
+			http.WithDownloadChecksum(sha256.New, strings.ToUpper(sum)),
+			http.WithDownloadProgress(func(p http.DownloadProgress) { last = p }),
+		)
+		so(err, isNil)
+
+		b, err := os.ReadFile(target)
+		so(err, isNil)
+		so(bytes.Equal(b, content), eq, true)
+
+		so(len(ranges), eq, 5)
+		so(last.Total, eq, len(content))
+		so(last.Downloaded, eq, len(content))
+		so(len(last.Chunks), eq, 4)
+		so(last.Chunks[3].End, eq, len(content)-1)
+
+		_, err = os.Stat(target + ".download.json")
+		so(os.IsNotExist(err), eq, true)
+	})
+
+	cv("断点续传", t, func() {
+		resetRanges()
+		target := filepath.Join(dir, "resume.bin")
+		atomic.StoreInt32(&abortNext, 1)
+		err := http.DownloadToFile(ctx, svr.URL+"/file.bin", target)
+		so(err, isErr)
+
+		_, err = os.Stat(target + ".download.json")
+		so(err, isNil)
+
+		resetRanges()
+		err = http.DownloadToFile(ctx, svr.URL+"/file.bin", target, http.WithDebugger(t.Logf))
+		so(err, isNil)
+		so(len(ranges), eq, 2)
+		so(ranges[1], eq, "bytes=102400-1048575")
+
+		b, err := os.ReadFile(target)
+		so(err, isNil)
+		so(bytes.Equal(b, content), eq, true)
+	})
+
+	cv("下载过程中保存进度", t, func() {
+		target := filepath.Join(dir, "slow.bin")
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- http.DownloadToFile(ctx, svr.URL+"/slow", target, http.WithDownloadChunks(2))
+		}()
+
+		// 第一个分片完成之后, 进度文件中应记录其已下载
+		var state struct {
+			Chunks []struct {
+				Start int64 `json:"start"`
+				End   int64 `json:"end"`
+				Done  int64 `json:"done"`
+			} `json:"chunks"`
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			b, err := os.ReadFile(target + ".download.json")
+			if err == nil && json.Unmarshal(b, &state) == nil && len(state.Chunks) == 2 &&
+				state.Chunks[0].Done == state.Chunks[0].End+1 {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		close(release)
+		so(len(state.Chunks), eq, 2)
+		so(state.Chunks[0].Done, eq, len(content)/2)
+		so(state.Chunks[1].Done, eq, 0)
+
+		so(<-errCh, isNil)
+		b, err := os.ReadFile(target)
+		so(err, isNil)
+		so(bytes.Equal(b, content), eq, true)
+	})
+
+	cv("远程文件为空", t, func() {
+		target := filepath.Join(dir, "empty.bin")
+		err := http.DownloadToFile(ctx, svr.URL+"/empty", target, http.WithDebugger(t.Logf))
+		so(err, isNil)
+
+		info, err := os.Stat(target)
+		so(err, isNil)
+		so(info.Size(), eq, 0)
+	})
+
+	cv("服务端不支持 Range", t, func() {
+		target := filepath.Join(dir, "no-range.bin")
+		err := http.DownloadToFile(ctx, svr.URL+"/no-range", target, http.WithDownloadChunks(4))
+		so(err, isNil)
+
+		b, err := os.ReadFile(target)
+		so(err, isNil)
+		so(bytes.Equal(b, content), eq, true)
+	})
+
+	cv("校验失败", t, func() {
+		target := filepath.Join(dir, "bad-sum.bin")
+		err := http.DownloadToFile(ctx, svr.URL+"/file.bin", target,
+			http.WithDownloadChecksum(md5.New, "00000000000000000000000000000000"),
+		)
+		so(err, isErr)
+
+		_, err = os.Stat(target)
+		so(os.IsNotExist(err), eq, true)
+		_, err = os.Stat(target + ".download")
+		so(os.IsNotExist(err), eq, true)
+	})
+}
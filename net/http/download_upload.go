@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"mime"
+	"net/http"
 	"net/url"
 	"path"
 	"strconv"
@@ -15,7 +16,14 @@ import (
 func DownloadFile(
 	ctx context.Context, targetURL string, opts ...RequestOption,
 ) (fileName string, content []byte, err error) {
-	o := mergeOptions(opts, nil)
+	return defaultClient.DownloadFile(ctx, targetURL, opts...)
+}
+
+// DownloadFile 下载文件
+func (c *Client) DownloadFile(
+	ctx context.Context, targetURL string, opts ...RequestOption,
+) (fileName string, content []byte, err error) {
+	o := c.mergeOptions(opts, nil)
 	httpRsp, err := raw(ctx, targetURL, o)
 	if err != nil {
 		return "", nil, err
@@ -51,3 +59,21 @@ func DownloadFile(
 
 	return fileName, content, nil
 }
+
+// UploadFile 以 multipart/form-data 格式上传磁盘上的文件, 返回响应 body。默认使用 POST 方法,
+// 可以通过 WithMultipartBody 追加其他表单字段, 通过 WithProgressCallback 获取上传进度
+func UploadFile(
+	ctx context.Context, targetURL, fieldName, filePath string, opts ...RequestOption,
+) ([]byte, error) {
+	return defaultClient.UploadFile(ctx, targetURL, fieldName, filePath, opts...)
+}
+
+// UploadFile 以 multipart/form-data 格式上传磁盘上的文件, 返回响应 body
+func (c *Client) UploadFile(
+	ctx context.Context, targetURL, fieldName, filePath string, opts ...RequestOption,
+) ([]byte, error) {
+	all := make([]RequestOption, 0, len(opts)+2)
+	all = append(all, WithMethod(http.MethodPost), WithMultipartBody(MultipartFile(fieldName, filePath)))
+	all = append(all, opts...)
+	return c.Raw(ctx, targetURL, all...)
+}
@@ -1,10 +1,15 @@
 package http
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 )
 
+// ErrEmptyBody 表示服务端返回了空的响应体
+var ErrEmptyBody = errors.New("empty body from remote server")
+
 // Error 表示本 http 工具返回的错误
 type Error struct {
 	wrapped error
@@ -72,3 +77,28 @@ func UnwrapError(err error) (Error, bool) {
 
 	return Error{}, false
 }
+
+// DecodeJSON 将错误响应体按照 JSON 格式反序列化到 v 中, v 应为指针
+func (d ErrorDetail) DecodeJSON(v any) error {
+	if len(d.Body) == 0 {
+		return ErrEmptyBody
+	}
+	if err := json.Unmarshal(d.Body, v); err != nil {
+		return fmt.Errorf("json.Unmarshal error (%w)", err)
+	}
+	return nil
+}
+
+// ErrorBody 尝试从 err 中取出服务端返回的错误响应, 并按照 JSON 格式反序列化为 T 类型。
+// err 不是 Error 类型或者反序列化失败时返回 false
+func ErrorBody[T any](err error) (*T, bool) {
+	e, ok := UnwrapError(err)
+	if !ok {
+		return nil, false
+	}
+	v := new(T)
+	if err := e.Detail().DecodeJSON(v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
@@ -22,6 +22,8 @@ var (
 	eq = convey.ShouldEqual
 	gt = convey.ShouldBeGreaterThan
 
+	resemble = convey.ShouldResemble
+
 	isNil = convey.ShouldBeNil
 	isErr = convey.ShouldBeError
 )
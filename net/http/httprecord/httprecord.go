@@ -0,0 +1,311 @@
+// Package httprecord 实现 HTTP 请求的录制与回放, 用于编写不依赖网络的单元测试。
+//
+// Recorder 实现了 http.RoundTripper, 可以通过 http.WithTransport 或者 http.Client.Transport 使用。
+// 录制的请求和响应保存在 cassette 文件中, 文件扩展名为 .yaml 或 .yml 时使用 YAML 格式, 否则使用 JSON 格式。
+package httprecord
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Mode 表示录制模式
+type Mode int
+
+const (
+	// ModeReplay 只回放。找不到匹配的录制时, 严格模式下返回错误, 否则透传给真实的 transport (不录制)
+	ModeReplay Mode = iota
+	// ModeRecord 总是请求真实服务, 并覆盖原有的录制
+	ModeRecord
+	// ModeReplayOrRecord 有匹配的录制时回放, 否则请求真实服务并追加录制
+	ModeReplayOrRecord
+)
+
+// ErrNoInteraction 表示严格模式下找不到匹配的录制
+var ErrNoInteraction = errors.New("no matched interaction in cassette")
+
+// Cassette 表示一个录制文件
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions" yaml:"interactions"`
+}
+
+// Interaction 表示一次请求和响应
+type Interaction struct {
+	Request  Request  `json:"request" yaml:"request"`
+	Response Response `json:"response" yaml:"response"`
+}
+
+// Request 录制的请求
+type Request struct {
+	Method     string      `json:"method" yaml:"method"`
+	URL        string      `json:"url" yaml:"url"`
+	Header     http.Header `json:"header,omitempty" yaml:"header,omitempty"`
+	Body       string      `json:"body,omitempty" yaml:"body,omitempty"`
+	BodyBase64 bool        `json:"body_base64,omitempty" yaml:"body_base64,omitempty"`
+}
+
+// Response 录制的响应
+type Response struct {
+	StatusCode int         `json:"status_code" yaml:"status_code"`
+	Header     http.Header `json:"header,omitempty" yaml:"header,omitempty"`
+	Body       string      `json:"body,omitempty" yaml:"body,omitempty"`
+	BodyBase64 bool        `json:"body_base64,omitempty" yaml:"body_base64,omitempty"`
+}
+
+// Recorder 录制和回放 HTTP 请求
+type Recorder struct {
+	path string
+	opts options
+
+	lock     sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// New 新建一个录制器, path 为 cassette 文件路径, 根据扩展名选择 JSON 或 YAML 格式。回放模式下文件不存在时返回错误
+func New(path string, opts ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:     path,
+		opts:     mergeOptions(opts),
+		cassette: &Cassette{},
+	}
+	if r.opts.mode == ModeRecord {
+		return r, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && r.opts.mode == ModeReplayOrRecord {
+			return r, nil
+		}
+		return nil, fmt.Errorf("read cassette error (%w)", err)
+	}
+	if err := r.unmarshal(b); err != nil {
+		return nil, fmt.Errorf("unmarshal cassette error (%w)", err)
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// Cassette 返回当前的录制内容
+func (r *Recorder) Cassette() *Cassette {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.cassette
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	// 按照 http.RoundTripper 的约定不能修改 req, 因此在副本上读取请求体, 并将副本传给下层
+	out := req.Clone(req.Context())
+	reqBody, err := readAndRestore(&out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read request body error (%w)", err)
+	}
+	live := r.newRequest(out, reqBody)
+
+	if r.opts.mode != ModeRecord {
+		if it := r.match(live); it != nil {
+			return it.Response.toHTTP(req), nil
+		}
+		if r.opts.mode == ModeReplay {
+			if r.opts.strict {
+				return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, live.Method, live.URL)
+			}
+			return r.roundTrip(req, out)
+		}
+	}
+
+	rsp, err := r.roundTrip(req, out)
+	if err != nil {
+		return nil, err
+	}
+	rspBody, err := readAndRestore(&rsp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response body error (%w)", err)
+	}
+
+	it := &Interaction{Request: live}
+	it.Response.StatusCode = rsp.StatusCode
+	it.Response.Header = rsp.Header.Clone()
+	it.Response.Body, it.Response.BodyBase64 = encodeBody(rspBody)
+	for _, f := range r.opts.redactors {
+		f(it)
+	}
+	if err := r.add(it); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+// roundTrip 使用下层 transport 发送副本 out, 响应中的 Request 仍指向调用方的 req
+func (r *Recorder) roundTrip(req, out *http.Request) (*http.Response, error) {
+	rsp, err := r.opts.transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	rsp.Request = req
+	return rsp, nil
+}
+
+// Save 将录制内容写入 cassette 文件
+func (r *Recorder) Save() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.save()
+}
+
+func (r *Recorder) save() error {
+	b, err := r.marshal()
+	if err != nil {
+		return fmt.Errorf("marshal cassette error (%w)", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("create cassette dir error (%w)", err)
+	}
+	if err := os.WriteFile(r.path, b, 0o644); err != nil {
+		return fmt.Errorf("write cassette error (%w)", err)
+	}
+	return nil
+}
+
+func (r *Recorder) isYAML() bool {
+	switch strings.ToLower(filepath.Ext(r.path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+func (r *Recorder) unmarshal(b []byte) error {
+	if r.isYAML() {
+		return yaml.Unmarshal(b, r.cassette)
+	}
+	return json.Unmarshal(b, r.cassette)
+}
+
+func (r *Recorder) marshal() ([]byte, error) {
+	if !r.isYAML() {
+		return json.MarshalIndent(r.cassette, "", "  ")
+	}
+	buf := bytes.Buffer{}
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(r.cassette); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *Recorder) add(it *Interaction) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, it)
+	r.used = append(r.used, true)
+	return r.save()
+}
+
+// match 查找匹配的录制, 优先使用尚未回放过的, 以便按顺序回放多次相同的请求
+func (r *Recorder) match(live Request) *Interaction {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	last := -1
+	for i, it := range r.cassette.Interactions {
+		if !r.matches(live, it.Request) {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return it
+		}
+		last = i
+	}
+	if last < 0 {
+		return nil
+	}
+	return r.cassette.Interactions[last]
+}
+
+func (r *Recorder) matches(live, recorded Request) bool {
+	for _, m := range r.opts.matchers {
+		if !m(live, recorded) {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequest 将真实请求转换为录制格式, 并按照规则脱敏。匹配时也使用脱敏后的请求, 以便与录制内容比较
+func (r *Recorder) newRequest(req *http.Request, body []byte) Request {
+	it := &Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: req.Header.Clone(),
+		},
+	}
+	if it.Request.Method == "" {
+		it.Request.Method = http.MethodGet
+	}
+	it.Request.Body, it.Request.BodyBase64 = encodeBody(body)
+	for _, f := range r.opts.redactors {
+		f(it)
+	}
+	return it.Request
+}
+
+func (rsp Response) toHTTP(req *http.Request) *http.Response {
+	body := decodeBody(rsp.Body, rsp.BodyBase64)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rsp.StatusCode, http.StatusText(rsp.StatusCode)),
+		StatusCode:    rsp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rsp.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func readAndRestore(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	b, err := io.ReadAll(*body)
+	_ = (*body).Close()
+	*body = io.NopCloser(bytes.NewReader(b))
+	return b, err
+}
+
+func encodeBody(b []byte) (string, bool) {
+	if utf8.Valid(b) {
+		return string(b), false
+	}
+	return base64.StdEncoding.EncodeToString(b), true
+}
+
+func decodeBody(s string, isBase64 bool) []byte {
+	if !isBase64 {
+		return []byte(s)
+	}
+	b, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	return b
+}
@@ -0,0 +1,175 @@
+package httprecord_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Andrew-M-C/go.util/net/http"
+	"github.com/Andrew-M-C/go.util/net/http/httprecord"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	cv = convey.Convey
+	so = convey.So
+	eq = convey.ShouldEqual
+
+	isNil = convey.ShouldBeNil
+	isErr = convey.ShouldBeError
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(m.Run())
+}
+
+type echo struct {
+	Count int    `json:"count"`
+	Body  string `json:"body"`
+}
+
+func TestRecorder(t *testing.T) {
+	var count int64
+	svr := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		n := atomic.AddInt64(&count, 1)
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Set-Cookie", "session=secret")
+		_ = json.NewEncoder(w).Encode(echo{Count: int(n), Body: string(b)})
+	}))
+	defer svr.Close()
+
+	path := filepath.Join(t.TempDir(), "testdata", "echo.json")
+	ctx := context.Background()
+	redact := []httprecord.Option{
+		httprecord.WithRedactHeaders("Authorization", "Set-Cookie"),
+		httprecord.WithRedactQuery("access_token"),
+	}
+	request := func(rec *httprecord.Recorder, body string) (*echo, error) {
+		return http.JSON[echo](ctx, svr.URL+"/echo?access_token=abc&a=1",
+			http.WithMethod("POST"), http.WithRequestBody([]byte(body)),
+			http.WithRequestHeader(nethttp.Header{"Authorization": []string{"Bearer xyz"}}),
+			http.WithTransport(rec),
+		)
+	}
+
+	cv("录制", t, func() {
+		rec, err := httprecord.New(path, append(redact, httprecord.WithMode(httprecord.ModeRecord))...)
+		so(err, isNil)
+
+		rsp, err := request(rec, "hello")
+		so(err, isNil)
+		so(rsp.Count, eq, 1)
+		rsp, err = request(rec, "hello")
+		so(err, isNil)
+		so(rsp.Count, eq, 2)
+		rsp, err = request(rec, "world")
+		so(err, isNil)
+		so(rsp.Body, eq, "world")
+
+		b, err := os.ReadFile(path)
+		so(err, isNil)
+		so(strings.Contains(string(b), "xyz"), eq, false)
+		so(strings.Contains(string(b), "secret"), eq, false)
+		so(strings.Contains(string(b), "abc"), eq, false)
+		so(len(rec.Cassette().Interactions), eq, 3)
+	})
+
+	cv("严格回放", t, func() {
+		atomic.StoreInt64(&count, 100)
+		rec, err := httprecord.New(path, append(redact, httprecord.WithStrict())...)
+		so(err, isNil)
+
+		// 相同的请求按照录制顺序回放
+		rsp, err := request(rec, "hello")
+		so(err, isNil)
+		so(rsp.Count, eq, 1)
+		rsp, err = request(rec, "hello")
+		so(err, isNil)
+		so(rsp.Count, eq, 2)
+		rsp, err = request(rec, "world")
+		so(err, isNil)
+		so(rsp.Count, eq, 3)
+		so(atomic.LoadInt64(&count), eq, 100)
+
+		_, err = request(rec, "unknown")
+		so(err, isErr)
+		so(errors.Is(err, httprecord.ErrNoInteraction), eq, true)
+	})
+
+	cv("回放或录制", t, func() {
+		rec, err := httprecord.New(path, append(redact, httprecord.WithMode(httprecord.ModeReplayOrRecord))...)
+		so(err, isNil)
+
+		rsp, err := request(rec, "new")
+		so(err, isNil)
+		so(rsp.Count, eq, 101)
+		so(len(rec.Cassette().Interactions), eq, 4)
+
+		rsp, err = request(rec, "world")
+		so(err, isNil)
+		so(rsp.Count, eq, 3)
+	})
+
+	cv("自定义匹配规则", t, func() {
+		rec, err := httprecord.New(path, httprecord.WithStrict(),
+			httprecord.WithMatchers(httprecord.MatchPath, httprecord.MatchHeaders("Authorization")),
+		)
+		so(err, isNil)
+
+		// 未脱敏时 Authorization 不匹配
+		_, err = request(rec, "hello")
+		so(err, isErr)
+	})
+
+	cv("YAML 格式", t, func() {
+		yamlPath := filepath.Join(t.TempDir(), "echo.yaml")
+		rec, err := httprecord.New(yamlPath, append(redact, httprecord.WithMode(httprecord.ModeRecord))...)
+		so(err, isNil)
+		rsp, err := request(rec, "yaml")
+		so(err, isNil)
+		count := rsp.Count
+
+		b, err := os.ReadFile(yamlPath)
+		so(err, isNil)
+		so(string(b), convey.ShouldStartWith, "interactions:\n")
+		so(string(b), convey.ShouldContainSubstring, "status_code: 200")
+		so(strings.Contains(string(b), "xyz"), eq, false)
+
+		rec, err = httprecord.New(yamlPath, append(redact, httprecord.WithStrict())...)
+		so(err, isNil)
+		rsp, err = request(rec, "yaml")
+		so(err, isNil)
+		so(rsp.Count, eq, count)
+		so(rsp.Body, eq, "yaml")
+	})
+
+	cv("不修改调用方的请求", t, func() {
+		rec, err := httprecord.New(filepath.Join(t.TempDir(), "raw.json"), httprecord.WithMode(httprecord.ModeRecord))
+		so(err, isNil)
+		req, _ := nethttp.NewRequest("POST", svr.URL+"/raw", strings.NewReader("raw"))
+		body := req.Body
+		rsp, err := rec.RoundTrip(req)
+		so(err, isNil)
+		defer rsp.Body.Close()
+		so(req.Body, eq, body)
+		so(rsp.Request, eq, req)
+
+		var e echo
+		so(json.NewDecoder(rsp.Body).Decode(&e), isNil)
+		so(e.Body, eq, "raw")
+		so(rec.Cassette().Interactions[0].Request.Body, eq, "raw")
+	})
+
+	cv("文件不存在", t, func() {
+		_, err := httprecord.New(filepath.Join(t.TempDir(), "not-exist.json"))
+		so(err, isErr)
+	})
+}
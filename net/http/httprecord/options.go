@@ -0,0 +1,158 @@
+package httprecord
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+)
+
+// Redacted 脱敏后的替换值
+const Redacted = "[REDACTED]"
+
+// Option 录制器选项
+type Option func(*options)
+
+// Matcher 判断一个真实请求与录制的请求是否匹配, live 为已经脱敏后的真实请求
+type Matcher func(live, recorded Request) bool
+
+type options struct {
+	mode      Mode
+	strict    bool
+	transport http.RoundTripper
+	matchers  []Matcher
+	redactors []func(*Interaction)
+}
+
+func mergeOptions(opts []Option) options {
+	o := options{
+		transport: http.DefaultTransport,
+	}
+	for _, f := range opts {
+		if f != nil {
+			f(&o)
+		}
+	}
+	if len(o.matchers) == 0 {
+		o.matchers = []Matcher{MatchMethod, MatchURL, MatchBody}
+	}
+	return o
+}
+
+// WithMode 指定录制模式, 默认为 ModeReplay
+func WithMode(m Mode) Option {
+	return func(o *options) {
+		o.mode = m
+	}
+}
+
+// WithStrict 严格模式, 回放模式下找不到匹配的录制时返回 ErrNoInteraction, 而不是请求真实服务
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithTransport 指定请求真实服务使用的 transport, 默认为 http.DefaultTransport
+func WithTransport(t http.RoundTripper) Option {
+	return func(o *options) {
+		if t != nil {
+			o.transport = t
+		}
+	}
+}
+
+// WithMatchers 指定请求匹配规则, 所有规则都满足时视为匹配。默认为 MatchMethod、MatchURL 和 MatchBody
+func WithMatchers(m ...Matcher) Option {
+	return func(o *options) {
+		o.matchers = append(o.matchers, m...)
+	}
+}
+
+// MatchMethod 匹配请求方法
+func MatchMethod(live, recorded Request) bool {
+	return live.Method == recorded.Method
+}
+
+// MatchURL 匹配完整的 URL, query 参数不区分顺序
+func MatchURL(live, recorded Request) bool {
+	lu, err1 := url.Parse(live.URL)
+	ru, err2 := url.Parse(recorded.URL)
+	if err1 != nil || err2 != nil {
+		return live.URL == recorded.URL
+	}
+	lq, rq := lu.Query(), ru.Query()
+	lu.RawQuery, ru.RawQuery = "", ""
+	return lu.String() == ru.String() && lq.Encode() == rq.Encode()
+}
+
+// MatchPath 只匹配 URL 的 path 部分, 适用于 host 或 query 参数会变化的场景
+func MatchPath(live, recorded Request) bool {
+	lu, err1 := url.Parse(live.URL)
+	ru, err2 := url.Parse(recorded.URL)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return lu.Path == ru.Path
+}
+
+// MatchBody 匹配请求体
+func MatchBody(live, recorded Request) bool {
+	return live.Body == recorded.Body && live.BodyBase64 == recorded.BodyBase64
+}
+
+// MatchHeaders 匹配指定的请求头
+func MatchHeaders(keys ...string) Matcher {
+	return func(live, recorded Request) bool {
+		for _, k := range keys {
+			if !slices.Equal(live.Header.Values(k), recorded.Header.Values(k)) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// WithRedactHeaders 录制时将指定的请求头和响应头替换为 Redacted, 如 Authorization、Cookie 等
+func WithRedactHeaders(keys ...string) Option {
+	return WithRedactor(func(it *Interaction) {
+		for _, h := range []http.Header{it.Request.Header, it.Response.Header} {
+			for _, k := range keys {
+				if h.Get(k) != "" {
+					h.Set(k, Redacted)
+				}
+			}
+		}
+	})
+}
+
+// WithRedactQuery 录制时将 URL 中指定的 query 参数替换为 Redacted, 如 access_token、key 等
+func WithRedactQuery(keys ...string) Option {
+	return WithRedactor(func(it *Interaction) {
+		u, err := url.Parse(it.Request.URL)
+		if err != nil {
+			return
+		}
+		q := u.Query()
+		changed := false
+		for _, k := range keys {
+			if q.Has(k) {
+				q.Set(k, Redacted)
+				changed = true
+			}
+		}
+		if changed {
+			u.RawQuery = q.Encode()
+			it.Request.URL = u.String()
+		}
+	})
+}
+
+// WithRedactor 指定自定义的脱敏逻辑。脱敏逻辑同样会作用于回放时的真实请求, 以便与录制内容匹配,
+// 此时 Interaction 中只有 Request 部分
+func WithRedactor(f func(*Interaction)) Option {
+	return func(o *options) {
+		if f != nil {
+			o.redactors = append(o.redactors, f)
+		}
+	}
+}
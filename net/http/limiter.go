@@ -0,0 +1,265 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited 表示 fail-fast 模式下触发了限流
+var ErrRateLimited = errors.New("rate limited")
+
+// LimitRule 限流规则
+type LimitRule struct {
+	QPS         float64 // 每秒请求数 (令牌桶速率), 小于等于 0 表示不限制
+	Burst       int     // 令牌桶容量, 默认为 QPS 向上取整, 且不小于 1
+	MaxInFlight int     // 最大并发请求数, 小于等于 0 表示不限制
+}
+
+// Limiter 由令牌桶和并发数上限组成的限流器, 并发安全
+type Limiter struct {
+	rule LimitRule
+
+	lock   sync.Mutex
+	tokens float64
+	last   time.Time
+
+	slots chan struct{}
+}
+
+// NewLimiter 按照规则新建一个限流器
+func NewLimiter(rule LimitRule) *Limiter {
+	if rule.QPS > 0 && rule.Burst <= 0 {
+		rule.Burst = max(int(math.Ceil(rule.QPS)), 1)
+	}
+	l := &Limiter{
+		rule:   rule,
+		tokens: float64(rule.Burst),
+		last:   time.Now(),
+	}
+	if rule.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, rule.MaxInFlight)
+	}
+	return l
+}
+
+// Rule 返回限流规则
+func (l *Limiter) Rule() LimitRule {
+	return l.rule
+}
+
+// Acquire 等待直到允许发起请求, 返回的 release 函数需要在请求结束后调用, 以释放并发数。
+// ctx 结束时返回 ctx 的错误
+func (l *Limiter) Acquire(ctx context.Context) (release func(), err error) {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release = l.releaseFunc()
+
+	wait := l.reserve()
+	if wait <= 0 {
+		return release, nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return release, nil
+	case <-ctx.Done():
+		l.cancelReservation()
+		release()
+		return nil, ctx.Err()
+	}
+}
+
+// TryAcquire 不等待, 如果当前不允许发起请求则返回 false
+func (l *Limiter) TryAcquire() (release func(), ok bool) {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			return nil, false
+		}
+	}
+	release = l.releaseFunc()
+
+	if l.rule.QPS <= 0 {
+		return release, true
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.refill()
+	if l.tokens < 1 {
+		release()
+		return nil, false
+	}
+	l.tokens--
+	return release, true
+}
+
+func (l *Limiter) releaseFunc() func() {
+	if l.slots == nil {
+		return func() {}
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-l.slots })
+	}
+}
+
+// reserve 预占一个令牌, 返回需要等待的时间
+func (l *Limiter) reserve() time.Duration {
+	if l.rule.QPS <= 0 {
+		return 0
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.refill()
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rule.QPS * float64(time.Second))
+}
+
+func (l *Limiter) cancelReservation() {
+	if l.rule.QPS <= 0 {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.tokens = min(l.tokens+1, float64(l.rule.Burst))
+}
+
+func (l *Limiter) refill() {
+	now := time.Now()
+	l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.rule.QPS, float64(l.rule.Burst))
+	l.last = now
+}
+
+// LimiterRegistry 按照 key (一般为 host) 管理一组共享的限流器, 并发安全
+type LimiterRegistry struct {
+	lock        sync.Mutex
+	defaultRule LimitRule
+	rules       map[string]LimitRule
+	limiters    map[string]*Limiter
+}
+
+// NewLimiterRegistry 新建一个限流器注册表, 没有单独设置规则的 key 使用 defaultRule
+func NewLimiterRegistry(defaultRule LimitRule) *LimiterRegistry {
+	return &LimiterRegistry{
+		defaultRule: defaultRule,
+		rules:       map[string]LimitRule{},
+		limiters:    map[string]*Limiter{},
+	}
+}
+
+// DefaultLimiters 全局共享的限流器注册表, 默认不限流, 可以通过 SetRule 为指定的 host 设置规则
+var DefaultLimiters = NewLimiterRegistry(LimitRule{})
+
+// SetRule 为 key 设置限流规则, 已有的限流器会被替换
+func (r *LimiterRegistry) SetRule(key string, rule LimitRule) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.rules[key] = rule
+	delete(r.limiters, key)
+}
+
+// Limiter 获取 key 对应的限流器, 不存在时按照规则新建
+func (r *LimiterRegistry) Limiter(key string) *Limiter {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if l, ok := r.limiters[key]; ok {
+		return l
+	}
+	rule, ok := r.rules[key]
+	if !ok {
+		rule = r.defaultRule
+	}
+	l := NewLimiter(rule)
+	r.limiters[key] = l
+	return l
+}
+
+// Acquire 等待 key 对应的限流器, 参见 Limiter.Acquire
+func (r *LimiterRegistry) Acquire(ctx context.Context, key string) (release func(), err error) {
+	return r.Limiter(key).Acquire(ctx)
+}
+
+// WithRateLimit 使用 reg 中的限流器对请求限流, 默认以目标 URL 的 host 作为 key。
+// 并发数在响应 body 关闭时释放。配置了重试时, 每次尝试都需要经过限流
+func WithRateLimit(reg *LimiterRegistry) RequestOption {
+	return func(ro *requestOption) {
+		ro.limiters = reg
+	}
+}
+
+// WithRateLimitKey 指定限流使用的 key, 替代默认的 host
+func WithRateLimitKey(key string) RequestOption {
+	return func(ro *requestOption) {
+		ro.limitKey = key
+	}
+}
+
+// WithRateLimitFailFast 触发限流时不等待, 直接返回 ErrRateLimited
+func WithRateLimitFailFast() RequestOption {
+	return func(ro *requestOption) {
+		ro.limitFailFast = true
+	}
+}
+
+// limiterMiddleware 限流中间件, 位于缓存中间件之内
+func (o *requestOption) limiterMiddleware(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		key := o.limitKey
+		if key == "" {
+			key = req.URL.Host
+		}
+		l := o.limiters.Limiter(key)
+
+		var release func()
+		if o.limitFailFast {
+			ok := false
+			if release, ok = l.TryAcquire(); !ok {
+				o.debugf("rate limited by '%s'", key)
+				return nil, ErrRateLimited
+			}
+		} else {
+			start := time.Now()
+			var err error
+			if release, err = l.Acquire(req.Context()); err != nil {
+				return nil, err
+			}
+			if ela := time.Since(start); ela > time.Millisecond {
+				o.debugf("rate limiter '%s' waited %v", key, ela)
+			}
+		}
+
+		rsp, err := next(req)
+		if err != nil || rsp.Body == nil {
+			release()
+			return rsp, err
+		}
+		rsp.Body = &releaseOnClose{ReadCloser: rsp.Body, release: release}
+		return rsp, nil
+	}
+}
+
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
@@ -0,0 +1,91 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Andrew-M-C/go.util/net/http"
+)
+
+func TestLimiter(t *testing.T) {
+	cv("令牌桶", t, func() {
+		l := http.NewLimiter(http.LimitRule{QPS: 20, Burst: 2})
+		start := time.Now()
+		for range 4 {
+			release, err := l.Acquire(context.Background())
+			so(err, isNil)
+			release()
+		}
+		// 前两个立即通过, 后两个各需要约 50ms
+		so(time.Since(start), gt, 80*time.Millisecond)
+
+		_, ok := l.TryAcquire()
+		so(ok, eq, false)
+	})
+
+	cv("并发数上限与 ctx 超时", t, func() {
+		l := http.NewLimiter(http.LimitRule{MaxInFlight: 1})
+		release, err := l.Acquire(context.Background())
+		so(err, isNil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = l.Acquire(ctx)
+		so(errors.Is(err, context.DeadlineExceeded), eq, true)
+
+		release()
+		release() // 重复调用无副作用
+		release, ok := l.TryAcquire()
+		so(ok, eq, true)
+		_, ok = l.TryAcquire()
+		so(ok, eq, false)
+		release()
+	})
+
+	cv("请求限流", t, func() {
+		var inFlight, peak int64
+		svr := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			n := atomic.AddInt64(&inFlight, 1)
+			defer atomic.AddInt64(&inFlight, -1)
+			for {
+				p := atomic.LoadInt64(&peak)
+				if n <= p || atomic.CompareAndSwapInt64(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			_, _ = w.Write([]byte("ok"))
+		}))
+		defer svr.Close()
+
+		reg := http.NewLimiterRegistry(http.LimitRule{MaxInFlight: 2})
+		wg := sync.WaitGroup{}
+		errs := make([]error, 6)
+		for i := range errs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[i] = http.Raw(context.Background(), svr.URL, http.WithRateLimit(reg))
+			}()
+		}
+		wg.Wait()
+		so(errors.Join(errs...), isNil)
+		so(atomic.LoadInt64(&peak), eq, 2)
+
+		// fail-fast
+		reg.SetRule("custom", http.LimitRule{QPS: 1, Burst: 1})
+		opts := []http.RequestOption{
+			http.WithRateLimit(reg), http.WithRateLimitKey("custom"), http.WithRateLimitFailFast(),
+		}
+		_, err := http.Raw(context.Background(), svr.URL, opts...)
+		so(err, isNil)
+		_, err = http.Raw(context.Background(), svr.URL, opts...)
+		so(errors.Is(err, http.ErrRateLimited), eq, true)
+	})
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/Andrew-M-C/go.util/log"
+	"github.com/Andrew-M-C/go.util/log/trace"
+)
+
+// RoundTripFunc 表示一次 HTTP 往返, 实现了 http.RoundTripper 接口
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip 实现 http.RoundTripper
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware 客户端中间件, 可以用于签名、鉴权、日志、监控等。如需修改请求, 应先 Clone 再修改
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// WithMiddleware 为请求追加中间件。先添加的中间件在外层, 全局默认中间件在所有中间件的最外层。
+// 配置了重试时, 每次尝试都会经过中间件
+func WithMiddleware(mw ...Middleware) RequestOption {
+	return func(ro *requestOption) {
+		ro.middlewares = append(ro.middlewares, mw...)
+	}
+}
+
+var defaultMiddlewares atomic.Pointer[[]Middleware]
+
+// SetDefaultMiddlewares 设置全局默认的中间件, 对之后发起的所有请求生效
+func SetDefaultMiddlewares(mw ...Middleware) {
+	mw = slices.Clone(mw)
+	defaultMiddlewares.Store(&mw)
+}
+
+// DefaultMiddlewares 返回全局默认的中间件
+func DefaultMiddlewares() []Middleware {
+	if p := defaultMiddlewares.Load(); p != nil {
+		return slices.Clone(*p)
+	}
+	return nil
+}
+
+// wrapMiddlewares 按照全局默认中间件和请求中间件包装 transport
+func (o *requestOption) wrapMiddlewares(base http.RoundTripper) http.RoundTripper {
+	var chain []Middleware
+	if p := defaultMiddlewares.Load(); p != nil {
+		chain = append(chain, *p...)
+	}
+	chain = append(chain, o.middlewares...)
+	if o.cache != nil {
+		chain = append(chain, o.cacheMiddleware)
+	}
+	if o.limiters != nil {
+		chain = append(chain, o.limiterMiddleware)
+	}
+	if len(chain) == 0 {
+		return base
+	}
+
+	rt := RoundTripFunc(base.RoundTrip)
+	for i := len(chain) - 1; i >= 0; i-- {
+		if chain[i] != nil {
+			rt = chain[i](rt)
+		}
+	}
+	return rt
+}
+
+// TraceIDMiddleware 将 context 中 log/trace 的 trace ID 写入请求头, header 为空时使用 "X-Trace-ID"
+func TraceIDMiddleware(header string) Middleware {
+	if header == "" {
+		header = "X-Trace-ID"
+	}
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			id := trace.TraceID(req.Context())
+			if id == "" || req.Header.Get(header) != "" {
+				return next(req)
+			}
+			req = req.Clone(req.Context())
+			req.Header.Set(header, id)
+			return next(req)
+		}
+	}
+}
+
+// LogMiddleware 使用 log 包记录请求和响应。成功的请求使用 Info 级别, 失败的使用 Error 级别。
+// 请求体和响应体不会被记录
+func LogMiddleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			start := time.Now()
+			rsp, err := next(req)
+			ela := time.Since(start)
+			if err != nil {
+				log.ErrorContextf(ctx, "%s %s failed, ela %v, error: %v", req.Method, req.URL.Redacted(), ela, err)
+				return rsp, err
+			}
+			if rsp.StatusCode >= 400 {
+				log.ErrorContextf(ctx, "%s %s got %s, ela %v", req.Method, req.URL.Redacted(), rsp.Status, ela)
+			} else {
+				log.InfoContextf(ctx, "%s %s got %s, ela %v", req.Method, req.URL.Redacted(), rsp.Status, ela)
+			}
+			return rsp, nil
+		}
+	}
+}
+
+// AccessTokenProvider 提供访问凭证, 与 wxwork.AccessTokenGetter 兼容
+type AccessTokenProvider interface {
+	GetAccessToken(context.Context) (string, error)
+}
+
+// BearerTokenMiddleware 从 p 获取 token, 以 "Authorization: Bearer <token>" 的形式写入请求头
+func BearerTokenMiddleware(p AccessTokenProvider) Middleware {
+	return tokenMiddleware(p, func(req *http.Request, token string) {
+		req.Header.Set("Authorization", "Bearer "+token)
+	})
+}
+
+// QueryTokenMiddleware 从 p 获取 token, 写入 URL query 参数 key 中, 如企业微信的 access_token 参数
+func QueryTokenMiddleware(p AccessTokenProvider, key string) Middleware {
+	return tokenMiddleware(p, func(req *http.Request, token string) {
+		q := req.URL.Query()
+		q.Set(key, token)
+		req.URL.RawQuery = q.Encode()
+	})
+}
+
+func tokenMiddleware(p AccessTokenProvider, set func(*http.Request, string)) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			token, err := p.GetAccessToken(req.Context())
+			if err != nil {
+				return nil, fmt.Errorf("get access token error (%w)", err)
+			}
+			req = req.Clone(req.Context())
+			set(req, token)
+			return next(req)
+		}
+	}
+}
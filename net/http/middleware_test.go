@@ -0,0 +1,79 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Andrew-M-C/go.util/log/trace"
+	"github.com/Andrew-M-C/go.util/net/http"
+)
+
+type tokenGetter string
+
+func (g tokenGetter) GetAccessToken(context.Context) (string, error) {
+	if g == "" {
+		return "", errors.New("no token")
+	}
+	return string(g), nil
+}
+
+func TestMiddleware(t *testing.T) {
+	type result struct {
+		Header nethttp.Header `json:"header"`
+		Query  string         `json:"query"`
+	}
+	svr := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		_ = json.NewEncoder(w).Encode(result{Header: r.Header, Query: r.URL.RawQuery})
+	}))
+	defer svr.Close()
+
+	mark := func(name string, order *[]string) http.Middleware {
+		return func(next http.RoundTripFunc) http.RoundTripFunc {
+			return func(r *nethttp.Request) (*nethttp.Response, error) {
+				*order = append(*order, name)
+				return next(r)
+			}
+		}
+	}
+
+	cv("执行顺序", t, func() {
+		var order []string
+		http.SetDefaultMiddlewares(mark("default", &order))
+		defer http.SetDefaultMiddlewares()
+
+		_, err := http.Raw(context.Background(), svr.URL,
+			http.WithMiddleware(mark("a", &order), mark("b", &order)),
+			http.WithMiddleware(mark("c", &order)),
+		)
+		so(err, isNil)
+		so(order, resemble, []string{"default", "a", "b", "c"})
+		so(len(http.DefaultMiddlewares()), eq, 1)
+	})
+
+	cv("内置中间件", t, func() {
+		ctx := trace.WithTraceID(context.Background(), "trace-1234")
+		rsp, err := http.JSON[result](ctx, svr.URL+"?a=1",
+			http.WithMiddleware(
+				http.LogMiddleware(),
+				http.TraceIDMiddleware(""),
+				http.BearerTokenMiddleware(tokenGetter("bearer-token")),
+				http.QueryTokenMiddleware(tokenGetter("query-token"), "access_token"),
+			),
+		)
+		so(err, isNil)
+		so(rsp.Header.Get("X-Trace-ID"), eq, "trace-1234")
+		so(rsp.Header.Get("Authorization"), eq, "Bearer bearer-token")
+		so(rsp.Query, eq, "a=1&access_token=query-token")
+	})
+
+	cv("获取 token 失败", t, func() {
+		_, err := http.Raw(context.Background(), svr.URL,
+			http.WithMiddleware(http.BearerTokenMiddleware(tokenGetter(""))),
+		)
+		so(err, isErr)
+	})
+}
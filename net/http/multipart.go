@@ -0,0 +1,213 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// MultipartPart 表示 multipart/form-data 请求体中的一个部分, 可以是普通表单字段, 也可以是文件。
+// 文件内容在发送请求时才读取, 不会整个加载到内存中
+type MultipartPart struct {
+	FieldName   string // 表单字段名
+	FileName    string // 文件名, 为空表示普通表单字段
+	ContentType string // 文件的 Content-Type, 为空时根据文件扩展名判断
+
+	value  string
+	path   string
+	reader io.Reader
+	size   int64
+}
+
+// MultipartField 普通表单字段
+func MultipartField(fieldName, value string) MultipartPart {
+	return MultipartPart{FieldName: fieldName, value: value}
+}
+
+// MultipartFile 从磁盘读取的文件, 文件名默认使用路径中的文件名。由于文件可以重新打开, 配合 WithRetry 时可以重试
+func MultipartFile(fieldName, path string) MultipartPart {
+	return MultipartPart{
+		FieldName: fieldName,
+		FileName:  filepath.Base(path),
+		path:      path,
+		size:      -1,
+	}
+}
+
+// MultipartReader 从 io.Reader 读取的文件。size 表示内容长度, 未知时传 -1, 此时使用 chunked 方式发送。
+// io.Reader 只能读取一次, 因此请求不会被重试
+func MultipartReader(fieldName, fileName string, r io.Reader, size int64) MultipartPart {
+	return MultipartPart{
+		FieldName: fieldName,
+		FileName:  fileName,
+		reader:    r,
+		size:      size,
+	}
+}
+
+// WithMultipartBody 使用 multipart/form-data 格式发送请求体, 会覆盖 WithRequestBody 的设置。
+// 请求体以流的方式发送, 配合 WithProgressCallback 可以获取上传进度
+func WithMultipartBody(parts ...MultipartPart) RequestOption {
+	return func(ro *requestOption) {
+		ro.multipart = append(ro.multipart, parts...)
+	}
+}
+
+// streamBody 表示一个流式的请求体
+type streamBody struct {
+	// open 打开请求体, 每次重试都需要重新打开
+	open        func() (io.ReadCloser, error)
+	length      int64 // -1 表示未知
+	contentType string
+}
+
+type multipartSegment struct {
+	header []byte
+	part   MultipartPart
+}
+
+func newMultipartBody(parts []MultipartPart) (*streamBody, error) {
+	buff := &bytes.Buffer{}
+	w := multipart.NewWriter(buff)
+
+	segments := make([]multipartSegment, 0, len(parts))
+	length := int64(0)
+	for _, p := range parts {
+		h := textproto.MIMEHeader{}
+		if p.FileName == "" {
+			h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(p.FieldName)))
+		} else {
+			h.Set("Content-Disposition", fmt.Sprintf(
+				`form-data; name="%s"; filename="%s"`, escapeQuotes(p.FieldName), escapeQuotes(p.FileName),
+			))
+			h.Set("Content-Type", p.contentType())
+		}
+		if _, err := w.CreatePart(h); err != nil {
+			return nil, fmt.Errorf("create multipart header error (%w)", err)
+		}
+
+		size, err := p.contentLength()
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 || length < 0 {
+			length = -1
+		} else {
+			length += int64(buff.Len()) + size
+		}
+
+		segments = append(segments, multipartSegment{header: bytes.Clone(buff.Bytes()), part: p})
+		buff.Reset()
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("close multipart writer error (%w)", err)
+	}
+	trailer := bytes.Clone(buff.Bytes())
+	if length >= 0 {
+		length += int64(len(trailer))
+	}
+
+	readerUsed := int32(0)
+	open := func() (io.ReadCloser, error) {
+		readers := make([]io.Reader, 0, 2*len(segments)+1)
+		var files []*os.File
+		closeFiles := func() {
+			for _, f := range files {
+				_ = f.Close()
+			}
+		}
+		for _, seg := range segments {
+			readers = append(readers, bytes.NewReader(seg.header))
+			switch {
+			case seg.part.path != "":
+				f, err := os.Open(seg.part.path)
+				if err != nil {
+					closeFiles()
+					return nil, fmt.Errorf("open file error (%w)", err)
+				}
+				files = append(files, f)
+				readers = append(readers, f)
+			case seg.part.reader != nil:
+				if !atomic.CompareAndSwapInt32(&readerUsed, 0, 1) {
+					closeFiles()
+					return nil, errors.New("multipart reader cannot be read twice")
+				}
+				readers = append(readers, seg.part.reader)
+			default:
+				readers = append(readers, strings.NewReader(seg.part.value))
+			}
+		}
+		readers = append(readers, bytes.NewReader(trailer))
+		return &multiReadCloser{Reader: io.MultiReader(readers...), close: closeFiles}, nil
+	}
+
+	return &streamBody{
+		open:        open,
+		length:      length,
+		contentType: w.FormDataContentType(),
+	}, nil
+}
+
+func (p MultipartPart) contentType() string {
+	if p.ContentType != "" {
+		return p.ContentType
+	}
+	if t := mime.TypeByExtension(filepath.Ext(p.FileName)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+func (p MultipartPart) contentLength() (int64, error) {
+	switch {
+	case p.path != "":
+		st, err := os.Stat(p.path)
+		if err != nil {
+			return 0, fmt.Errorf("stat file error (%w)", err)
+		}
+		return st.Size(), nil
+	case p.reader != nil:
+		return p.size, nil
+	default:
+		return int64(len(p.value)), nil
+	}
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
+type multiReadCloser struct {
+	io.Reader
+	close func()
+}
+
+func (r *multiReadCloser) Close() error {
+	r.close()
+	return nil
+}
+
+// uploadProgressReader 在读取请求体时更新上传进度
+type uploadProgressReader struct {
+	io.ReadCloser
+	progress *requestProgressWriter
+}
+
+func (r *uploadProgressReader) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	if n > 0 {
+		atomic.AddInt64(&r.progress.uploadedLength, int64(n))
+		r.progress.invokeIfNotNil(SendingBody)
+	}
+	return n, err
+}
@@ -1,46 +1,38 @@
 package http
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
-	"time"
+	"strings"
 
-	"golang.org/x/net/html/charset"
+	"github.com/Andrew-M-C/go.util/net/charset"
 	"golang.org/x/text/encoding"
-	"golang.org/x/text/transform"
 )
 
 // Request 发起一个请求, 但是返回 *http.Response。适合 HTTP SSE 场景适配
 func Request(ctx context.Context, targetURL string, opts ...RequestOption) (*http.Response, error) {
-	o := mergeOptions(opts, json.Marshal)
-	return raw(ctx, targetURL, o)
+	return defaultClient.Request(ctx, targetURL, opts...)
 }
 
 // Raw 发起一个请求, 但是返回 []byte
 func Raw(ctx context.Context, targetURL string, opts ...RequestOption) (rsp []byte, err error) {
-	o := mergeOptions(opts, json.Marshal)
-	httpRsp, err := raw(ctx, targetURL, o)
-	if err != nil {
-		return nil, err
-	}
-	defer httpRsp.Body.Close()
-
-	return readBody(o, httpRsp.Header.Get("Content-Encoding"), httpRsp.Body)
+	return defaultClient.Raw(ctx, targetURL, opts...)
 }
 
 func raw(ctx context.Context, targetURL string, o *requestOption) (*http.Response, error) {
+	if o.queryErr != nil {
+		return nil, fmt.Errorf("encode query error (%w)", o.queryErr)
+	}
 	reqBody, err := o.getBody()
 	if err != nil {
 		return nil, err
 	}
-	u, err := url.Parse(targetURL)
+	u, err := url.Parse(o.resolveURL(targetURL))
 	if err != nil {
 		return nil, fmt.Errorf("illegal target URL (%w)", err)
 	}
@@ -56,34 +48,31 @@ func raw(ctx context.Context, targetURL string, o *requestOption) (*http.Respons
 	}
 
 	httpReq.Header = o.header
-	o.progress.invokeIfNotNil(ResponseReceived)
-
-	cli := http.Client{Transport: http.DefaultTransport}
-	start := time.Now()
-	httpRsp, err := cli.Do(httpReq)
-	ela := time.Since(start)
-	if err != nil {
-		return httpRsp, fmt.Errorf("cli.Do error (%w)", err)
+	if err := o.prepareBody(httpReq); err != nil {
+		return nil, err
 	}
 
-	if o.progress != nil {
-		o.progress.rsp = httpRsp
+	httpRsp, err := o.do(httpReq)
+	if err != nil {
+		return httpRsp, err
 	}
-	o.progress.invokeIfNotNil(ResponseReceived)
-	o.debugf("request done, ela %v, status %v", ela, httpRsp.Status)
 
-	if httpRsp.StatusCode != http.StatusOK {
+	if !o.statusAccepted(httpRsp.StatusCode) {
 		return httpRsp, errors.New(httpRsp.Status)
 	}
 	return httpRsp, nil
 }
 
+// 请求失败时读取的响应体大小上限
+const maxErrorBodySize = 4 << 20
+
 // rawAndRead raw 请求并 io.ReadAll, 拿到的 response 无需 close
 func rawAndRead(ctx context.Context, targetURL string, o *requestOption) (*http.Response, []byte, error) {
 	rsp, err := raw(ctx, targetURL, o)
 	if err != nil {
-		if rsp != nil && rsp.StatusCode != http.StatusOK {
-			b, _ := io.ReadAll(rsp.Body)
+		if rsp != nil && rsp.Body != nil {
+			defer rsp.Body.Close()
+			b, _ := io.ReadAll(io.LimitReader(rsp.Body, maxErrorBodySize))
 			return rsp, b, err
 		}
 		return rsp, nil, err
@@ -103,89 +92,66 @@ func rawAndRead(ctx context.Context, targetURL string, o *requestOption) (*http.
 
 // JSON 发起一个 JSON 请求
 func JSON[T any](ctx context.Context, targetURL string, opts ...RequestOption) (*T, error) {
-	o := mergeOptions(opts, json.Marshal)
-	if o.body != nil && o.header.Get("Content-Type") != "" {
-		o.header.Set("Content-Type", "application/json")
-	}
-	httpRsp, b, err := rawAndRead(ctx, targetURL, o)
-	if err != nil {
-		return nil, packError(httpRsp, b, err)
-	}
-	if len(b) == 0 {
-		return nil, errors.New("empty body from remote server")
-	}
-
-	o.debugf("response: '%s'", b)
-	o.debugf("response header: %+v", httpRsp.Header)
-
-	b = decodeIfNecessary(o, b, httpRsp)
 	rsp := new(T)
-	if err := json.Unmarshal(b, rsp); err != nil {
-		return nil, fmt.Errorf("json.Unmarshal error (%w)", err)
+	if err := defaultClient.JSON(ctx, targetURL, rsp, opts...); err != nil {
+		return nil, err
 	}
 	return rsp, nil
 }
 
 // XMLGetRspBody 发起一个 XML 请求并返回包体字节
 func XMLGetRspBody(ctx context.Context, targetURL string, opts ...RequestOption) ([]byte, error) {
-	o := mergeOptions(opts, xml.Marshal)
-	if o.body != nil {
-		o.header.Set("Content-Type", "application/xml")
-	}
-	httpRsp, b, err := rawAndRead(ctx, targetURL, o)
-	if err != nil {
-		return nil, packError(httpRsp, b, err)
-	}
-	if len(b) == 0 {
-		return nil, errors.New("empty body from remote server")
-	}
-
-	o.debugf("response: '%s'", b)
-	o.debugf("response header: %+v", httpRsp.Header)
-
-	b = decodeIfNecessary(o, b, httpRsp)
-	return b, nil
+	return defaultClient.XMLGetRspBody(ctx, targetURL, opts...)
 }
 
 // XML 发起一个 XML 请求
 //
 // WARNING: 未测试, 请注意
 func XML[T any](ctx context.Context, targetURL string, opts ...RequestOption) (*T, error) {
-	b, err := XMLGetRspBody(ctx, targetURL, opts...)
-	if err != nil {
-		return nil, err
-	}
 	rsp := new(T)
-	if err := xml.Unmarshal(b, &rsp); err != nil {
-		return nil, fmt.Errorf("xml.Unmarshal error (%w)", err)
+	if err := defaultClient.XML(ctx, targetURL, rsp, opts...); err != nil {
+		return nil, err
 	}
 	return rsp, nil
 }
 
+// decodeIfNecessary 将响应转换为 UTF-8。未通过 WithResponseCharset 指定编码时, 按照 Content-Type、
+// BOM、HTML meta 标签、XML 声明以及内容统计自动检测
 func decodeIfNecessary(o *requestOption, b []byte, httpRsp *http.Response) []byte {
 	dec := o.rspCharset
 	if dec != nil {
 		o.debugf("指定使用 charset %v", dec)
 	} else {
-		contentType := httpRsp.Header.Get("Content-Type")
-		charset, name, certain := charset.DetermineEncoding(b, contentType)
-		o.debugf("响应编码为 '%v', 是否确定 %v, 采用编码器 '%v'", name, certain, charset)
-		if charset == nil {
+		r := charset.Detect(b, httpRsp.Header.Get("Content-Type"))
+		o.debugf("响应编码为 '%s', 来源 %s", r.Name, r.Source)
+		if r.IsUTF8() {
 			return b
 		}
-		dec = charset
+		dec = r.Encoding
 	}
 	if dec == encoding.Nop {
 		o.debugf("指定 charset 为 nop, 则默认为 UTF-8")
 		return b
 	}
 
-	utf8Reader := transform.NewReader(bytes.NewReader(b), dec.NewDecoder())
-	utf8Byte, err := io.ReadAll(utf8Reader)
+	utf8Byte, err := charset.Decode(b, dec)
 	if err != nil {
 		o.debugf("解码失败, 预测编码为 %v, 错误 %v", dec, err)
 		return b
 	}
-
 	return utf8Byte
 }
+
+// isTextContent 判断响应是否为文本内容, 只有文本内容才需要转换编码
+func isTextContent(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case strings.HasSuffix(mediaType, "xml"), strings.HasSuffix(mediaType, "+json"):
+		return true
+	case mediaType == "application/json", mediaType == "application/javascript", mediaType == "application/xhtml+xml":
+		return true
+	}
+	return false
+}
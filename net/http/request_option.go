@@ -4,10 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
+	"sync/atomic"
+	"time"
 
+	urlutil "github.com/Andrew-M-C/go.util/net/url"
 	"golang.org/x/text/encoding"
 )
 
@@ -56,21 +61,31 @@ func WithRequestCookies(cookies []*http.Cookie) RequestOption {
 	}
 }
 
-// WithRequestBody 请求正文
+// WithCookieJar 使用 jar 自动保存响应中的 cookie, 并在请求中携带, 参见 cookiejar 子包
+func WithCookieJar(jar http.CookieJar) RequestOption {
+	return func(ro *requestOption) {
+		ro.jar = jar
+	}
+}
+
+// WithRequestBody 请求正文。[]byte 类型直接发送; io.Reader 类型以流的方式发送, 不会读取到内存中;
+// 其他类型按照请求的格式 (如 JSON、XML) 序列化
 func WithRequestBody(req any) RequestOption {
 	return func(ro *requestOption) {
 		ro.body = req
 	}
 }
 
-// WithQuery query 参数
-func WithQuery(q url.Values) RequestOption {
+// WithQuery query 参数。除了 url.Values 以外, 还可以直接传入带有 url tag 的结构体, 编码规则参见
+// net/url 子包的 MarshalValues, 编码失败时请求返回错误
+func WithQuery(q any) RequestOption {
 	return func(ro *requestOption) {
-		for k, values := range q {
-			for _, v := range values {
-				ro.query.Add(k, v)
-			}
+		values, err := urlutil.MarshalValues(q)
+		if err != nil {
+			ro.queryErr = err
+			return
 		}
+		ro.mergeQuery(values)
 	}
 }
 
@@ -83,7 +98,7 @@ func WithDebugger(f func(string, ...any)) RequestOption {
 	}
 }
 
-// WithResponseCharset 指定响应 charset
+// WithResponseCharset 指定响应 charset。不指定时自动检测, 参见 net/charset 包
 func WithResponseCharset(c encoding.Encoding) RequestOption {
 	return func(ro *requestOption) {
 		ro.rspCharset = c
@@ -105,6 +120,45 @@ func WithSSEUnmarshalErrorCallback(cb func(error, string)) RequestOption {
 	}
 }
 
+// WithClient 指定发起请求使用的 http.Client, 默认使用 http.DefaultTransport 构建一个新的 http.Client。
+// 可用于复用连接池、设置 cookie jar 等。WithTransport 和 WithTimeout 会在其基础上生效, 但不会修改传入的 client
+func WithClient(cli *http.Client) RequestOption {
+	return func(ro *requestOption) {
+		ro.client = cli
+	}
+}
+
+// WithTransport 指定发起请求使用的 http.RoundTripper, 可用于设置代理、自定义 TLS 配置 (如客户端证书、
+// 私有 CA) 和连接池参数等。一般可以基于 http.DefaultTransport.(*http.Transport).Clone() 修改
+func WithTransport(t http.RoundTripper) RequestOption {
+	return func(ro *requestOption) {
+		ro.transport = t
+	}
+}
+
+// WithTimeout 指定单次请求的超时时间, 包括连接、发送请求和读取响应体。如果配置了重试, 则每次尝试单独计时。
+// 与 context 的超时同时生效
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(ro *requestOption) {
+		ro.timeout = timeout
+	}
+}
+
+// WithBaseURL 指定 base URL。当请求的目标 URL 不是绝对地址时, 会拼接在 base URL 之后。一般配合 NewClient 使用
+func WithBaseURL(baseURL string) RequestOption {
+	return func(ro *requestOption) {
+		ro.baseURL = baseURL
+	}
+}
+
+// WithAcceptedStatus 指定视为请求成功的状态码, 默认所有 2xx 状态码均视为成功。其他状态码会返回 *Error,
+// 可以通过 UnwrapError 获取响应详情
+func WithAcceptedStatus(codes ...int) RequestOption {
+	return func(ro *requestOption) {
+		ro.acceptedStatus = codes
+	}
+}
+
 type requestOption struct {
 	method string
 	header http.Header
@@ -120,15 +174,49 @@ type requestOption struct {
 	progress   *requestProgressWriter
 
 	sseUnmarshalErrorCB func(error, string)
+	sseReconnectMax     int
+	sseReconnectDelay   time.Duration
+	sseHeartbeatTimeout time.Duration
+
+	queryErr error
+
+	retry *RetryPolicy
+
+	client    *http.Client
+	transport http.RoundTripper
+	timeout   time.Duration
+	baseURL   string
+
+	acceptedStatus []int
+
+	multipart []MultipartPart
+
+	downloadChunks     int
+	downloadHash       func() hash.Hash
+	downloadSum        string
+	downloadProgressCB func(DownloadProgress)
+
+	middlewares []Middleware
+	cache       CacheStore
+
+	jar http.CookieJar
+
+	limiters      *LimiterRegistry
+	limitKey      string
+	limitFailFast bool
 }
 
 type marshalerType func(any) ([]byte, error)
 type unmarshalerType func([]byte, any) error
 
 func (o *requestOption) getBody() (io.Reader, error) {
-	if o.body == nil {
+	if o.body == nil || len(o.multipart) > 0 {
 		return nil, nil
 	}
+	if r, ok := o.body.(io.Reader); ok {
+		o.debugf("request body is a %T stream", r)
+		return r, nil
+	}
 	if b, ok := o.body.([]byte); ok {
 		o.debugf("request body '%s'", b)
 		return bytes.NewBuffer(b), nil
@@ -150,6 +238,9 @@ func mergeOptions(opts []RequestOption, marshaler marshalerType) *requestOption
 		debugf:      func(string, ...any) {},
 		marshaler:   marshaler,
 		unmarshaler: json.Unmarshal,
+
+		sseReconnectMax:   3,
+		sseReconnectDelay: 3 * time.Second,
 	}
 	for _, f := range opts {
 		if f != nil {
@@ -168,6 +259,53 @@ func mergeOptions(opts []RequestOption, marshaler marshalerType) *requestOption
 	return o
 }
 
+// prepareBody 处理流式请求体, 并在需要时统计上传进度
+func (o *requestOption) prepareBody(req *http.Request) error {
+	if len(o.multipart) > 0 {
+		sb, err := newMultipartBody(o.multipart)
+		if err != nil {
+			return err
+		}
+		body, err := sb.open()
+		if err != nil {
+			return err
+		}
+		req.Body, req.GetBody, req.ContentLength = body, sb.open, sb.length
+		req.Header.Set("Content-Type", sb.contentType)
+		o.debugf("multipart body with %d parts, length %d", len(o.multipart), sb.length)
+	}
+
+	if o.progress == nil || req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	o.progress.uploadLength = req.ContentLength
+	if o.progress.uploadLength <= 0 {
+		o.progress.uploadLength = -1
+	}
+	wrap := func(rc io.ReadCloser) io.ReadCloser {
+		atomic.StoreInt64(&o.progress.uploadedLength, 0)
+		return &uploadProgressReader{ReadCloser: rc, progress: o.progress}
+	}
+	req.Body = wrap(req.Body)
+	if getBody := req.GetBody; getBody != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			rc, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return wrap(rc), nil
+		}
+	}
+	return nil
+}
+
+func (o *requestOption) statusAccepted(code int) bool {
+	if len(o.acceptedStatus) == 0 {
+		return code >= 200 && code < 300
+	}
+	return slices.Contains(o.acceptedStatus, code)
+}
+
 func (o *requestOption) mergeQuery(q url.Values) {
 	for k, values := range q {
 		for _, v := range values {
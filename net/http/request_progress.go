@@ -3,7 +3,9 @@ package http
 import (
 	"net/http"
 	"strconv"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // RequestState 表示请求中间阶段
@@ -16,6 +18,10 @@ const (
 	ResponseReceived
 	// ReceivingBody 表示正在读取响应 body
 	ReceivingBody
+	// WaitingRetry 表示本次尝试失败, 正在等待重试
+	WaitingRetry
+	// SendingBody 表示正在发送请求 body
+	SendingBody
 )
 
 // RequestProgress 表示请求进度, 用于回调
@@ -23,6 +29,13 @@ type RequestProgress struct {
 	state         RequestState
 	contentLength int64
 	readLength    int64
+	attempt       int
+	retryWait     time.Duration
+
+	uploadLength   int64
+	uploadedLength int64
+
+	cacheHit bool
 
 	rsp *http.Response
 }
@@ -54,6 +67,34 @@ func (p *RequestProgress) ContentLength() int64 {
 	return i
 }
 
+// Attempt 表示当前是第几次尝试, 从 1 开始
+func (p *RequestProgress) Attempt() int {
+	return p.attempt
+}
+
+// RetryWait 表示重试前需要等待的时间, 仅在 WaitingRetry 状态下有意义
+func (p *RequestProgress) RetryWait() time.Duration {
+	return p.retryWait
+}
+
+// UploadLength 表示请求 body 的大小, -1 表示未知 (chunked 发送) 或没有 body
+func (p *RequestProgress) UploadLength() int64 {
+	if p.uploadLength == 0 {
+		return -1
+	}
+	return p.uploadLength
+}
+
+// UploadedLength 表示已发送的请求 body 大小
+func (p *RequestProgress) UploadedLength() int64 {
+	return atomic.LoadInt64(&p.uploadedLength)
+}
+
+// CacheHit 表示响应是否来自 WithCache 指定的缓存 (包括重新验证后未修改的情况)
+func (p *RequestProgress) CacheHit() bool {
+	return p.cacheHit
+}
+
 // ReadLength 表示已读取的 body 大小
 func (p *RequestProgress) ReadLength() int64 {
 	return atomic.LoadInt64(&p.readLength)
@@ -62,6 +103,8 @@ func (p *RequestProgress) ReadLength() int64 {
 type requestProgressWriter struct {
 	*RequestProgress
 
+	// 上传进度在 transport 的协程中回调, 因此需要加锁保证回调串行
+	lock     sync.Mutex
 	callback func(*RequestProgress)
 }
 
@@ -76,6 +119,8 @@ func (p *requestProgressWriter) invokeIfNotNil(s RequestState) {
 	if p == nil {
 		return
 	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
 	p.state = s
 
 	if cb := p.callback; cb != nil {
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy 请求重试策略
+type RetryPolicy struct {
+	// MaxAttempts 最多尝试次数 (含第一次), 小于等于 1 表示不重试
+	MaxAttempts int
+	// InitialBackoff 第一次重试前的等待时间, 默认 100ms
+	InitialBackoff time.Duration
+	// MaxBackoff 最大等待时间, 默认 10s。服务端 Retry-After 指定的时间不受此限制
+	MaxBackoff time.Duration
+	// Multiplier 每次重试等待时间的增长倍数, 默认 2
+	Multiplier float64
+	// Jitter 随机抖动比例, 取值 [0, 1], 如 0.2 表示在等待时间上下浮动 20%
+	Jitter float64
+	// RetryNonIdempotent 是否对非幂等的方法 (如 POST、PATCH) 也进行重试, 默认只重试幂等方法
+	RetryNonIdempotent bool
+	// Retryable 判断一次请求的结果是否需要重试, 为空时使用 DefaultRetryable
+	Retryable func(rsp *http.Response, err error) bool
+}
+
+// WithRetry 指定请求失败时的重试策略。只有请求体可以重新读取时才会重试: WithRequestBody 指定的 []byte
+// 和序列化的结构体, 以及只包含 MultipartField、MultipartFile 的 multipart 请求体可以重试; WithRequestBody
+// 指定的 io.Reader 和 MultipartReader 以流的方式发送, 只能读取一次, 因此不会重试
+func WithRetry(policy RetryPolicy) RequestOption {
+	return func(ro *requestOption) {
+		p := policy
+		ro.retry = &p
+	}
+}
+
+// DefaultRetryable 默认的重试判断逻辑: 网络错误 (context 取消和超时除外), 以及 408、429、500、
+// 502、503、504 状态码需要重试
+func DefaultRetryable(rsp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	if rsp == nil {
+		return false
+	}
+	switch rsp.StatusCode {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsIdempotentMethod 判断 HTTP 方法是否为幂等的
+func IsIdempotentMethod(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *RetryPolicy) retryable(req *http.Request, rsp *http.Response, err error) bool {
+	if !p.RetryNonIdempotent && !IsIdempotentMethod(req.Method) {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(rsp, err)
+	}
+	return DefaultRetryable(rsp, err)
+}
+
+// backoff 计算第 n 次重试 (从 1 开始) 之前需要等待的时间
+func (p *RetryPolicy) backoff(n int, rsp *http.Response) time.Duration {
+	if d, ok := parseRetryAfter(rsp); ok {
+		return d
+	}
+
+	initial, maxBackoff, mul := p.InitialBackoff, p.MaxBackoff, p.Multiplier
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = 10 * time.Second
+	}
+	if mul < 1 {
+		mul = 2
+	}
+
+	d := float64(initial) * math.Pow(mul, float64(n-1))
+	d = math.Min(d, float64(maxBackoff))
+	if j := math.Min(p.Jitter, 1); j > 0 {
+		d *= 1 - j + 2*j*rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// parseRetryAfter 解析 Retry-After 头, 支持秒数和 HTTP 日期两种格式
+func parseRetryAfter(rsp *http.Response) (time.Duration, bool) {
+	if rsp == nil {
+		return 0, false
+	}
+	v := rsp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Duration(max(sec, 0)) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// do 发出请求, 按照重试策略进行重试
+func (o *requestOption) do(req *http.Request) (*http.Response, error) {
+	p := o.retry
+	for attempt := 1; ; attempt++ {
+		rsp, err := o.doOnce(req, attempt)
+		if p == nil || attempt >= p.MaxAttempts || !p.retryable(req, rsp, err) {
+			return rsp, err
+		}
+
+		next, ok := rewindRequest(req)
+		if !ok {
+			o.debugf("request body cannot be rewound, give up retrying")
+			return rsp, err
+		}
+
+		wait := p.backoff(attempt, rsp)
+		if rsp != nil {
+			o.debugf("attempt %d got status %v, retry after %v", attempt, rsp.Status, wait)
+			_, _ = io.Copy(io.Discard, io.LimitReader(rsp.Body, 64<<10))
+			_ = rsp.Body.Close()
+		} else {
+			o.debugf("attempt %d got error '%v', retry after %v", attempt, err, wait)
+		}
+		if o.progress != nil {
+			o.progress.rsp = nil
+			o.progress.retryWait = wait
+		}
+		o.progress.invokeIfNotNil(WaitingRetry)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, fmt.Errorf("wait for retry error (%w)", req.Context().Err())
+		case <-timer.C:
+		}
+		req = next
+	}
+}
+
+func (o *requestOption) doOnce(req *http.Request, attempt int) (*http.Response, error) {
+	if o.progress != nil {
+		o.progress.attempt = attempt
+	}
+	o.progress.invokeIfNotNil(RequestInitialized)
+
+	cli := o.httpClient()
+	start := time.Now()
+	httpRsp, err := cli.Do(req)
+	ela := time.Since(start)
+	if err != nil {
+		return httpRsp, fmt.Errorf("cli.Do error (%w)", err)
+	}
+
+	if o.progress != nil {
+		o.progress.rsp = httpRsp
+	}
+	o.progress.invokeIfNotNil(ResponseReceived)
+	o.debugf("attempt %d done, ela %v, status %v", attempt, ela, httpRsp.Status)
+	return httpRsp, nil
+}
+
+// rewindRequest 复制一个请求并重置请求体, 用于重试
+func rewindRequest(req *http.Request) (*http.Request, bool) {
+	next := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return next, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	next.Body = body
+	return next, true
+}
@@ -0,0 +1,114 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Andrew-M-C/go.util/net/http"
+)
+
+func TestRetry(t *testing.T) {
+	var count int64
+	svr := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		n := atomic.AddInt64(&count, 1)
+		b, _ := io.ReadAll(r.Body)
+		if n < 3 {
+			if r.URL.Path != "/no-retry-after" {
+				w.Header().Set("Retry-After", "0")
+			}
+			w.WriteHeader(nethttp.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"int":1,"str":"` + string(b) + `"}`))
+	}))
+	defer svr.Close()
+
+	policy := http.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		Jitter:         0.5,
+	}
+
+	cv("幂等方法重试成功, 并且重放请求体", t, func() {
+		atomic.StoreInt64(&count, 0)
+		var attempts []int
+		rsp, err := http.JSON[testSt](
+			context.Background(), svr.URL,
+			http.WithMethod("PUT"), http.WithRequestBody([]byte("body")),
+			http.WithRetry(policy), http.WithDebugger(t.Logf),
+			http.WithProgressCallback(func(p *http.RequestProgress) {
+				if p.RequestState() == http.RequestInitialized {
+					attempts = append(attempts, p.Attempt())
+				}
+			}),
+		)
+		so(err, isNil)
+		so(rsp.Str, eq, "body")
+		so(atomic.LoadInt64(&count), eq, 3)
+		so(attempts, resemble, []int{1, 2, 3})
+	})
+
+	cv("非幂等方法默认不重试", t, func() {
+		atomic.StoreInt64(&count, 0)
+		_, err := http.JSON[testSt](
+			context.Background(), svr.URL,
+			http.WithMethod("POST"), http.WithRequestBody([]byte("body")), http.WithRetry(policy),
+		)
+		so(err, isErr)
+		so(atomic.LoadInt64(&count), eq, 1)
+
+		atomic.StoreInt64(&count, 0)
+		p := policy
+		p.RetryNonIdempotent = true
+		_, err = http.JSON[testSt](
+			context.Background(), svr.URL,
+			http.WithMethod("POST"), http.WithRequestBody([]byte("body")), http.WithRetry(p),
+		)
+		so(err, isNil)
+		so(atomic.LoadInt64(&count), eq, 3)
+	})
+
+	cv("超过最大次数", t, func() {
+		atomic.StoreInt64(&count, 0)
+		p := policy
+		p.MaxAttempts = 2
+		_, err := http.JSON[testSt](context.Background(), svr.URL, http.WithRetry(p))
+		so(err, isErr)
+		so(atomic.LoadInt64(&count), eq, 2)
+
+		e, ok := http.UnwrapError(err)
+		so(ok, eq, true)
+		so(e.Detail().StatusCode, eq, nethttp.StatusServiceUnavailable)
+	})
+
+	cv("自定义重试判断", t, func() {
+		atomic.StoreInt64(&count, 0)
+		p := policy
+		p.Retryable = func(rsp *nethttp.Response, err error) bool {
+			return false
+		}
+		_, err := http.JSON[testSt](context.Background(), svr.URL, http.WithRetry(p))
+		so(err, isErr)
+		so(atomic.LoadInt64(&count), eq, 1)
+	})
+
+	cv("等待重试时 context 取消", t, func() {
+		atomic.StoreInt64(&count, 0)
+		p := policy
+		p.InitialBackoff = time.Hour
+		p.Retryable = func(*nethttp.Response, error) bool { return true }
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err := http.Raw(ctx, svr.URL+"/no-retry-after", http.WithRetry(p))
+		so(err, isErr)
+		so(errors.Is(err, context.DeadlineExceeded), eq, true)
+		so(atomic.LoadInt64(&count), eq, 1)
+	})
+}
@@ -0,0 +1,328 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event 表示一个 SSE 事件
+type Event[T any] struct {
+	ID    string        // 事件 ID, 即 'id: ' 字段。未指定时沿用上一个事件的 ID
+	Event string        // 事件类型, 即 'event: ' 字段, 未指定时为 "message"
+	Retry time.Duration // 服务端通过 'retry: ' 字段指定的重连间隔, 未指定时为 0
+	Data  T             // 反序列化后的数据
+	Raw   string        // 原始数据, 多行 'data: ' 使用 '\n' 连接
+}
+
+// SSE 发起一个 SSE 请求, 并以迭代器的形式返回服务端推送的事件。事件数据使用 json 反序列化为 T 类型,
+// 如果 T 为 string, 则直接使用原始数据。
+//
+// 连接异常中断或者在 WithSSEHeartbeatTimeout 指定的时间内没有收到任何数据 (包括 ': ' 开头的心跳注释)
+// 时, 会携带 Last-Event-ID 自动重连, 参见 WithSSEReconnect。服务端正常关闭连接、返回 204 状态码,
+// 或者推送 OpenAI 风格的 '[DONE]' 数据时, 迭代结束。
+//
+// 反序列化失败时, 如果指定了 WithSSEUnmarshalErrorCallback 则调用该回调并跳过该事件, 否则将错误交给
+// 调用方, 调用方可以选择继续迭代。其他错误 (包括 context 取消) 交给调用方之后, 迭代结束。
+func SSE[T any](ctx context.Context, targetURL string, opts ...RequestOption) iter.Seq2[Event[T], error] {
+	return func(yield func(Event[T], error) bool) {
+		s := newSSEStream(ctx, targetURL, opts)
+		defer s.closeBody()
+
+		for {
+			raw, err := s.next()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					yield(Event[T]{}, err)
+				}
+				return
+			}
+			if raw.data == "[DONE]" {
+				s.o.debugf("got '[DONE]', stop reading")
+				return
+			}
+
+			ev := Event[T]{
+				ID:    raw.id,
+				Event: raw.event,
+				Retry: raw.retry,
+				Raw:   raw.data,
+			}
+			if p, ok := any(&ev.Data).(*string); ok {
+				*p = raw.data
+			} else if err := s.o.unmarshaler([]byte(raw.data), &ev.Data); err != nil {
+				if cb := s.o.sseUnmarshalErrorCB; cb != nil {
+					s.o.debugf("Unmarshal error: %v, data: '%s'", err, raw.data)
+					cb(err, raw.data)
+					continue
+				}
+				if !yield(ev, fmt.Errorf("unmarshal SSE data error (%w)", err)) {
+					return
+				}
+				continue
+			}
+			if !yield(ev, nil) {
+				return
+			}
+		}
+	}
+}
+
+// WithSSEReconnect 指定 SSE 连接异常中断时的自动重连策略。maxAttempts 为连续重连的最大次数, 成功收到
+// 事件之后重新计数, 小于等于 0 表示不重连; delay 为重连间隔, 服务端通过 'retry: ' 字段指定的间隔优先。
+// 默认最多连续重连 3 次, 间隔 3 秒
+func WithSSEReconnect(maxAttempts int, delay time.Duration) RequestOption {
+	return func(ro *requestOption) {
+		ro.sseReconnectMax = maxAttempts
+		if delay > 0 {
+			ro.sseReconnectDelay = delay
+		}
+	}
+}
+
+// WithSSEHeartbeatTimeout 指定 SSE 连接的空闲超时时间。超过该时间没有收到任何数据 (包括心跳) 时,
+// 认为连接已经失效并重连。默认不检测
+func WithSSEHeartbeatTimeout(timeout time.Duration) RequestOption {
+	return func(ro *requestOption) {
+		ro.sseHeartbeatTimeout = timeout
+	}
+}
+
+var errSSEIdle = errors.New("no data received within heartbeat timeout")
+
+type sseRawEvent struct {
+	id    string
+	event string
+	retry time.Duration
+	data  string
+}
+
+func (ev sseRawEvent) dispatch(data []string) sseRawEvent {
+	ev.data = strings.Join(data, "\n")
+	if ev.event == "" {
+		ev.event = "message"
+	}
+	return ev
+}
+
+type sseLine struct {
+	line string
+	err  error
+}
+
+type sseStream struct {
+	ctx       context.Context
+	targetURL string
+	opts      []RequestOption
+	o         *requestOption
+
+	lastID   string
+	retry    time.Duration
+	failures int
+
+	body  io.ReadCloser
+	lines chan sseLine
+	done  chan struct{}
+}
+
+func newSSEStream(ctx context.Context, targetURL string, opts []RequestOption) *sseStream {
+	return &sseStream{
+		ctx:       ctx,
+		targetURL: targetURL,
+		opts:      opts,
+		o:         mergeOptions(opts, json.Marshal),
+	}
+}
+
+// next 读取下一个事件, 流正常结束时返回 io.EOF
+func (s *sseStream) next() (sseRawEvent, error) {
+	ev := sseRawEvent{id: s.lastID}
+	var data []string
+
+	for {
+		if s.lines == nil {
+			if err := s.connect(); err != nil {
+				return ev, err
+			}
+		}
+
+		line, err := s.readLine()
+		if err != nil {
+			s.closeBody()
+			if ctxErr := s.ctx.Err(); ctxErr != nil {
+				return ev, ctxErr
+			}
+			if errors.Is(err, io.EOF) {
+				// 服务端正常关闭连接, 没有以空行结束的事件按照规范丢弃, 不再重连
+				if len(data) > 0 {
+					s.o.debugf("SSE stream ended with incomplete event, discarded")
+				}
+				return sseRawEvent{id: s.lastID}, io.EOF
+			}
+			s.o.debugf("SSE stream interrupted: %v", err)
+			// 未完成的事件丢弃, 重连之后由服务端根据 Last-Event-ID 重新推送
+			ev, data = sseRawEvent{id: s.lastID}, nil
+			continue
+		}
+
+		s.o.debugf("Read line: '%s'", line)
+		if line == "" {
+			if len(data) == 0 {
+				ev.event = ""
+				continue
+			}
+			s.failures = 0
+			return ev.dispatch(data), nil
+		}
+		if strings.HasPrefix(line, ":") {
+			s.o.debugf("SSE heartbeat '%s'", line)
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			data = append(data, value)
+		case "event":
+			ev.event = value
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				ev.id, s.lastID = value, value
+			}
+		case "retry":
+			if ms, err := strconv.ParseInt(value, 10, 64); err == nil && ms >= 0 {
+				ev.retry = time.Duration(ms) * time.Millisecond
+				s.retry = ev.retry
+			}
+		default:
+			// 忽略未知字段
+		}
+	}
+}
+
+// connect 建立连接, 首次连接失败时直接返回错误, 重连时按照重连策略等待并重试
+func (s *sseStream) connect() error {
+	for {
+		reconnecting := s.done != nil
+		if reconnecting {
+			s.failures++
+			if s.failures > s.o.sseReconnectMax {
+				return fmt.Errorf("SSE reconnect failed after %d attempts", s.failures-1)
+			}
+			delay := s.o.sseReconnectDelay
+			if s.retry > 0 {
+				delay = s.retry
+			}
+			s.o.debugf("SSE reconnect #%d after %v, Last-Event-ID '%s'", s.failures, delay, s.lastID)
+			timer := time.NewTimer(delay)
+			select {
+			case <-s.ctx.Done():
+				timer.Stop()
+				return s.ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		err := s.dial()
+		if err == nil || !reconnecting || errors.Is(err, io.EOF) {
+			return err
+		}
+		if _, isHTTPErr := UnwrapError(err); isHTTPErr {
+			return err
+		}
+		s.o.debugf("SSE reconnect error: %v", err)
+	}
+}
+
+func (s *sseStream) dial() error {
+	o := mergeOptions(s.opts, json.Marshal)
+	if o.header.Get("Accept") == "" {
+		o.header.Set("Accept", "text/event-stream")
+	}
+	o.header.Set("Cache-Control", "no-cache")
+	if s.lastID != "" {
+		o.header.Set("Last-Event-ID", s.lastID)
+	}
+
+	rsp, err := raw(s.ctx, s.targetURL, o)
+	if err != nil {
+		if rsp != nil {
+			defer rsp.Body.Close()
+			b, _ := io.ReadAll(io.LimitReader(rsp.Body, maxErrorBodySize))
+			return packError(rsp, b, err)
+		}
+		return err
+	}
+	if rsp.StatusCode == http.StatusNoContent {
+		rsp.Body.Close()
+		s.o.debugf("SSE server returned %v, stop reading", rsp.Status)
+		return io.EOF
+	}
+
+	s.body = rsp.Body
+	s.lines = make(chan sseLine)
+	s.done = make(chan struct{})
+	go readSSELines(rsp.Body, s.lines, s.done)
+	return nil
+}
+
+func (s *sseStream) readLine() (string, error) {
+	var timeout <-chan time.Time
+	if d := s.o.sseHeartbeatTimeout; d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-s.ctx.Done():
+		return "", s.ctx.Err()
+	case <-timeout:
+		return "", errSSEIdle
+	case l := <-s.lines:
+		return l.line, l.err
+	}
+}
+
+func (s *sseStream) closeBody() {
+	if s.body == nil {
+		return
+	}
+	close(s.done)
+	_ = s.body.Close()
+	s.body, s.lines = nil, nil
+}
+
+func readSSELines(body io.Reader, lines chan<- sseLine, done <-chan struct{}) {
+	send := func(l sseLine) bool {
+		select {
+		case lines <- l:
+			return true
+		case <-done:
+			return false
+		}
+	}
+
+	reader := bufio.NewReader(body)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" && (err == nil || errors.Is(err, io.EOF)) {
+			if !send(sseLine{line: strings.TrimRight(line, "\r\n")}) {
+				return
+			}
+		}
+		if err != nil {
+			send(sseLine{err: err})
+			return
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Andrew-M-C/go.util/net/http"
+)
+
+func TestSSE(t *testing.T) {
+	type event struct {
+		Data string `json:"data"`
+	}
+
+	var lastEventIDs []string
+	svr := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		id := r.Header.Get("Last-Event-ID")
+		lastEventIDs = append(lastEventIDs, id)
+		write := func(s string) {
+			_, _ = fmt.Fprint(w, s)
+			w.(nethttp.Flusher).Flush()
+		}
+
+		switch r.URL.Path {
+		case "/multi":
+			write("id: 1\nevent: greet\ndata: hello\n\n")
+			write(": keep-alive\n\n")
+			write("data: line1\ndata: line2\n\n")
+			write("data: [DONE]\n\n")
+			write("data: never\n\n")
+		case "/resume":
+			if id == "" {
+				write("retry: 10\nid: 1\ndata: {\"data\":\"a\"}\n\n")
+				write("data: {\"data\":\"partial")
+				panic(nethttp.ErrAbortHandler)
+			}
+			write("id: 2\ndata: {\"data\":\"b\"}\n\n")
+		case "/idle":
+			if id == "" {
+				write("retry: 10\nid: 1\ndata: {\"data\":\"a\"}\n\n")
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+				return
+			}
+			write("id: 2\ndata: {\"data\":\"b\"}\n\n")
+		case "/unterminated":
+			write("data: a\n\ndata: b\n")
+		case "/bad-data":
+			write("data: {\"data\":\"a\"}\n\ndata: oops\n\ndata: {\"data\":\"b\"}\n\n")
+		default:
+			w.WriteHeader(nethttp.StatusNoContent)
+		}
+	}))
+	defer svr.Close()
+	ctx := context.Background()
+
+	cv("字段解析与多行 data", t, func() {
+		var events []http.Event[string]
+		for ev, err := range http.SSE[string](ctx, svr.URL+"/multi", http.WithDebugger(t.Logf)) {
+			so(err, isNil)
+			events = append(events, ev)
+		}
+		so(len(events), eq, 2)
+		so(events[0].ID, eq, "1")
+		so(events[0].Event, eq, "greet")
+		so(events[0].Data, eq, "hello")
+		so(events[1].ID, eq, "1")
+		so(events[1].Event, eq, "message")
+		so(events[1].Data, eq, "line1\nline2")
+	})
+
+	cv("连接中断后携带 Last-Event-ID 重连", t, func() {
+		lastEventIDs = nil
+		var data []string
+		for ev, err := range http.SSE[event](ctx, svr.URL+"/resume", http.WithDebugger(t.Logf)) {
+			so(err, isNil)
+			data = append(data, ev.Data.Data)
+		}
+		so(data, resemble, []string{"a", "b"})
+		so(lastEventIDs, resemble, []string{"", "1"})
+	})
+
+	cv("结尾没有空行的事件丢弃, 不重连", t, func() {
+		lastEventIDs = nil
+		var data []string
+		for ev, err := range http.SSE[string](ctx, svr.URL+"/unterminated") {
+			so(err, isNil)
+			data = append(data, ev.Data)
+		}
+		so(data, resemble, []string{"a"})
+		so(len(lastEventIDs), eq, 1)
+	})
+
+	cv("心跳超时后重连", t, func() {
+		lastEventIDs = nil
+		var data []string
+		for ev, err := range http.SSE[event](
+			ctx, svr.URL+"/idle", http.WithSSEHeartbeatTimeout(50*time.Millisecond),
+		) {
+			so(err, isNil)
+			data = append(data, ev.Data.Data)
+		}
+		so(data, resemble, []string{"a", "b"})
+		so(lastEventIDs, resemble, []string{"", "1"})
+	})
+
+	cv("context 取消", t, func() {
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		var errs []error
+		for _, err := range http.SSE[event](ctx, svr.URL+"/idle") {
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+		so(len(errs), eq, 1)
+		so(errors.Is(errs[0], context.DeadlineExceeded), eq, true)
+	})
+
+	cv("反序列化错误", t, func() {
+		var data []string
+		var errCount int
+		for ev, err := range http.SSE[event](ctx, svr.URL+"/bad-data") {
+			if err != nil {
+				errCount++
+				so(ev.Raw, eq, "oops")
+				continue
+			}
+			data = append(data, ev.Data.Data)
+		}
+		so(errCount, eq, 1)
+		so(data, resemble, []string{"a", "b"})
+	})
+
+	cv("204 表示没有数据", t, func() {
+		count := 0
+		for range http.SSE[event](ctx, svr.URL+"/none") {
+			count++
+		}
+		so(count, eq, 0)
+	})
+}
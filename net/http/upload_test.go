@@ -0,0 +1,119 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Andrew-M-C/go.util/net/http"
+)
+
+func TestUpload(t *testing.T) {
+	type part struct {
+		FileName    string `json:"file_name"`
+		ContentType string `json:"content_type"`
+		Content     string `json:"content"`
+	}
+	type result struct {
+		ContentLength int64             `json:"content_length"`
+		Fields        map[string]string `json:"fields"`
+		Files         map[string]part   `json:"files"`
+		Body          string            `json:"body"`
+	}
+
+	svr := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		res := result{
+			ContentLength: r.ContentLength,
+			Fields:        map[string]string{},
+			Files:         map[string]part{},
+		}
+		if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			b, _ := io.ReadAll(r.Body)
+			res.Body = string(b)
+			_ = json.NewEncoder(w).Encode(res)
+			return
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(nethttp.StatusBadRequest)
+			return
+		}
+		for k, v := range r.MultipartForm.Value {
+			res.Fields[k] = v[0]
+		}
+		for k, v := range r.MultipartForm.File {
+			f, _ := v[0].Open()
+			b, _ := io.ReadAll(f)
+			_ = f.Close()
+			res.Files[k] = part{
+				FileName:    v[0].Filename,
+				ContentType: v[0].Header.Get("Content-Type"),
+				Content:     string(b),
+			}
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	defer svr.Close()
+
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "hello.json")
+	content := strings.Repeat("hello, world\n", 10000)
+	if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cv("上传磁盘文件和表单字段", t, func() {
+		var uploadLength, uploaded int64
+		b, err := http.UploadFile(context.Background(), svr.URL, "file", filePath,
+			http.WithMultipartBody(http.MultipartField("name", "demo")),
+			http.WithProgressCallback(func(p *http.RequestProgress) {
+				if p.RequestState() == http.SendingBody {
+					uploadLength, uploaded = p.UploadLength(), p.UploadedLength()
+				}
+			}),
+		)
+		so(err, isNil)
+
+		res := result{}
+		so(json.Unmarshal(b, &res), isNil)
+		so(res.Fields["name"], eq, "demo")
+		so(res.Files["file"].FileName, eq, "hello.json")
+		so(res.Files["file"].ContentType, eq, "application/json")
+		so(res.Files["file"].Content, eq, content)
+
+		so(res.ContentLength, gt, len(content))
+		so(uploadLength, eq, res.ContentLength)
+		so(uploaded, eq, uploadLength)
+	})
+
+	cv("从 io.Reader 上传, 长度未知", t, func() {
+		res, err := http.JSON[result](context.Background(), svr.URL,
+			http.WithMethod("PUT"),
+			http.WithMultipartBody(
+				http.MultipartReader("data", "data.bin", strings.NewReader("raw data"), -1),
+			),
+		)
+		so(err, isNil)
+		so(res.ContentLength, eq, -1)
+		so(res.Files["data"].ContentType, eq, "application/octet-stream")
+		so(res.Files["data"].Content, eq, "raw data")
+	})
+
+	cv("流式请求体", t, func() {
+		res, err := http.JSON[result](context.Background(), svr.URL,
+			http.WithMethod("POST"), http.WithRequestBody(io.LimitReader(strings.NewReader(content), 100)),
+		)
+		so(err, isNil)
+		so(res.Body, eq, content[:100])
+	})
+
+	cv("文件不存在", t, func() {
+		_, err := http.UploadFile(context.Background(), svr.URL, "file", filepath.Join(dir, "not-exist"))
+		so(err, isErr)
+	})
+}
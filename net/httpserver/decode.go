@@ -0,0 +1,219 @@
+package httpserver
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// decodeRequest 将 path 参数、query 参数和请求体解析到 req (结构体指针) 中
+func decodeRequest(r *http.Request, req any, maxBodySize int64) error {
+	v := reflect.ValueOf(req).Elem()
+	if v.Kind() != reflect.Struct {
+		return decodeBody(r, req, v, maxBodySize)
+	}
+
+	if err := decodeValues(v, "path", func(name string) []string {
+		if s := r.PathValue(name); s != "" {
+			return []string{s}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	query := r.URL.Query()
+	if err := decodeValues(v, "query", valuesGetter(query)); err != nil {
+		return err
+	}
+	return decodeBody(r, req, v, maxBodySize)
+}
+
+func decodeBody(r *http.Request, req any, v reflect.Value, maxBodySize int64) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	r.Body = http.MaxBytesReader(nil, r.Body, maxBodySize)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if strings.HasSuffix(mediaType, "+json") {
+		mediaType = "application/json"
+	}
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return fmt.Errorf("read body error (%w)", err)
+		}
+		form, err := url.ParseQuery(string(b))
+		if err != nil {
+			return fmt.Errorf("parse form error (%w)", err)
+		}
+		return decodeStructValues(v, form)
+
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(maxBodySize); err != nil {
+			return fmt.Errorf("parse multipart form error (%w)", err)
+		}
+		return decodeStructValues(v, r.MultipartForm.Value)
+
+	case "", "application/json":
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("unmarshal JSON error (%w)", err)
+		}
+		return nil
+
+	default:
+		return NewError(http.StatusUnsupportedMediaType, "", "unsupported content type "+mediaType)
+	}
+}
+
+func decodeStructValues(v reflect.Value, values url.Values) error {
+	if v.Kind() != reflect.Struct {
+		return errors.New("form body requires a struct request")
+	}
+	return decodeValues(v, "form", valuesGetter(values))
+}
+
+func valuesGetter(values url.Values) func(string) []string {
+	return func(name string) []string {
+		return values[name]
+	}
+}
+
+// decodeValues 按照 tag 将参数写入结构体的字段, 没有 tag 时使用 json tag 中的名称, 都没有时使用字段名
+func decodeValues(v reflect.Value, tag string, get func(string) []string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fv := v.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			if err := decodeValues(fv, tag, get); err != nil {
+				return err
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, explicit := fieldName(sf, tag)
+		if name == "" || (tag == "path" && !explicit) {
+			continue
+		}
+		values := get(name)
+		if len(values) == 0 {
+			continue
+		}
+		if err := setValue(fv, values); err != nil {
+			return fmt.Errorf("invalid %s parameter '%s' (%w)", tag, name, err)
+		}
+	}
+	return nil
+}
+
+func fieldName(sf reflect.StructField, tag string) (name string, explicit bool) {
+	if s, ok := sf.Tag.Lookup(tag); ok {
+		name, _, _ = strings.Cut(s, ",")
+		if name == "-" {
+			return "", true
+		}
+		if name != "" {
+			return name, true
+		}
+	}
+	if s, ok := sf.Tag.Lookup("json"); ok {
+		name, _, _ = strings.Cut(s, ",")
+		if name == "-" {
+			return "", false
+		}
+		if name != "" {
+			return name, false
+		}
+	}
+	return sf.Name, false
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+func setValue(fv reflect.Value, values []string) error {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setValue(fv.Elem(), values)
+	}
+	if fv.Addr().Type().Implements(textUnmarshalerType) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0]))
+	}
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+		s := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i, str := range values {
+			if err := setValue(s.Index(i), []string{str}); err != nil {
+				return err
+			}
+		}
+		fv.Set(s)
+		return nil
+	}
+	return setScalar(fv, values[0])
+}
+
+func setScalar(fv reflect.Value, s string) error {
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Slice: // []byte
+		fv.SetBytes([]byte(s))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %v", fv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	errutil "github.com/Andrew-M-C/go.util/errors"
+	"github.com/Andrew-M-C/go.util/log"
+	"github.com/Andrew-M-C/go.util/log/trace"
+)
+
+// ErrorResponse 处理失败时返回的 JSON 结构。
+//
+// HTTP 状态码和 Code 按照以下规则确定:
+//
+//   - 错误链中实现了 StatusCode() int 的错误 (如 *Error) 决定状态码, 否则为 500
+//   - 错误链中实现了 ErrorCode() string 并且返回非空时使用该 code, 否则使用 errors.ErrorToCode 生成
+//
+// 5xx 错误只返回状态码的描述作为 Message, 不暴露内部错误信息, 可以通过 Code 和 TraceID 查找日志
+type ErrorResponse struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	TraceID   string `json:"trace_id,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Error 携带 HTTP 状态码的错误
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Err     error
+}
+
+// NewError 新建一个携带状态码的错误, code 为空时使用 errors.ErrorToCode 生成
+func NewError(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// WrapError 为 err 附加状态码
+func WrapError(status int, err error) *Error {
+	return &Error{Status: status, Err: err}
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Message != "":
+		return e.Message
+	case e.Err != nil:
+		return e.Err.Error()
+	default:
+		return http.StatusText(e.Status)
+	}
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode 返回 HTTP 状态码
+func (e *Error) StatusCode() int {
+	return e.Status
+}
+
+// ErrorCode 返回错误码
+func (e *Error) ErrorCode() string {
+	return e.Code
+}
+
+type statusCoder interface {
+	StatusCode() int
+}
+
+type errorCoder interface {
+	ErrorCode() string
+}
+
+func (o *options) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var sc statusCoder
+	if errors.As(err, &sc) && sc.StatusCode() >= 400 {
+		status = sc.StatusCode()
+	}
+	rsp := ErrorResponse{
+		Message:   err.Error(),
+		TraceID:   trace.TraceID(ctx),
+		RequestID: w.Header().Get(o.requestIDHeader),
+	}
+	var ec errorCoder
+	if errors.As(err, &ec) {
+		rsp.Code = ec.ErrorCode()
+	}
+	if rsp.Code == "" {
+		rsp.Code = errutil.ErrorToCode(err)
+	}
+
+	if status >= 500 {
+		log.ErrorContextf(ctx, "handle request error, code %s: %v", rsp.Code, err)
+		rsp.Message = http.StatusText(status)
+	} else {
+		log.DebugContextf(ctx, "bad request, code %s: %v", rsp.Code, err)
+	}
+	writeJSON(ctx, w, status, rsp)
+}
@@ -0,0 +1,112 @@
+// Package httpserver 提供泛型的 JSON HTTP 服务端处理函数适配器
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	ctxutil "github.com/Andrew-M-C/go.util/context"
+	"github.com/Andrew-M-C/go.util/log"
+	"github.com/Andrew-M-C/go.util/log/trace"
+)
+
+// HandlerFunc 业务处理函数
+type HandlerFunc[Req, Rsp any] func(ctx context.Context, req *Req) (*Rsp, error)
+
+// Validator 请求参数校验接口, Req 实现了该接口时, 在解析完参数后调用
+type Validator interface {
+	Validate() error
+}
+
+// Handle 将业务处理函数适配为 http.Handler。
+//
+// 请求参数按照以下顺序解析到 Req 中, 后解析的覆盖先解析的:
+//
+//   - path 参数, 使用 `path` tag
+//   - URL query 参数, 使用 `query` tag
+//   - 请求体。JSON 按照 encoding/json 的规则解析; 表单 (urlencoded 和 multipart) 使用 `form` tag
+//
+// 未指定 query / form tag 的字段使用 json tag 中的名称。解析完成后依次调用 Req 的 Validate 方法和
+// WithValidator 指定的校验函数, 校验失败返回 400。
+//
+// 处理函数返回的 Rsp 以 JSON 格式返回, Rsp 为 nil 时返回 204; 返回的错误参见 ErrorResponse。
+// ctx 中会注入 trace ID (log/trace) 和 unique ID (context.WithUniqueID), 并分别写入响应头
+func Handle[Req, Rsp any](f HandlerFunc[Req, Rsp], opts ...Option) http.Handler {
+	o := mergeOptions(opts)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := trace.WithTraceID(r.Context(), r.Header.Get(o.traceHeader))
+		ctx = trace.EnsureTraceID(ctx)
+		ctx, uid := ctxutil.WithUniqueID(ctx, r.Header.Get(o.requestIDHeader))
+		r = r.WithContext(context.WithValue(ctx, requestKey{}, r))
+		ctx = r.Context()
+
+		w.Header().Set(o.traceHeader, trace.TraceID(ctx))
+		w.Header().Set(o.requestIDHeader, uid)
+
+		req := new(Req)
+		if err := o.decode(r, req); err != nil {
+			o.writeError(ctx, w, err)
+			return
+		}
+		rsp, err := f(ctx, req)
+		if err != nil {
+			o.writeError(ctx, w, err)
+			return
+		}
+		if rsp == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(ctx, w, http.StatusOK, rsp)
+	})
+}
+
+type requestKey struct{}
+
+// Request 返回 Handle 处理中的原始请求, ctx 不是由 Handle 传入时返回 nil
+func Request(ctx context.Context) *http.Request {
+	r, _ := ctx.Value(requestKey{}).(*http.Request)
+	return r
+}
+
+func (o *options) decode(r *http.Request, req any) error {
+	if err := decodeRequest(r, req, o.maxBodySize); err != nil {
+		var sc statusCoder
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &sc):
+			return err
+		case errors.As(err, &maxBytesErr):
+			return WrapError(http.StatusRequestEntityTooLarge, err)
+		default:
+			return WrapError(http.StatusBadRequest, err)
+		}
+	}
+	if v, ok := req.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return WrapError(http.StatusBadRequest, err)
+		}
+	}
+	if o.validator != nil {
+		if err := o.validator(r.Context(), req); err != nil {
+			return WrapError(http.StatusBadRequest, err)
+		}
+	}
+	return nil
+}
+
+func writeJSON(ctx context.Context, w http.ResponseWriter, status int, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		log.ErrorContextf(ctx, "marshal response error: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if _, err := w.Write(b); err != nil {
+		log.DebugContextf(ctx, "write response error: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package httpserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	errutil "github.com/Andrew-M-C/go.util/errors"
+	"github.com/Andrew-M-C/go.util/log/trace"
+	"github.com/Andrew-M-C/go.util/net/httpserver"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	cv = convey.Convey
+	so = convey.So
+	eq = convey.ShouldEqual
+
+	resemble = convey.ShouldResemble
+
+	isNil = convey.ShouldBeNil
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(m.Run())
+}
+
+type echoReq struct {
+	ID      int           `path:"id"`
+	Name    string        `json:"name"`
+	Tags    []string      `query:"tag" form:"tag"`
+	Timeout time.Duration `query:"timeout"`
+	Limit   *int          `json:"limit"`
+}
+
+func (r *echoReq) Validate() error {
+	if r.Name == "invalid" {
+		return errors.New("invalid name")
+	}
+	return nil
+}
+
+type echoRsp struct {
+	echoReq
+	TraceID string `json:"trace_id"`
+	UID     string `json:"uid"`
+	Method  string `json:"method"`
+}
+
+var errInternal = errors.New("database is down")
+
+func echo(ctx context.Context, req *echoReq) (*echoRsp, error) {
+	switch req.Name {
+	case "not-found":
+		return nil, httpserver.NewError(http.StatusNotFound, "USER_NOT_FOUND", "user not found")
+	case "internal":
+		return nil, errInternal
+	case "empty":
+		return nil, nil
+	}
+	rsp := &echoRsp{echoReq: *req, TraceID: trace.TraceID(ctx)}
+	if r := httpserver.Request(ctx); r != nil {
+		rsp.Method = r.Method
+	}
+	return rsp, nil
+}
+
+func TestHandle(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/echo/{id}", httpserver.Handle(echo))
+	svr := httptest.NewServer(mux)
+	defer svr.Close()
+
+	do := func(method, path, contentType, body string) (*http.Response, []byte) {
+		req, err := http.NewRequest(method, svr.URL+path, strings.NewReader(body))
+		so(err, isNil)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.Header.Set("X-Trace-ID", "trace-1")
+		rsp, err := http.DefaultClient.Do(req)
+		so(err, isNil)
+		defer rsp.Body.Close()
+		b, err := io.ReadAll(rsp.Body)
+		so(err, isNil)
+		return rsp, b
+	}
+
+	cv("JSON 与 query 参数", t, func() {
+		rsp, b := do("POST", "/echo/12?tag=a&tag=b&timeout=3s", "application/json", `{"name":"Alice","limit":5}`)
+		so(rsp.StatusCode, eq, 200)
+		so(rsp.Header.Get("X-Trace-ID"), eq, "trace-1")
+		so(rsp.Header.Get("X-Request-ID"), convey.ShouldNotBeEmpty)
+
+		res := echoRsp{}
+		so(json.Unmarshal(b, &res), isNil)
+		so(res.ID, eq, 12)
+		so(res.Name, eq, "Alice")
+		so(res.Tags, resemble, []string{"a", "b"})
+		so(res.Timeout, eq, 3*time.Second)
+		so(*res.Limit, eq, 5)
+		so(res.TraceID, eq, "trace-1")
+		so(res.Method, eq, "POST")
+	})
+
+	cv("表单参数", t, func() {
+		form := url.Values{"name": {"Bob"}, "tag": {"x"}, "limit": {"7"}}
+		rsp, b := do("POST", "/echo/1", "application/x-www-form-urlencoded", form.Encode())
+		so(rsp.StatusCode, eq, 200)
+		res := echoRsp{}
+		so(json.Unmarshal(b, &res), isNil)
+		so(res.Name, eq, "Bob")
+		so(res.Tags, resemble, []string{"x"})
+		so(*res.Limit, eq, 7)
+	})
+
+	cv("错误处理", t, func() {
+		check := func(rsp *http.Response, b []byte, status int, code string) httpserver.ErrorResponse {
+			so(rsp.StatusCode, eq, status)
+			res := httpserver.ErrorResponse{}
+			so(json.Unmarshal(b, &res), isNil)
+			so(res.Code, eq, code)
+			so(res.TraceID, eq, "trace-1")
+			so(res.RequestID, eq, rsp.Header.Get("X-Request-ID"))
+			return res
+		}
+
+		rsp, b := do("POST", "/echo/1", "application/json", `{"name":"invalid"}`)
+		res := check(rsp, b, 400, errutil.ErrorToCode(errors.New("invalid name")))
+		so(res.Message, eq, "invalid name")
+
+		rsp, b = do("POST", "/echo/1", "application/json", `{"name":"not-found"}`)
+		res = check(rsp, b, 404, "USER_NOT_FOUND")
+		so(res.Message, eq, "user not found")
+
+		// 内部错误不暴露错误信息
+		rsp, b = do("POST", "/echo/1", "application/json", `{"name":"internal"}`)
+		res = check(rsp, b, 500, errutil.ErrorToCode(errInternal))
+		so(res.Message, eq, "Internal Server Error")
+
+		rsp, _ = do("GET", "/echo/abc", "", "")
+		so(rsp.StatusCode, eq, 400)
+		rsp, _ = do("POST", "/echo/1", "text/plain", "hello")
+		so(rsp.StatusCode, eq, 415)
+		rsp, _ = do("POST", "/echo/1", "application/json", `{"name":"empty"}`)
+		so(rsp.StatusCode, eq, 204)
+	})
+}
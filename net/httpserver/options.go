@@ -0,0 +1,61 @@
+package httpserver
+
+import "context"
+
+// Option 表示 Handle 的选项
+type Option func(*options)
+
+type options struct {
+	traceHeader     string
+	requestIDHeader string
+	maxBodySize     int64
+	validator       func(context.Context, any) error
+}
+
+func mergeOptions(opts []Option) *options {
+	o := &options{
+		traceHeader:     "X-Trace-ID",
+		requestIDHeader: "X-Request-ID",
+		maxBodySize:     10 << 20,
+	}
+	for _, f := range opts {
+		if f != nil {
+			f(o)
+		}
+	}
+	return o
+}
+
+// WithTraceHeader 指定读取和返回 trace ID 的 header, 默认为 "X-Trace-ID"
+func WithTraceHeader(h string) Option {
+	return func(o *options) {
+		if h != "" {
+			o.traceHeader = h
+		}
+	}
+}
+
+// WithRequestIDHeader 指定读取和返回 unique ID 的 header, 默认为 "X-Request-ID"
+func WithRequestIDHeader(h string) Option {
+	return func(o *options) {
+		if h != "" {
+			o.requestIDHeader = h
+		}
+	}
+}
+
+// WithMaxBodySize 限制请求体的大小, 默认为 10 MiB
+func WithMaxBodySize(n int64) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.maxBodySize = n
+		}
+	}
+}
+
+// WithValidator 指定额外的参数校验函数, 参数为 *Req, 可以用于接入第三方校验库
+func WithValidator(f func(ctx context.Context, req any) error) Option {
+	return func(o *options) {
+		o.validator = f
+	}
+}
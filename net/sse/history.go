@@ -0,0 +1,54 @@
+package sse
+
+import (
+	"strconv"
+	"sync"
+)
+
+// History 保存最近发布的事件, 用于客户端携带 Last-Event-ID 重连时重放, 并发安全。
+// 同一个事件流的多个连接应共享同一个 History: 每个事件只调用一次 Publish, 再通过各个连接的 Writer.Send 推送
+type History struct {
+	lock   sync.Mutex
+	size   int
+	events []Event
+	nextID uint64
+}
+
+// NewHistory 新建一个最多保存 size 个事件的历史记录
+func NewHistory(size int) *History {
+	return &History{size: max(size, 1)}
+}
+
+// Publish 为没有 ID 的事件分配递增的 ID, 加入历史记录, 并返回分配 ID 之后的事件
+func (h *History) Publish(e Event) Event {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.nextID++
+	if e.ID == "" {
+		e.ID = strconv.FormatUint(h.nextID, 10)
+	}
+	h.events = append(h.events, e)
+	if len(h.events) > h.size {
+		h.events = append(h.events[:0:0], h.events[len(h.events)-h.size:]...)
+	}
+	return e
+}
+
+// Since 返回 ID 为 id 的事件之后的所有事件。如果 id 已经不在历史记录中 (过旧或者未知), 返回全部历史记录
+func (h *History) Since(id string) []Event {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for i := len(h.events) - 1; i >= 0; i-- {
+		if h.events[i].ID == id {
+			return append([]Event(nil), h.events[i+1:]...)
+		}
+	}
+	return append([]Event(nil), h.events...)
+}
+
+// Len 返回历史记录中的事件数量
+func (h *History) Len() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return len(h.events)
+}
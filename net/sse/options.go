@@ -0,0 +1,45 @@
+package sse
+
+import "time"
+
+// Option 表示 Writer 的选项
+type Option func(*options)
+
+type options struct {
+	heartbeat time.Duration
+	retry     time.Duration
+	history   *History
+}
+
+func mergeOptions(opts []Option) *options {
+	o := &options{
+		heartbeat: 15 * time.Second,
+	}
+	for _, f := range opts {
+		if f != nil {
+			f(o)
+		}
+	}
+	return o
+}
+
+// WithHeartbeat 指定心跳注释的发送间隔, 默认为 15 秒, 小于等于 0 表示不发送心跳
+func WithHeartbeat(d time.Duration) Option {
+	return func(o *options) {
+		o.heartbeat = d
+	}
+}
+
+// WithRetry 在连接建立时通过 'retry: ' 字段告知客户端重连间隔
+func WithRetry(d time.Duration) Option {
+	return func(o *options) {
+		o.retry = d
+	}
+}
+
+// WithHistory 指定历史记录, 客户端携带 Last-Event-ID 重连时重放其中的事件, Writer.Publish 也会将事件保存到其中
+func WithHistory(h *History) Option {
+	return func(o *options) {
+		o.history = h
+	}
+}
@@ -0,0 +1,259 @@
+// Package sse 实现服务端的 SSE (Server-Sent Events) 推送
+package sse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDisconnected 表示客户端已经断开连接, 或者 Writer 已经关闭
+var ErrDisconnected = errors.New("sse client disconnected")
+
+// Event 表示一个推送的事件
+type Event struct {
+	ID    string        // 事件 ID, 为空时由 History.Publish 生成递增的 ID
+	Event string        // 事件类型, 为空时客户端视为 "message"
+	Retry time.Duration // 建议客户端的重连间隔, 为 0 时不发送
+	Data  any           // 数据。string、[]byte、json.RawMessage 原样发送, 其他类型使用 JSON 序列化
+}
+
+// Writer 封装 http.ResponseWriter, 用于推送 SSE 事件, 并发安全。
+//
+// 一般在 handler 中按照以下方式使用:
+//
+//	w, err := sse.NewWriter(rw, r, sse.WithHistory(h))
+//	if err != nil { ... }
+//	defer w.Close()
+//	_, err = openai.Process(w.Context(), ..., openai.WithContentCallback(func(delta string) {
+//		_ = w.SendData(delta)
+//	}))
+type Writer struct {
+	rw   http.ResponseWriter
+	rc   *http.ResponseController
+	r    *http.Request
+	opts *options
+
+	lock   sync.Mutex
+	err    error
+	closed chan struct{}
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewWriter 写入 SSE 响应头并返回 Writer。如果请求带有 Last-Event-ID 并且配置了 WithHistory,
+// 会先重放历史中该 ID 之后的事件。调用方需要在 handler 返回前调用 Close
+func NewWriter(rw http.ResponseWriter, r *http.Request, opts ...Option) (*Writer, error) {
+	w := &Writer{
+		rw:     rw,
+		rc:     http.NewResponseController(rw),
+		r:      r,
+		opts:   mergeOptions(opts),
+		closed: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		select {
+		case <-r.Context().Done():
+		case <-w.closed:
+		}
+		close(w.done)
+	}()
+
+	h := rw.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no") // 禁止 nginx 缓冲
+	rw.WriteHeader(http.StatusOK)
+	if err := w.rc.Flush(); err != nil {
+		return nil, fmt.Errorf("flush response error (%w)", err)
+	}
+
+	if w.opts.retry > 0 {
+		if err := w.write(fmt.Sprintf("retry: %d\n\n", w.opts.retry.Milliseconds())); err != nil {
+			return nil, err
+		}
+	}
+	if hist := w.opts.history; hist != nil {
+		if id := w.LastEventID(); id != "" {
+			for _, e := range hist.Since(id) {
+				if err := w.write(encode(e)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if w.opts.heartbeat > 0 {
+		w.wg.Add(1)
+		go w.runHeartbeat()
+	}
+	return w, nil
+}
+
+// LastEventID 返回客户端重连时携带的 Last-Event-ID
+func (w *Writer) LastEventID() string {
+	return w.r.Header.Get("Last-Event-ID")
+}
+
+// Context 返回请求的 context, 客户端断开时结束
+func (w *Writer) Context() context.Context {
+	return w.r.Context()
+}
+
+// Done 在客户端断开连接或者 Writer 关闭时关闭
+func (w *Writer) Done() <-chan struct{} {
+	return w.done
+}
+
+// Send 推送一个事件, 不会保存到历史记录中, 客户端断开后返回 ErrDisconnected。
+// 向多个连接广播同一个事件时, 应先调用一次 History.Publish, 再对每个连接调用 Send
+func (w *Writer) Send(e Event) error {
+	data, err := encodeData(e.Data)
+	if err != nil {
+		return err
+	}
+	return w.write(format(e, data))
+}
+
+// Publish 将事件发布到 WithHistory 指定的历史记录中并推送, 未指定历史记录时等同于 Send。
+// 适用于每个事件流只有一个连接的场景, 并发调用时推送的顺序与 ID 的顺序一致
+func (w *Writer) Publish(e Event) error {
+	data, err := encodeData(e.Data)
+	if err != nil {
+		return err
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if hist := w.opts.history; hist != nil {
+		e = hist.Publish(e)
+	}
+	return w.writeLocked(format(e, data))
+}
+
+// SendData 推送一个只有数据的事件
+func (w *Writer) SendData(data any) error {
+	return w.Send(Event{Data: data})
+}
+
+// Comment 推送一条注释, 客户端会忽略注释, 可以用于保活
+func (w *Writer) Comment(s string) error {
+	b := strings.Builder{}
+	for _, line := range strings.Split(s, "\n") {
+		b.WriteString(": ")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	return w.write(b.String())
+}
+
+// Close 停止心跳, 之后的推送返回 ErrDisconnected。不会关闭底层连接, 连接在 handler 返回后结束
+func (w *Writer) Close() {
+	w.lock.Lock()
+	select {
+	case <-w.closed:
+	default:
+		close(w.closed)
+		if w.err == nil {
+			w.err = ErrDisconnected
+		}
+	}
+	w.lock.Unlock()
+	w.wg.Wait()
+}
+
+func (w *Writer) runHeartbeat() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.opts.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.Comment("ping"); err != nil {
+				return
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *Writer) write(s string) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.writeLocked(s)
+}
+
+func (w *Writer) writeLocked(s string) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.r.Context().Err() != nil {
+		w.err = ErrDisconnected
+		return w.err
+	}
+	if _, err := w.rw.Write([]byte(s)); err != nil {
+		w.err = fmt.Errorf("%w (%v)", ErrDisconnected, err)
+		return w.err
+	}
+	if err := w.rc.Flush(); err != nil {
+		w.err = fmt.Errorf("%w (%v)", ErrDisconnected, err)
+		return w.err
+	}
+	return nil
+}
+
+func encodeData(data any) (string, error) {
+	switch v := data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case json.RawMessage:
+		return string(v), nil
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("marshal data error (%w)", err)
+	}
+	return string(b), nil
+}
+
+func encode(e Event) string {
+	data, _ := encodeData(e.Data)
+	return format(e, data)
+}
+
+// format 按照 SSE 格式输出事件, 多行数据拆分为多个 data 字段
+func format(e Event, data string) string {
+	buff := bytes.Buffer{}
+	if e.ID != "" {
+		buff.WriteString("id: " + oneLine(e.ID) + "\n")
+	}
+	if e.Event != "" {
+		buff.WriteString("event: " + oneLine(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		buff.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		buff.WriteString("data: " + line + "\n")
+	}
+	buff.WriteString("\n")
+	return buff.String()
+}
+
+func oneLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
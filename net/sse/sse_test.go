@@ -0,0 +1,172 @@
+package sse_test
+
+import (
+	"context"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Andrew-M-C/go.util/net/http"
+	"github.com/Andrew-M-C/go.util/net/sse"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	cv = convey.Convey
+	so = convey.So
+	eq = convey.ShouldEqual
+
+	isNil = convey.ShouldBeNil
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(m.Run())
+}
+
+type message struct {
+	Text string `json:"text"`
+}
+
+func TestWriter(t *testing.T) {
+	hist := sse.NewHistory(3)
+	disconnected := make(chan error, 1)
+	svr := httptest.NewServer(nethttp.HandlerFunc(func(rw nethttp.ResponseWriter, r *nethttp.Request) {
+		w, err := sse.NewWriter(rw, r,
+			sse.WithHistory(hist), sse.WithRetry(time.Second), sse.WithHeartbeat(10*time.Millisecond),
+		)
+		if err != nil {
+			return
+		}
+		defer w.Close()
+
+		switch r.URL.Path {
+		case "/stream":
+			for _, s := range []string{"a", "b", "c", "d"} {
+				_ = w.Publish(sse.Event{Event: "msg", Data: message{Text: s}})
+			}
+		case "/heartbeat":
+			time.Sleep(50 * time.Millisecond)
+		case "/wait":
+			<-w.Done()
+			disconnected <- w.SendData("late")
+		}
+	}))
+	defer svr.Close()
+
+	cv("推送与 JSON 数据", t, func() {
+		var texts, ids []string
+		for ev, err := range http.SSE[message](context.Background(), svr.URL+"/stream") {
+			so(err, isNil)
+			so(ev.Event, eq, "msg")
+			texts = append(texts, ev.Data.Text)
+			ids = append(ids, ev.ID)
+		}
+		so(strings.Join(texts, ","), eq, "a,b,c,d")
+		so(strings.Join(ids, ","), eq, "1,2,3,4")
+		so(hist.Len(), eq, 3)
+	})
+
+	cv("Last-Event-ID 重放", t, func() {
+		b := get(t, svr.URL+"/heartbeat", "3")
+		so(b, convey.ShouldStartWith, "retry: 1000\n\n")
+		so(b, convey.ShouldContainSubstring, "id: 4\nevent: msg\ndata: {\"text\":\"d\"}\n\n")
+		so(b, convey.ShouldNotContainSubstring, "id: 3\n")
+		so(b, convey.ShouldContainSubstring, ": ping\n\n")
+
+		// 过旧的 ID 重放全部历史
+		b = get(t, svr.URL+"/heartbeat", "1")
+		so(b, convey.ShouldContainSubstring, "id: 2\n")
+	})
+
+	cv("客户端断开", t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		req, _ := nethttp.NewRequestWithContext(ctx, "GET", svr.URL+"/wait", nil)
+		rsp, err := nethttp.DefaultClient.Do(req)
+		so(err, isNil)
+		so(rsp.Header.Get("Content-Type"), eq, "text/event-stream")
+		cancel()
+		_ = rsp.Body.Close()
+
+		select {
+		case err := <-disconnected:
+			so(err, eq, sse.ErrDisconnected)
+		case <-time.After(time.Second):
+			t.Error("disconnect not detected")
+		}
+	})
+}
+
+func TestHistory(t *testing.T) {
+	newWriter := func(h *sse.History) (*sse.Writer, *httptest.ResponseRecorder) {
+		rec := httptest.NewRecorder()
+		w, err := sse.NewWriter(rec, httptest.NewRequest("GET", "/", nil), sse.WithHistory(h), sse.WithHeartbeat(0))
+		so(err, isNil)
+		return w, rec
+	}
+
+	cv("多个连接共享历史记录", t, func() {
+		hist := sse.NewHistory(10)
+		w1, rec1 := newWriter(hist)
+		defer w1.Close()
+		w2, rec2 := newWriter(hist)
+		defer w2.Close()
+
+		// 广播的事件只保存一次
+		e := hist.Publish(sse.Event{Data: "x"})
+		so(e.ID, eq, "1")
+		so(w1.Send(e), isNil)
+		so(w2.Send(e), isNil)
+		so(hist.Len(), eq, 1)
+		so(rec1.Body.String(), eq, "id: 1\ndata: x\n\n")
+		so(rec2.Body.String(), eq, "id: 1\ndata: x\n\n")
+
+		// Send 不保存到历史记录
+		so(w1.SendData("y"), isNil)
+		so(hist.Len(), eq, 1)
+	})
+
+	cv("并发发布时 ID 有序", t, func() {
+		hist := sse.NewHistory(100)
+		w, rec := newWriter(hist)
+		defer w.Close()
+
+		wg := sync.WaitGroup{}
+		for range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = w.Publish(sse.Event{Data: "x"})
+			}()
+		}
+		wg.Wait()
+
+		var ids []string
+		for _, line := range strings.Split(rec.Body.String(), "\n") {
+			if id, ok := strings.CutPrefix(line, "id: "); ok {
+				ids = append(ids, id)
+			}
+		}
+		so(len(ids), eq, 50)
+		for i, id := range ids {
+			so(id, eq, strconv.Itoa(i+1))
+		}
+	})
+}
+
+func get(t *testing.T, u, lastEventID string) string {
+	req, _ := nethttp.NewRequest("GET", u, nil)
+	req.Header.Set("Last-Event-ID", lastEventID)
+	rsp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rsp.Body.Close()
+	b, _ := io.ReadAll(rsp.Body)
+	return string(b)
+}
@@ -0,0 +1,511 @@
+package url
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MarshalValues 将结构体 (或其指针) 编码为 url.Values, 也支持 url.Values、map[string][]string 和
+// map[string]string。结构体字段使用 url tag 指定参数名和选项, 如 `url:"name,omitempty,comma"`:
+//
+//   - 参数名为空时使用字段名, "-" 表示忽略该字段
+//   - omitempty: 零值不编码
+//   - comma: 切片以逗号连接为一个参数, 默认编码为多个同名参数
+//   - unix / unixmilli: time.Time 编码为秒 / 毫秒时间戳, 默认为 RFC 3339 格式, 也可以使用 layout tag 指定格式
+//
+// 嵌套的结构体和 map 以 "父参数名.子参数名" 编码, 匿名嵌入的结构体展开到上一层。
+// 实现了 encoding.TextMarshaler 的类型使用 MarshalText 的结果
+func MarshalValues(v any) (url.Values, error) {
+	res := url.Values{}
+	switch q := v.(type) {
+	case nil:
+		return res, nil
+	case url.Values:
+		return addValues(res, q), nil
+	case map[string][]string:
+		return addValues(res, q), nil
+	case map[string]string:
+		for k, s := range q {
+			res.Add(k, s)
+		}
+		return res, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return res, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported query type %T", v)
+	}
+	if err := encodeStruct(res, "", rv); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// UnmarshalValues 将 url.Values 解析到 v 指向的结构体中, tag 规则与 MarshalValues 相同。
+// 参数不存在的字段保持不变; comma 选项的切片同时支持逗号分隔和多个同名参数
+func UnmarshalValues(q url.Values, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("UnmarshalValues requires a non-nil pointer")
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("UnmarshalValues requires a pointer to struct, got %T", v)
+	}
+	return decodeStruct(q, "", rv)
+}
+
+func addValues(dst url.Values, src map[string][]string) url.Values {
+	for k, values := range src {
+		for _, s := range values {
+			dst.Add(k, s)
+		}
+	}
+	return dst
+}
+
+// -------- 字段解析 --------
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+type fieldInfo struct {
+	name      string
+	explicit  bool // tag 中指定了参数名
+	omitempty bool
+	comma     bool
+	timeFmt   string // "unix"、"unixmilli" 或 time layout
+}
+
+func parseField(sf reflect.StructField) (fieldInfo, bool) {
+	tag := sf.Tag.Get("url")
+	if tag == "-" || (!sf.IsExported() && !sf.Anonymous) {
+		return fieldInfo{}, false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	info := fieldInfo{name: name, explicit: name != "", timeFmt: sf.Tag.Get("layout")}
+	if name == "" {
+		info.name = sf.Name
+	}
+	for _, opt := range strings.Split(opts, ",") {
+		switch opt {
+		case "omitempty":
+			info.omitempty = true
+		case "comma":
+			info.comma = true
+		case "unix", "unixmilli":
+			info.timeFmt = opt
+		}
+	}
+	return info, true
+}
+
+// embeddedStruct 判断匿名字段是否需要展开到上一层
+func embeddedStruct(sf reflect.StructField, info fieldInfo) bool {
+	if !sf.Anonymous || info.explicit {
+		return false
+	}
+	t := sf.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isScalarType(t)
+}
+
+func isScalarType(t reflect.Type) bool {
+	if t == timeType || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	}
+	return false
+}
+
+// -------- 编码 --------
+
+func encodeStruct(q url.Values, prefix string, rv reflect.Value) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		info, ok := parseField(sf)
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+		if embeddedStruct(sf, info) {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if err := encodeStruct(q, prefix, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if info.omitempty && fv.IsZero() {
+			continue
+		}
+		if err := encodeValue(q, prefix+info.name, fv, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeValue(q url.Values, name string, v reflect.Value, info fieldInfo) error {
+	v, ok := indirect(v)
+	if !ok {
+		return nil
+	}
+	if s, ok, err := formatScalar(v, info); ok {
+		if err != nil {
+			return fmt.Errorf("encode query '%s' error (%w)", name, err)
+		}
+		q.Add(name, s)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		values, err := formatList(v, info)
+		if err != nil {
+			return fmt.Errorf("encode query '%s' error (%w)", name, err)
+		}
+		if info.comma {
+			if len(values) > 0 {
+				q.Add(name, strings.Join(values, ","))
+			}
+			return nil
+		}
+		q[name] = append(q[name], values...)
+		return nil
+
+	case reflect.Struct:
+		return encodeStruct(q, name+".", v)
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			elem := v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key()))
+			if err := encodeValue(q, name+"."+k, elem, info); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported type %v of query '%s'", v.Type(), name)
+}
+
+// indirect 解引用指针和接口, nil 时返回 false
+func indirect(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, v.IsValid()
+}
+
+func formatList(v reflect.Value, info fieldInfo) ([]string, error) {
+	res := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elem, ok := indirect(v.Index(i))
+		if !ok {
+			continue
+		}
+		s, ok, err := formatScalar(elem, info)
+		if !ok {
+			return nil, fmt.Errorf("unsupported element type %v", elem.Type())
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+// formatScalar 将单个值格式化为字符串, 不是单值类型时第二个返回值为 false
+func formatScalar(v reflect.Value, info fieldInfo) (string, bool, error) {
+	if v.Type() == timeType {
+		return formatTime(v.Interface().(time.Time), info.timeFmt), true, nil
+	}
+	if v.CanInterface() {
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			b, err := m.MarshalText()
+			return string(b), true, err
+		}
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), true, nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func formatTime(t time.Time, format string) string {
+	switch format {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unixmilli":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case "":
+		return t.Format(time.RFC3339)
+	}
+	return t.Format(format)
+}
+
+// -------- 解码 --------
+
+func decodeStruct(q url.Values, prefix string, rv reflect.Value) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		info, ok := parseField(sf)
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+		if embeddedStruct(sf, info) {
+			if fv.Kind() == reflect.Pointer {
+				if !fv.CanSet() {
+					continue
+				}
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if err := decodeStruct(q, prefix, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if !fv.CanSet() {
+			continue
+		}
+		if err := decodeValue(q, prefix+info.name, fv, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeValue(q url.Values, name string, v reflect.Value, info fieldInfo) error {
+	t := v.Type()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if isScalarType(t) {
+		values := q[name]
+		if len(values) == 0 {
+			return nil
+		}
+		if err := setScalar(v, values[0], info); err != nil {
+			return fmt.Errorf("decode query '%s' error (%w)", name, err)
+		}
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		values, exist := q[name]
+		if !exist {
+			return nil
+		}
+		if info.comma {
+			var split []string
+			for _, s := range values {
+				if s != "" {
+					split = append(split, strings.Split(s, ",")...)
+				}
+			}
+			values = split
+		}
+		target := allocate(v)
+		if t.Kind() == reflect.Slice {
+			target.Set(reflect.MakeSlice(t, len(values), len(values)))
+		} else {
+			target.SetZero()
+		}
+		for i, s := range values {
+			if i >= target.Len() {
+				break
+			}
+			if err := setScalar(target.Index(i), s, info); err != nil {
+				return fmt.Errorf("decode query '%s' error (%w)", name, err)
+			}
+		}
+		return nil
+
+	case reflect.Struct:
+		if !hasPrefix(q, name+".") {
+			return nil
+		}
+		return decodeStruct(q, name+".", allocate(v))
+
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String || !isScalarType(t.Elem()) {
+			break
+		}
+		prefix := name + "."
+		if !hasPrefix(q, prefix) {
+			return nil
+		}
+		target := allocate(v)
+		if target.IsNil() {
+			target.Set(reflect.MakeMap(t))
+		}
+		for k, values := range q {
+			if !strings.HasPrefix(k, prefix) || len(values) == 0 {
+				continue
+			}
+			elem := reflect.New(t.Elem()).Elem()
+			if err := setScalar(elem, values[0], info); err != nil {
+				return fmt.Errorf("decode query '%s' error (%w)", k, err)
+			}
+			target.SetMapIndex(reflect.ValueOf(strings.TrimPrefix(k, prefix)).Convert(t.Key()), elem)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported type %v of query '%s'", v.Type(), name)
+}
+
+// allocate 为 nil 指针分配内存, 返回最终指向的值
+func allocate(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func hasPrefix(q url.Values, prefix string) bool {
+	for k := range q {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func setScalar(v reflect.Value, s string, info fieldInfo) error {
+	v = allocate(v)
+	if v.Type() == timeType {
+		t, err := parseTime(s, info.timeFmt)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type %v", v.Type())
+		}
+		v.SetBytes([]byte(s))
+	default:
+		return fmt.Errorf("unsupported type %v", v.Type())
+	}
+	return nil
+}
+
+func parseTime(s, format string) (time.Time, error) {
+	switch format {
+	case "unix", "unixmilli":
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if format == "unix" {
+			return time.Unix(i, 0), nil
+		}
+		return time.UnixMilli(i), nil
+	case "":
+		return time.Parse(time.RFC3339, s)
+	}
+	return time.Parse(format, s)
+}
@@ -0,0 +1,92 @@
+package url_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	urlutil "github.com/Andrew-M-C/go.util/net/url"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+type page struct {
+	Page int `url:"page,omitempty"`
+	Size int `url:"size,omitempty"`
+}
+
+type filter struct {
+	page
+
+	Keyword  string            `url:"q"`
+	Tags     []string          `url:"tag"`
+	IDs      []int64           `url:"ids,comma"`
+	Since    time.Time         `url:"since,unix"`
+	Date     time.Time         `url:"date,omitempty" layout:"2006-01-02"`
+	Enabled  *bool             `url:"enabled"`
+	User     *user             `url:"user"`
+	Extra    map[string]string `url:"extra"`
+	Ignored  string            `url:"-"`
+	Optional string            `url:",omitempty"`
+	private  string
+}
+
+type user struct {
+	Name string `url:"name"`
+	Age  uint8  `url:"age,omitempty"`
+}
+
+func TestValues(t *testing.T) {
+	enabled := true
+	since := time.Unix(1700000000, 0)
+	f := filter{
+		page:    page{Page: 2},
+		Keyword: "中文 a&b",
+		Tags:    []string{"go", "http"},
+		IDs:     []int64{1, 2, 3},
+		Since:   since,
+		Date:    time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Enabled: &enabled,
+		User:    &user{Name: "Tom"},
+		Extra:   map[string]string{"b": "2", "a": "1"},
+		Ignored: "ignored",
+		private: "private",
+	}
+
+	cv("编码", t, func() {
+		q, err := urlutil.MarshalValues(&f)
+		so(err, isNil)
+		so(q.Encode(), eq, "date=2024-05-01&enabled=true&extra.a=1&extra.b=2&ids=1%2C2%2C3&page=2"+
+			"&q=%E4%B8%AD%E6%96%87+a%26b&since=1700000000&tag=go&tag=http&user.name=Tom")
+
+		q, err = urlutil.MarshalValues(map[string]string{"a": "1"})
+		so(err, isNil)
+		so(q.Encode(), eq, "a=1")
+
+		_, err = urlutil.MarshalValues(1)
+		so(err, convey.ShouldBeError)
+	})
+
+	cv("解码", t, func() {
+		q, err := urlutil.MarshalValues(f)
+		so(err, isNil)
+		q.Add("ids", "4")
+
+		var res filter
+		so(urlutil.UnmarshalValues(q, &res), isNil)
+		so(res.Page, eq, 2)
+		so(res.Size, eq, 0)
+		so(res.Keyword, eq, f.Keyword)
+		so(res.Tags, convey.ShouldResemble, f.Tags)
+		so(res.IDs, convey.ShouldResemble, []int64{1, 2, 3, 4})
+		so(res.Since.Equal(since), eq, true)
+		so(res.Date.Equal(f.Date), eq, true)
+		so(*res.Enabled, eq, true)
+		so(res.User.Name, eq, "Tom")
+		so(res.Extra, convey.ShouldResemble, f.Extra)
+		so(res.Ignored, eq, "")
+
+		err = urlutil.UnmarshalValues(url.Values{"page": {"abc"}}, &res)
+		so(err, convey.ShouldBeError)
+		so(urlutil.UnmarshalValues(url.Values{}, res), convey.ShouldBeError)
+	})
+}
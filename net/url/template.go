@@ -0,0 +1,393 @@
+package url
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Template 表示 RFC 6570 URI 模板, 支持全部 4 个级别的语法, 如 "/users/{id}{?page,size}"、
+// "{+base}/search{?q*}"、"/files{/path*}"
+type Template struct {
+	raw   string
+	parts []templatePart
+}
+
+type templatePart struct {
+	literal string
+	expr    *templateExpr
+}
+
+type templateExpr struct {
+	op       byte
+	varspecs []varspec
+}
+
+type varspec struct {
+	name    string
+	explode bool
+	prefix  int
+}
+
+// ParseTemplate 解析 URI 模板
+func ParseTemplate(s string) (*Template, error) {
+	t := &Template{raw: s}
+	rest := s
+	for rest != "" {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			t.parts = append(t.parts, templatePart{literal: rest})
+			break
+		}
+		if start > 0 {
+			t.parts = append(t.parts, templatePart{literal: rest[:start]})
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("invalid URI template '%s', unclosed expression", s)
+		}
+		expr, err := parseExpr(rest[start+1 : start+end])
+		if err != nil {
+			return nil, fmt.Errorf("invalid URI template '%s' (%w)", s, err)
+		}
+		t.parts = append(t.parts, templatePart{expr: expr})
+		rest = rest[start+end+1:]
+	}
+	return t, nil
+}
+
+// MustParseTemplate 与 ParseTemplate 相同, 但解析失败时 panic, 用于初始化全局变量
+func MustParseTemplate(s string) *Template {
+	t, err := ParseTemplate(s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// ExpandTemplate 解析并展开 URI 模板, 参见 Template.Expand
+func ExpandTemplate(tmpl string, vars any) (string, error) {
+	t, err := ParseTemplate(tmpl)
+	if err != nil {
+		return "", err
+	}
+	return t.Expand(vars)
+}
+
+func parseExpr(s string) (*templateExpr, error) {
+	if s == "" {
+		return nil, fmt.Errorf("empty expression")
+	}
+	expr := &templateExpr{}
+	switch s[0] {
+	case '+', '#', '.', '/', ';', '?', '&':
+		expr.op, s = s[0], s[1:]
+	case '=', ',', '!', '@', '|':
+		return nil, fmt.Errorf("reserved operator '%c'", s[0])
+	}
+	for _, spec := range strings.Split(s, ",") {
+		vs := varspec{name: spec}
+		if name, ok := strings.CutSuffix(spec, "*"); ok {
+			vs.name, vs.explode = name, true
+		} else if name, prefix, ok := strings.Cut(spec, ":"); ok {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n <= 0 || n >= 10000 {
+				return nil, fmt.Errorf("invalid prefix modifier '%s'", spec)
+			}
+			vs.name, vs.prefix = name, n
+		}
+		if !validVarName(vs.name) {
+			return nil, fmt.Errorf("invalid variable name '%s'", vs.name)
+		}
+		expr.varspecs = append(expr.varspecs, vs)
+	}
+	return expr, nil
+}
+
+func validVarName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range []byte(name) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_', c == '.', c == '%':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// String 返回原始模板
+func (t *Template) String() string {
+	return t.raw
+}
+
+// Names 按出现顺序返回模板中的变量名, 不重复
+func (t *Template) Names() []string {
+	var res []string
+	for _, p := range t.parts {
+		if p.expr == nil {
+			continue
+		}
+		for _, vs := range p.expr.varspecs {
+			if !slices.Contains(res, vs.name) {
+				res = append(res, vs.name)
+			}
+		}
+	}
+	return res
+}
+
+// Expand 使用 vars 展开模板。vars 可以是 map[string]any、map[string]string、url.Values 或结构体 (字段名规则
+// 与 MarshalValues 相同)。变量的值可以是字符串、数字等单值, 切片 (列表) 或 map / 结构体 (键值对);
+// 不存在、nil 或空的列表视为未定义, 按照 RFC 6570 忽略
+func (t *Template) Expand(vars any) (string, error) {
+	lookup, err := templateVars(vars)
+	if err != nil {
+		return "", err
+	}
+	b := strings.Builder{}
+	for _, p := range t.parts {
+		if p.expr == nil {
+			b.WriteString(p.literal)
+			continue
+		}
+		if err := p.expr.expand(&b, lookup); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// -------- 变量 --------
+
+type tplValue struct {
+	str   string
+	list  []string
+	pairs [][2]string
+	kind  reflect.Kind // String、Slice 或 Map
+}
+
+func templateVars(vars any) (func(string) (tplValue, bool, error), error) {
+	switch m := vars.(type) {
+	case nil:
+		return func(string) (tplValue, bool, error) { return tplValue{}, false, nil }, nil
+	case map[string]any:
+		return func(name string) (tplValue, bool, error) { return toTplValue(reflect.ValueOf(m[name])) }, nil
+	case map[string]string:
+		return func(name string) (tplValue, bool, error) {
+			s, exist := m[name]
+			return tplValue{str: s, kind: reflect.String}, exist, nil
+		}, nil
+	case url.Values:
+		return func(name string) (tplValue, bool, error) {
+			values := m[name]
+			if len(values) == 1 {
+				return tplValue{str: values[0], kind: reflect.String}, true, nil
+			}
+			return tplValue{list: values, kind: reflect.Slice}, len(values) > 0, nil
+		}, nil
+	}
+
+	rv, ok := indirect(reflect.ValueOf(vars))
+	if !ok || rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported template variables type %T", vars)
+	}
+	fields := map[string]reflect.Value{}
+	collectFields(fields, rv)
+	return func(name string) (tplValue, bool, error) {
+		v, exist := fields[name]
+		if !exist {
+			return tplValue{}, false, nil
+		}
+		return toTplValue(v)
+	}, nil
+}
+
+func collectFields(fields map[string]reflect.Value, rv reflect.Value) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		info, ok := parseField(sf)
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+		if embeddedStruct(sf, info) {
+			if fv, ok := indirect(fv); ok {
+				collectFields(fields, fv)
+			}
+			continue
+		}
+		if !sf.IsExported() || (info.omitempty && fv.IsZero()) {
+			continue
+		}
+		fields[info.name] = fv
+	}
+}
+
+func toTplValue(v reflect.Value) (tplValue, bool, error) {
+	v, ok := indirect(v)
+	if !ok {
+		return tplValue{}, false, nil
+	}
+	if s, ok, err := formatScalar(v, fieldInfo{}); ok {
+		return tplValue{str: s, kind: reflect.String}, err == nil, err
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		list, err := formatList(v, fieldInfo{})
+		return tplValue{list: list, kind: reflect.Slice}, err == nil && len(list) > 0, err
+	case reflect.Map, reflect.Struct:
+		q := url.Values{}
+		if err := encodeValue(q, "", v, fieldInfo{}); err != nil {
+			return tplValue{}, false, err
+		}
+		keys := make([]string, 0, len(q))
+		for k := range q {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		res := tplValue{kind: reflect.Map}
+		for _, k := range keys {
+			for _, s := range q[k] {
+				res.pairs = append(res.pairs, [2]string{strings.TrimPrefix(k, "."), s})
+			}
+		}
+		return res, len(res.pairs) > 0, nil
+	}
+	return tplValue{}, false, fmt.Errorf("unsupported template variable type %v", v.Type())
+}
+
+// -------- 展开 --------
+
+type opSpec struct {
+	first         string
+	sep           string
+	named         bool
+	ifEmpty       string
+	allowReserved bool
+}
+
+func getOpSpec(op byte) opSpec {
+	switch op {
+	case '+':
+		return opSpec{sep: ",", allowReserved: true}
+	case '#':
+		return opSpec{first: "#", sep: ",", allowReserved: true}
+	case '.':
+		return opSpec{first: ".", sep: "."}
+	case '/':
+		return opSpec{first: "/", sep: "/"}
+	case ';':
+		return opSpec{first: ";", sep: ";", named: true}
+	case '?':
+		return opSpec{first: "?", sep: "&", named: true, ifEmpty: "="}
+	case '&':
+		return opSpec{first: "&", sep: "&", named: true, ifEmpty: "="}
+	}
+	return opSpec{sep: ","}
+}
+
+func (e *templateExpr) expand(b *strings.Builder, lookup func(string) (tplValue, bool, error)) error {
+	spec := getOpSpec(e.op)
+	first := true
+	for _, vs := range e.varspecs {
+		v, defined, err := lookup(vs.name)
+		if err != nil {
+			return fmt.Errorf("expand variable '%s' error (%w)", vs.name, err)
+		}
+		if !defined {
+			continue
+		}
+		if first {
+			b.WriteString(spec.first)
+			first = false
+		} else {
+			b.WriteString(spec.sep)
+		}
+		enc := func(s string) string { return escapeTemplate(s, spec.allowReserved) }
+
+		switch {
+		case v.kind == reflect.String:
+			s := v.str
+			if vs.prefix > 0 {
+				if r := []rune(s); len(r) > vs.prefix {
+					s = string(r[:vs.prefix])
+				}
+			}
+			writeNamed(b, spec, vs.name, enc(s))
+
+		case !vs.explode:
+			var items []string
+			for _, s := range v.list {
+				items = append(items, enc(s))
+			}
+			for _, kv := range v.pairs {
+				items = append(items, enc(kv[0]), enc(kv[1]))
+			}
+			writeNamed(b, spec, vs.name, strings.Join(items, ","))
+
+		default:
+			var items []string
+			for _, s := range v.list {
+				item := strings.Builder{}
+				writeNamed(&item, spec, vs.name, enc(s))
+				items = append(items, item.String())
+			}
+			for _, kv := range v.pairs {
+				item := strings.Builder{}
+				writeNamed(&item, opSpec{named: true, ifEmpty: "="}, enc(kv[0]), enc(kv[1]))
+				items = append(items, item.String())
+			}
+			b.WriteString(strings.Join(items, spec.sep))
+		}
+	}
+	return nil
+}
+
+func writeNamed(b *strings.Builder, spec opSpec, name, value string) {
+	if !spec.named {
+		b.WriteString(value)
+		return
+	}
+	b.WriteString(name)
+	if value == "" {
+		b.WriteString(spec.ifEmpty)
+		return
+	}
+	b.WriteByte('=')
+	b.WriteString(value)
+}
+
+// escapeTemplate 按照 RFC 6570 转义: 默认只保留 unreserved 字符; allowReserved 时还保留 reserved 字符和
+// 已经转义的 %XX
+func escapeTemplate(s string, allowReserved bool) string {
+	const hex = "0123456789ABCDEF"
+	b := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', strings.IndexByte("-._~", c) >= 0:
+			b.WriteByte(c)
+		case allowReserved && strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0:
+			b.WriteByte(c)
+		case allowReserved && c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			b.WriteString(s[i : i+3])
+			i += 2
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0x0F])
+		}
+	}
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
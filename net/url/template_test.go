@@ -0,0 +1,71 @@
+package url_test
+
+import (
+	"testing"
+
+	urlutil "github.com/Andrew-M-C/go.util/net/url"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestTemplate(t *testing.T) {
+	cv("RFC 6570 示例", t, func() {
+		vars := map[string]any{
+			"var":   "value",
+			"hello": "Hello World!",
+			"path":  "/foo/bar",
+			"empty": "",
+			"list":  []string{"red", "green", "blue"},
+			"keys":  map[string]string{"semi": ";", "dot": ".", "comma": ","},
+			"x":     1024,
+			"y":     768,
+			"undef": nil,
+		}
+		cases := map[string]string{
+			"{var}":           "value",
+			"{hello}":         "Hello%20World%21",
+			"{+path}/here":    "/foo/bar/here",
+			"{#path,x}/here":  "#/foo/bar,1024/here",
+			"X{.var}":         "X.value",
+			"{/var,x}/here":   "/value/1024/here",
+			"{;x,y,empty}":    ";x=1024;y=768;empty",
+			"{?x,y,empty}":    "?x=1024&y=768&empty=",
+			"?fixed=yes{&x}":  "?fixed=yes&x=1024",
+			"{var:3}":         "val",
+			"{list}":          "red,green,blue",
+			"{list*}":         "red,green,blue",
+			"{/list*,path:4}": "/red/green/blue/%2Ffoo",
+			"{keys}":          "comma,%2C,dot,.,semi,%3B",
+			"{keys*}":         "comma=%2C,dot=.,semi=%3B",
+			"{?list*}":        "?list=red&list=green&list=blue",
+			"{?keys*}":        "?comma=%2C&dot=.&semi=%3B",
+			"{undef}{?undef}": "",
+			"{+hello}":        "Hello%20World!",
+			"/a{?undef,var}":  "/a?var=value",
+		}
+		for tmpl, expect := range cases {
+			s, err := urlutil.ExpandTemplate(tmpl, vars)
+			so(err, isNil)
+			so(s, eq, expect)
+		}
+	})
+
+	cv("结构体变量", t, func() {
+		tmpl := urlutil.MustParseTemplate("/users/{id}{?page,size}")
+		so(tmpl.Names(), convey.ShouldResemble, []string{"id", "page", "size"})
+
+		s, err := tmpl.Expand(struct {
+			ID   int64 `url:"id"`
+			Page int   `url:"page,omitempty"`
+			Size int   `url:"size,omitempty"`
+		}{ID: 12, Size: 20})
+		so(err, isNil)
+		so(s, eq, "/users/12?size=20")
+	})
+
+	cv("非法模板", t, func() {
+		for _, tmpl := range []string{"/a/{id", "{}", "{=a}", "{a:0}", "{a b}"} {
+			_, err := urlutil.ParseTemplate(tmpl)
+			so(err, convey.ShouldBeError)
+		}
+	})
+}
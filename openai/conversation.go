@@ -0,0 +1,465 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/Andrew-M-C/go.util/openai/tiktoken"
+	"github.com/sashabaranov/go-openai"
+)
+
+// Conversation 管理多轮对话的上下文。每次请求前按照 ModelConfig.MaxContextTokens 的预算截断历史消息,
+// 请求完成后保存新增的消息。截断规则:
+//
+//   - system prompt 始终保留
+//   - 以轮次 (从一条 user 消息开始, 到下一条 user 消息之前) 为单位从最早的开始丢弃
+//   - 仅剩最后一轮仍然超出预算时, 丢弃其中较早的工具调用, assistant 的工具调用请求和对应的 tool 响应总是同时丢弃
+//
+// 使用 MemorySummarize 策略时, 被丢弃的轮次会先调用模型总结为摘要, 以 system 消息的形式放在 system prompt 之后
+type Conversation struct {
+	lock    sync.Mutex
+	config  ModelConfig
+	opts    *conversationOptions
+	system  []openai.ChatCompletionMessage
+	history []openai.ChatCompletionMessage
+	summary string
+}
+
+// MemoryStrategy 表示超出 token 预算时的处理策略
+type MemoryStrategy int
+
+const (
+	// MemorySlidingWindow 滑动窗口, 直接丢弃最早的消息, 但完整的历史仍然保留在 History 中
+	MemorySlidingWindow MemoryStrategy = iota
+	// MemorySummarize 调用模型将最早的消息总结为摘要, 被总结的消息从历史中删除
+	MemorySummarize
+)
+
+// NewConversation 新建对话。config 同时用于对话请求和 token 预算, MaxContextTokens 为 0 时不截断
+func NewConversation(config ModelConfig, opts ...ConversationOption) *Conversation {
+	o := &conversationOptions{
+		debugf:        func(string, ...any) {},
+		summaryPrompt: defaultSummaryPrompt,
+	}
+	for _, f := range opts {
+		if f != nil {
+			f(o)
+		}
+	}
+	if o.counter == nil {
+		o.counter = tokenCounterForModel(config.Model, o.debugf)
+	}
+	return &Conversation{config: config, opts: o}
+}
+
+// SetSystemPrompt 设置 system prompt, 空字符串表示清除
+func (c *Conversation) SetSystemPrompt(prompt string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.system = nil
+	if prompt != "" {
+		c.system = []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleSystem, Content: prompt}}
+	}
+}
+
+// Append 直接向历史中追加消息。system 消息会作为 system prompt 保存, 不参与截断
+func (c *Conversation) Append(messages ...openai.ChatCompletionMessage) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.append(messages)
+}
+
+func (c *Conversation) append(messages []openai.ChatCompletionMessage) {
+	for _, m := range messages {
+		if m.Role == openai.ChatMessageRoleSystem {
+			c.system = append(c.system, m)
+		} else {
+			c.history = append(c.history, m)
+		}
+	}
+}
+
+// History 返回当前保存的历史消息 (不含 system prompt 和摘要)
+func (c *Conversation) History() []openai.ChatCompletionMessage {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]openai.ChatCompletionMessage(nil), c.history...)
+}
+
+// Summary 返回当前的摘要, 只有 MemorySummarize 策略才会产生
+func (c *Conversation) Summary() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.summary
+}
+
+// Reset 清空历史和摘要, 保留 system prompt
+func (c *Conversation) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.history, c.summary = nil, ""
+}
+
+// Messages 按照 token 预算生成本次需要发送的消息
+func (c *Conversation) Messages(ctx context.Context) ([]openai.ChatCompletionMessage, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.messages(ctx)
+}
+
+// Store 保存一次请求中新增的消息, sent 为发送给 Process 的消息, 即 Messages 的返回值
+func (c *Conversation) Store(sent []openai.ChatCompletionMessage, rsp ProcessResponse) {
+	if len(rsp.Messages) <= len(sent) {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.history = append(c.history, rsp.Messages[len(sent):]...)
+}
+
+// Process 追加 messages (一般是一条 user 消息) 之后调用包级的 Process 函数, 并保存模型返回的消息。
+// 返回值与 Process 相同, 其中的 Messages 包含本次实际发送的消息。
+//
+// 请求失败时撤销本次追加的消息, 避免历史中出现没有回复的 user 消息; 但 ErrMaxIterations 时保留已经产生的消息
+func (c *Conversation) Process(
+	ctx context.Context, messages []openai.ChatCompletionMessage, options ...Option,
+) (ProcessResponse, error) {
+	c.lock.Lock()
+	c.append(messages)
+	sent, err := c.messages(ctx)
+	c.lock.Unlock()
+	if err != nil {
+		c.rollback(messages)
+		return ProcessResponse{}, err
+	}
+
+	rsp, err := Process(ctx, c.config, sent, options...)
+	if err != nil {
+		if errors.Is(err, ErrMaxIterations) {
+			c.Store(sent, rsp)
+		} else {
+			c.rollback(messages)
+		}
+		return rsp, err
+	}
+	c.Store(sent, rsp)
+	return rsp, nil
+}
+
+// rollback 撤销 append 追加的消息。追加的消息总是在末尾, 总结只会移除更早的对话轮次
+func (c *Conversation) rollback(messages []openai.ChatCompletionMessage) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, m := range slices.Backward(messages) {
+		if m.Role == openai.ChatMessageRoleSystem {
+			if n := len(c.system); n > 0 {
+				c.system = c.system[:n-1]
+			}
+		} else if n := len(c.history); n > 0 {
+			c.history = c.history[:n-1]
+		}
+	}
+}
+
+func (c *Conversation) messages(ctx context.Context) ([]openai.ChatCompletionMessage, error) {
+	budget := c.config.MaxContextTokens - c.opts.reserved
+	if c.config.MaxContextTokens <= 0 {
+		return c.pack(c.history), nil
+	}
+
+	turns := splitTurns(c.history)
+	kept := turns
+	for len(kept) > 1 && c.opts.counter(c.pack(flatten(kept))) > budget {
+		kept = kept[1:]
+	}
+
+	if dropped := len(turns) - len(kept); dropped > 0 {
+		c.opts.debugf("对话上下文超出预算 %d, 丢弃最早的 %d 轮对话", budget, dropped)
+		if c.opts.strategy == MemorySummarize {
+			// 多总结一些, 避免每一轮都需要总结
+			for len(kept) > 1 && c.opts.counter(c.pack(flatten(kept))) > budget/2 {
+				kept = kept[1:]
+			}
+			if err := c.summarize(ctx, flatten(turns[:len(turns)-len(kept)])); err != nil {
+				return nil, err
+			}
+			c.history = flatten(kept)
+		}
+	}
+
+	res := c.pack(flatten(kept))
+	if len(kept) == 0 || c.opts.counter(res) <= budget {
+		return res, nil
+	}
+
+	// 只剩最后一轮仍然超出, 丢弃其中较早的工具调用
+	last := kept[len(kept)-1]
+	for {
+		next, ok := dropFirstToolCall(last)
+		if !ok {
+			break
+		}
+		last = next
+		if res = c.pack(last); c.opts.counter(res) <= budget {
+			return res, nil
+		}
+	}
+	c.opts.debugf("最后一轮对话仍然超出预算 %d, 原样发送", budget)
+	return c.pack(last), nil
+}
+
+// pack 在消息前加上 system prompt 和摘要
+func (c *Conversation) pack(history []openai.ChatCompletionMessage) []openai.ChatCompletionMessage {
+	res := make([]openai.ChatCompletionMessage, 0, len(c.system)+1+len(history))
+	res = append(res, c.system...)
+	if c.summary != "" {
+		res = append(res, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: summaryMessagePrefix + c.summary,
+		})
+	}
+	return append(res, history...)
+}
+
+const (
+	summaryMessagePrefix = "以下是之前对话的摘要:\n"
+
+	defaultSummaryPrompt = "你是一个对话摘要助手。请将以下对话内容总结为简洁的摘要, 保留关键事实、用户的偏好和要求、" +
+		"工具调用得到的重要结果以及尚未完成的任务。直接输出摘要内容, 不要添加额外的说明。"
+)
+
+func (c *Conversation) summarize(ctx context.Context, dropped []openai.ChatCompletionMessage) error {
+	b := strings.Builder{}
+	if c.summary != "" {
+		b.WriteString("[之前的摘要]\n")
+		b.WriteString(c.summary)
+		b.WriteString("\n\n[新的对话]\n")
+	}
+	for _, m := range dropped {
+		writeTranscript(&b, m)
+	}
+
+	config := c.config
+	if c.opts.summaryConfig != nil {
+		config = *c.opts.summaryConfig
+	}
+	rsp, err := Process(ctx, config, []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: c.opts.summaryPrompt},
+		{Role: openai.ChatMessageRoleUser, Content: b.String()},
+	}, WithDebugger(c.opts.debugf))
+	if err != nil {
+		return fmt.Errorf("总结对话失败 (%w)", err)
+	}
+	summary := strings.TrimSpace(rsp.Messages[len(rsp.Messages)-1].Content)
+	if summary == "" {
+		return errors.New("总结对话失败, 模型没有返回内容")
+	}
+	c.opts.debugf("总结了 %d 条消息, 摘要: %s", len(dropped), summary)
+	c.summary = summary
+	return nil
+}
+
+func writeTranscript(b *strings.Builder, m openai.ChatCompletionMessage) {
+	content := messageText(m)
+	switch {
+	case m.Role == openai.ChatMessageRoleTool:
+		fmt.Fprintf(b, "tool 返回: %s\n", content)
+	case len(m.ToolCalls) > 0:
+		if content != "" {
+			fmt.Fprintf(b, "%s: %s\n", m.Role, content)
+		}
+		for _, tc := range m.ToolCalls {
+			fmt.Fprintf(b, "%s 调用工具: %s(%s)\n", m.Role, tc.Function.Name, tc.Function.Arguments)
+		}
+	default:
+		fmt.Fprintf(b, "%s: %s\n", m.Role, content)
+	}
+}
+
+// messageText 返回消息中的文本内容
+func messageText(m openai.ChatCompletionMessage) string {
+	if len(m.MultiContent) == 0 {
+		return m.Content
+	}
+	var parts []string
+	for _, p := range m.MultiContent {
+		if p.Type == openai.ChatMessagePartTypeText {
+			parts = append(parts, p.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// -------- 消息分组 --------
+
+// splitTurns 将消息按轮次分组, 每一轮从一条 user 消息开始
+func splitTurns(messages []openai.ChatCompletionMessage) [][]openai.ChatCompletionMessage {
+	var res [][]openai.ChatCompletionMessage
+	for i, m := range messages {
+		if i == 0 || m.Role == openai.ChatMessageRoleUser {
+			res = append(res, nil)
+		}
+		res[len(res)-1] = append(res[len(res)-1], m)
+	}
+	return res
+}
+
+func flatten(turns [][]openai.ChatCompletionMessage) []openai.ChatCompletionMessage {
+	var res []openai.ChatCompletionMessage
+	for _, t := range turns {
+		res = append(res, t...)
+	}
+	return res
+}
+
+// dropFirstToolCall 删除第一组工具调用, 即带有 ToolCalls 的 assistant 消息和紧随其后的 tool 消息。
+// 最后一组工具调用不删除, 否则模型无法看到本轮的工具调用结果
+func dropFirstToolCall(turn []openai.ChatCompletionMessage) ([]openai.ChatCompletionMessage, bool) {
+	start, end, groups := -1, -1, 0
+	for i, m := range turn {
+		if len(m.ToolCalls) == 0 {
+			continue
+		}
+		groups++
+		if start >= 0 {
+			continue
+		}
+		start, end = i, i+1
+		for end < len(turn) && turn[end].Role == openai.ChatMessageRoleTool {
+			end++
+		}
+	}
+	if groups < 2 {
+		return turn, false
+	}
+	res := make([]openai.ChatCompletionMessage, 0, len(turn)-(end-start))
+	res = append(res, turn[:start]...)
+	return append(res, turn[end:]...), true
+}
+
+// -------- token 计数 --------
+
+// TokenCounter 计算一组消息占用的 token 数
+type TokenCounter func([]openai.ChatCompletionMessage) int
+
+// CountTokens 计算发送给 model 的消息的 token 数, 可用于费用估算。能够加载模型对应的 tiktoken 词表时
+// 精确计算, 否则使用 EstimateTokens 估算, 第二个返回值表示是否精确
+func CountTokens(model string, messages []openai.ChatCompletionMessage) (int, bool) {
+	enc, err := tiktoken.EncodingForModel(model)
+	if err != nil {
+		return EstimateTokens(messages), false
+	}
+	return enc.CountMessages(messages), true
+}
+
+func tokenCounterForModel(model string, debugf func(string, ...any)) TokenCounter {
+	enc, err := tiktoken.EncodingForModel(model)
+	if err != nil {
+		debugf("模型 '%s' 无法使用 tiktoken 计数 (%v), 使用估算", model, err)
+		return EstimateTokens
+	}
+	return enc.CountMessages
+}
+
+// EstimateTokens 粗略估算消息占用的 token 数: ASCII 字符按 4 个一个 token, 其他字符 (如中文) 每个字符一个 token,
+// 每条消息额外计 4 个, 图片按 85 个计算。适合在没有准确的 tokenizer 时作为预算的参考
+func EstimateTokens(messages []openai.ChatCompletionMessage) int {
+	const perMessage, perImage, reply = 4, 85, 3
+	total := reply
+	for _, m := range messages {
+		total += perMessage + estimateText(m.Role) + estimateText(m.Content) + estimateText(m.ReasoningContent)
+		for _, p := range m.MultiContent {
+			if p.Type == openai.ChatMessagePartTypeImageURL {
+				total += perImage
+			} else {
+				total += estimateText(p.Text)
+			}
+		}
+		for _, tc := range m.ToolCalls {
+			total += estimateText(tc.Function.Name) + estimateText(tc.Function.Arguments)
+		}
+	}
+	return total
+}
+
+func estimateText(s string) int {
+	ascii, others := 0, 0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			others++
+		}
+	}
+	return (ascii+3)/4 + others
+}
+
+// -------- 选项 --------
+
+type conversationOptions struct {
+	debugf        func(string, ...any)
+	strategy      MemoryStrategy
+	counter       TokenCounter
+	reserved      int
+	summaryPrompt string
+	summaryConfig *ModelConfig
+}
+
+// ConversationOption 对话选项
+type ConversationOption func(*conversationOptions)
+
+// WithConversationDebugger 设置调试函数
+func WithConversationDebugger(d func(string, ...any)) ConversationOption {
+	return func(o *conversationOptions) {
+		if d != nil {
+			o.debugf = d
+		}
+	}
+}
+
+// WithMemoryStrategy 设置超出 token 预算时的处理策略, 默认为 MemorySlidingWindow
+func WithMemoryStrategy(s MemoryStrategy) ConversationOption {
+	return func(o *conversationOptions) {
+		o.strategy = s
+	}
+}
+
+// WithTokenCounter 设置 token 计数函数, 如 tiktoken.Encoding 的 CountMessages 方法。默认按照模型名称
+// 使用对应的 tiktoken 编码, 无法加载时使用 EstimateTokens
+func WithTokenCounter(c TokenCounter) ConversationOption {
+	return func(o *conversationOptions) {
+		if c != nil {
+			o.counter = c
+		}
+	}
+}
+
+// WithReservedTokens 为模型的输出预留 token, 发送的消息不超过 MaxContextTokens 减去该值
+func WithReservedTokens(n int) ConversationOption {
+	return func(o *conversationOptions) {
+		if n > 0 {
+			o.reserved = n
+		}
+	}
+}
+
+// WithSummaryPrompt 设置 MemorySummarize 策略使用的 system prompt
+func WithSummaryPrompt(prompt string) ConversationOption {
+	return func(o *conversationOptions) {
+		if prompt != "" {
+			o.summaryPrompt = prompt
+		}
+	}
+}
+
+// WithSummaryModel 设置 MemorySummarize 策略使用的模型, 默认与对话使用相同的模型
+func WithSummaryModel(config ModelConfig) ConversationOption {
+	return func(o *conversationOptions) {
+		o.summaryConfig = &config
+	}
+}
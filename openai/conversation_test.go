@@ -0,0 +1,205 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	utils "github.com/Andrew-M-C/go.util/openai"
+	"github.com/sashabaranov/go-openai"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// fakeLLM 模拟 OpenAI 兼容的流式接口, reply 根据请求返回 assistant 消息
+type fakeLLM struct {
+	*httptest.Server
+
+	lock     sync.Mutex
+	requests []openai.ChatCompletionRequest
+}
+
+func newFakeLLM(reply func(openai.ChatCompletionRequest) openai.ChatCompletionMessage) *fakeLLM {
+	f := &fakeLLM{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.lock.Lock()
+		f.requests = append(f.requests, req)
+		f.lock.Unlock()
+
+		msg := reply(req)
+		finish := openai.FinishReasonStop
+		if len(msg.ToolCalls) > 0 {
+			finish = openai.FinishReasonToolCalls
+		}
+		for i := range msg.ToolCalls {
+			msg.ToolCalls[i].Index = &i
+		}
+		chunk := openai.ChatCompletionStreamResponse{
+			Choices: []openai.ChatCompletionStreamChoice{{
+				Delta: openai.ChatCompletionStreamChoiceDelta{
+					Role:      openai.ChatMessageRoleAssistant,
+					Content:   msg.Content,
+					ToolCalls: msg.ToolCalls,
+				},
+				FinishReason: finish,
+			}},
+		}
+		b, _ := json.Marshal(chunk)
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprintf(w, "data: %s\n\ndata: [DONE]\n\n", b)
+	}))
+	return f
+}
+
+func (f *fakeLLM) config() utils.ModelConfig {
+	return utils.ModelConfig{Model: "fake", BaseURL: f.URL, APIKey: "fake"}
+}
+
+func (f *fakeLLM) lastRequest() openai.ChatCompletionRequest {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.requests[len(f.requests)-1]
+}
+
+func userMsg(s string) openai.ChatCompletionMessage {
+	return openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: s}
+}
+
+// countByMessage 每条消息计 10 个 token
+func countByMessage(messages []openai.ChatCompletionMessage) int {
+	return len(messages) * 10
+}
+
+func TestConversation(t *testing.T) {
+	llm := newFakeLLM(func(req openai.ChatCompletionRequest) openai.ChatCompletionMessage {
+		if strings.Contains(req.Messages[0].Content, "摘要") {
+			return openai.ChatCompletionMessage{Content: "用户叫小明"}
+		}
+		return openai.ChatCompletionMessage{Content: "收到: " + req.Messages[len(req.Messages)-1].Content}
+	})
+	defer llm.Close()
+	ctx := context.Background()
+
+	cv("滑动窗口", t, func() {
+		config := llm.config()
+		config.MaxContextTokens = 50
+		c := utils.NewConversation(config, utils.WithTokenCounter(countByMessage))
+		c.SetSystemPrompt("你是一个助手")
+
+		for _, s := range []string{"1", "2", "3"} {
+			rsp, err := c.Process(ctx, []openai.ChatCompletionMessage{userMsg(s)})
+			so(err, isNil)
+			so(rsp.Messages[len(rsp.Messages)-1].Content, eq, "收到: "+s)
+		}
+		so(len(c.History()), eq, 6)
+
+		// system + 两轮对话
+		sent := llm.lastRequest().Messages
+		so(len(sent), eq, 4)
+		so(sent[0].Role, eq, openai.ChatMessageRoleSystem)
+		so(sent[1].Content, eq, "2")
+		so(sent[3].Content, eq, "3")
+	})
+
+	cv("工具调用成对丢弃", t, func() {
+		toolCall := func(id string) openai.ChatCompletionMessage {
+			return openai.ChatCompletionMessage{
+				Role:      openai.ChatMessageRoleAssistant,
+				ToolCalls: []openai.ToolCall{{ID: id, Type: openai.ToolTypeFunction}},
+			}
+		}
+		toolRsp := func(id string) openai.ChatCompletionMessage {
+			return openai.ChatCompletionMessage{Role: openai.ChatMessageRoleTool, ToolCallID: id, Content: id}
+		}
+
+		config := llm.config()
+		config.MaxContextTokens = 50
+		c := utils.NewConversation(config, utils.WithTokenCounter(countByMessage))
+		c.Append(userMsg("q"), toolCall("a"), toolRsp("a"), toolCall("b"), toolRsp("b"), toolCall("c"), toolRsp("c"))
+
+		msgs, err := c.Messages(ctx)
+		so(err, isNil)
+		so(len(msgs), eq, 5)
+		so(msgs[0].Content, eq, "q")
+		so(msgs[1].ToolCalls[0].ID, eq, "b")
+		so(msgs[2].ToolCallID, eq, "b")
+		so(msgs[4].ToolCallID, eq, "c")
+	})
+
+	cv("总结", t, func() {
+		config := llm.config()
+		config.MaxContextTokens = 40
+		c := utils.NewConversation(config,
+			utils.WithTokenCounter(countByMessage), utils.WithMemoryStrategy(utils.MemorySummarize),
+		)
+		for _, s := range []string{"我叫小明", "你好", "再见"} {
+			_, err := c.Process(ctx, []openai.ChatCompletionMessage{userMsg(s)})
+			so(err, isNil)
+		}
+		so(c.Summary(), eq, "用户叫小明")
+
+		sent := llm.lastRequest().Messages
+		so(sent[0].Role, eq, openai.ChatMessageRoleSystem)
+		so(sent[0].Content, convey.ShouldEndWith, "用户叫小明")
+		so(sent[len(sent)-1].Content, eq, "再见")
+		so(len(c.History()), eq, 2)
+	})
+
+	cv("请求失败时撤销追加的消息", t, func() {
+		c := utils.NewConversation(llm.config())
+		_, err := c.Process(ctx, []openai.ChatCompletionMessage{userMsg("1")})
+		so(err, isNil)
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = c.Process(cancelled, []openai.ChatCompletionMessage{userMsg("2")})
+		so(err, convey.ShouldBeError)
+		so(len(c.History()), eq, 2)
+
+		_, err = c.Process(ctx, []openai.ChatCompletionMessage{userMsg("3")})
+		so(err, isNil)
+		history := c.History()
+		so(len(history), eq, 4)
+		so(history[2].Content, eq, "3")
+	})
+
+	cv("超过最大迭代次数时保留已有的消息", t, func() {
+		loop := newFakeLLM(func(openai.ChatCompletionRequest) openai.ChatCompletionMessage {
+			return toolCallMsg([2]string{"echo", `{}`})
+		})
+		defer loop.Close()
+		echo := func(context.Context, struct{}) (string, error) { return "again", nil }
+
+		c := utils.NewConversation(loop.config())
+		_, err := c.Process(ctx, []openai.ChatCompletionMessage{userMsg("loop")},
+			utils.WithFunctionTool("echo", "", echo), utils.WithMaxIterations(1),
+		)
+		so(errors.Is(err, utils.ErrMaxIterations), eq, true)
+
+		history := c.History()
+		so(len(history), eq, 3)
+		so(history[0].Content, eq, "loop")
+		so(history[1].ToolCalls[0].Function.Name, eq, "echo")
+		so(history[2].Content, eq, "again")
+	})
+
+	cv("估算 token", t, func() {
+		n := utils.EstimateTokens([]openai.ChatCompletionMessage{userMsg("hello world, 你好")})
+		so(n, eq, 3+4+1+4+2)
+
+		// 没有 tiktoken 编码的模型使用估算
+		n, exact := utils.CountTokens("deepseek-chat", []openai.ChatCompletionMessage{userMsg("hello world, 你好")})
+		so(exact, eq, false)
+		so(n, eq, 3+4+1+4+2)
+	})
+}
@@ -0,0 +1,117 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+type functionTool struct {
+	name   string
+	desc   string
+	params *JSONSchema
+	call   func(ctx context.Context, args string) (string, error)
+	err    error // 生成参数 schema 的错误, 在 Process 开始时返回
+}
+
+// WithFunctionTool 将本地函数作为工具提供给模型, 不需要 MCP 服务器, 可以设置多个, 也可以与 MCP 工具同时使用。
+//
+// 工具的参数 schema 根据 Args 结构体生成, 规则参见 GenerateJSONSchema。模型调用时参数按照 schema 校验后
+// 解析为 Args; Result 为 string 时直接返回给模型, 否则使用 json.Marshal 序列化。
+// 函数返回的错误会作为工具调用失败的结果告知模型。name 不得重复, 也不得包含 ":"
+func WithFunctionTool[Args, Result any](
+	name string, desc string, fn func(context.Context, Args) (Result, error),
+) Option {
+	if fn == nil {
+		return nil
+	}
+	f := functionTool{name: name, desc: desc}
+	f.params, f.err = functionParamsSchema(reflect.TypeOf((*Args)(nil)).Elem())
+	f.call = func(ctx context.Context, arguments string) (string, error) {
+		if strings.TrimSpace(arguments) == "" {
+			arguments = "{}"
+		}
+		if err := f.params.Validate([]byte(arguments)); err != nil {
+			return "", fmt.Errorf("参数不符合要求 (%w)", err)
+		}
+		var args Args
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", fmt.Errorf("解析参数失败 (%w)", err)
+		}
+		res, err := fn(ctx, args)
+		if err != nil {
+			return "", err
+		}
+		return functionResultString(res)
+	}
+	return func(o *options) {
+		o.functionTools = append(o.functionTools, f)
+	}
+}
+
+func functionParamsSchema(t reflect.Type) (*JSONSchema, error) {
+	s, err := schemaForType(t, map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+	if s.Type != "object" {
+		return nil, fmt.Errorf("function tool arguments must be a struct or map, got %v", t)
+	}
+	s.nullable = false
+	if s.Properties == nil && s.AdditionalProperties == nil {
+		s.Properties = map[string]*JSONSchema{}
+	}
+	return s, nil
+}
+
+func functionResultString(res any) (string, error) {
+	switch v := res.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		return "", fmt.Errorf("序列化返回值失败 (%w)", err)
+	}
+	return string(b), nil
+}
+
+func (p *processor) packFunctionTools(ctx context.Context) error {
+	if len(p.Opts.functionTools) == 0 {
+		return nil
+	}
+	p.functionByName = make(map[string]functionTool, len(p.Opts.functionTools))
+	for _, f := range p.Opts.functionTools {
+		switch {
+		case f.err != nil:
+			return fmt.Errorf("函数工具 '%s' 参数错误 (%w)", f.name, f.err)
+		case f.name == "":
+			return errors.New("函数工具名称为空")
+		case strings.Contains(f.name, mcpClientNameSeparator):
+			return fmt.Errorf("函数工具名称不能包含 '%s' (%s)", mcpClientNameSeparator, f.name)
+		}
+		if _, exist := p.functionByName[f.name]; exist {
+			return fmt.Errorf("函数工具名称重复 (%s)", f.name)
+		}
+		p.functionByName[f.name] = f
+		p.functionTools = append(p.functionTools, openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        f.name,
+				Description: f.desc,
+				Parameters:  f.params,
+			},
+		})
+	}
+	p.Opts.debugf("打包函数工具成功: %v", toJSON{p.functionTools})
+	return nil
+}
@@ -0,0 +1,109 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	utils "github.com/Andrew-M-C/go.util/openai"
+	"github.com/sashabaranov/go-openai"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+type addArgs struct {
+	A int `json:"a" description:"加数"`
+	B int `json:"b" description:"加数"`
+}
+
+type addResult struct {
+	Sum int `json:"sum"`
+}
+
+func toolCallMsg(calls ...[2]string) openai.ChatCompletionMessage {
+	m := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant}
+	for _, c := range calls {
+		m.ToolCalls = append(m.ToolCalls, openai.ToolCall{
+			ID:       "call-" + c[0],
+			Type:     openai.ToolTypeFunction,
+			Function: openai.FunctionCall{Name: c[0], Arguments: c[1]},
+		})
+	}
+	return m
+}
+
+func toolResults(req openai.ChatCompletionRequest) map[string]string {
+	res := map[string]string{}
+	for _, m := range req.Messages {
+		if m.Role == openai.ChatMessageRoleTool {
+			res[m.ToolCallID] = m.Content
+		}
+	}
+	return res
+}
+
+func TestFunctionTool(t *testing.T) {
+	llm := newFakeLLM(func(req openai.ChatCompletionRequest) openai.ChatCompletionMessage {
+		if results := toolResults(req); len(results) > 0 {
+			return openai.ChatCompletionMessage{Content: results["call-add"] + "|" + results["call-tool-weather:weather"]}
+		}
+		return toolCallMsg(
+			[2]string{"add", `{"a":1,"b":2}`},
+			[2]string{"tool-weather:weather", `{"location":"深圳"}`},
+		)
+	})
+	defer llm.Close()
+	ctx := context.Background()
+	msgs := []openai.ChatCompletionMessage{userMsg("1+2=? 深圳天气如何?")}
+
+	add := func(_ context.Context, args addArgs) (addResult, error) {
+		return addResult{Sum: args.A + args.B}, nil
+	}
+
+	cv("与 MCP 工具混合调用", t, func() {
+		weather := &weatherMCP{}
+		rsp, err := utils.Process(ctx, llm.config(), msgs,
+			utils.WithFunctionTool("add", "计算两个整数的和", add),
+			utils.WithInitializedMCP(weather, "tool-weather"),
+		)
+		so(err, isNil)
+		so(rsp.Messages[len(rsp.Messages)-1].Content, eq, `{"sum":3}|狂风暴雨`)
+		so(weather.Count, eq, 1)
+
+		var names []string
+		for _, tool := range llm.requests[0].Tools {
+			names = append(names, tool.Function.Name)
+		}
+		so(names, convey.ShouldContain, "add")
+		so(names, convey.ShouldContain, "tool-weather:weather")
+	})
+
+	cv("参数错误和函数错误告知模型", t, func() {
+		failed := func(context.Context, struct{}) (string, error) { return "", errors.New("boom") }
+		strictAdd := func(_ context.Context, args struct {
+			A string `json:"a"`
+		}) (string, error) {
+			return args.A, nil
+		}
+		_, err := utils.Process(ctx, llm.config(), msgs,
+			utils.WithFunctionTool("add", "", strictAdd),
+			utils.WithFunctionTool("tool-weather:weather", "", failed),
+		)
+		so(err, convey.ShouldBeError) // 名称包含 ":"
+
+		rsp, err := utils.Process(ctx, llm.config(), msgs, utils.WithFunctionTool("add", "", strictAdd))
+		so(err, isNil)
+		so(toolResults(openai.ChatCompletionRequest{Messages: rsp.Messages})["call-add"], convey.ShouldContainSubstring, "$.a: expect string but got number")
+	})
+
+	cv("非法的参数类型和重复的名称", t, func() {
+		_, err := utils.Process(ctx, llm.config(), msgs,
+			utils.WithFunctionTool("add", "", func(context.Context, int) (int, error) { return 0, nil }),
+		)
+		so(err, convey.ShouldBeError)
+
+		_, err = utils.Process(ctx, llm.config(), msgs,
+			utils.WithFunctionTool("add", "", add), utils.WithFunctionTool("add", "", add),
+		)
+		so(err, convey.ShouldBeError)
+	})
+}
@@ -8,6 +8,8 @@ require (
 	github.com/Andrew-M-C/go-bytesize v0.0.0-20230105080248-c93b078d58b3
 	github.com/Andrew-M-C/go.jsonvalue v1.4.2
 	github.com/Andrew-M-C/go.util/net v0.0.0-20260119092309-47eb9e92542f
+	github.com/Andrew-M-C/go.util/recovery v0.0.0-20260112085026-f11b68b9fbfc
+	github.com/Andrew-M-C/go.util/runtime v0.0.0-20260112084229-7b0e1916deb4
 	github.com/Andrew-M-C/go.util/unsafe v0.0.0-20260119092309-47eb9e92542f
 	github.com/fatih/color v1.18.0
 	github.com/h2non/filetype v1.1.3
@@ -17,9 +19,11 @@ require (
 )
 
 require (
+	github.com/Andrew-M-C/go.objectid v1.0.3 // indirect
+	github.com/Andrew-M-C/go.util/log v0.0.0-20241118072554-b6cba35b72fb // indirect
+	github.com/Andrew-M-C/go.util/time v1.0.1-0.20260112084229-7b0e1916deb4 // indirect
 	github.com/bahlo/generic-list-go v0.2.0 // indirect
 	github.com/buger/jsonparser v1.1.1 // indirect
-	github.com/google/go-cmp v0.6.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/gopherjs/gopherjs v1.17.2 // indirect
 	github.com/invopop/jsonschema v0.13.0 // indirect
@@ -30,11 +34,11 @@ require (
 	github.com/shopspring/decimal v1.4.0 // indirect
 	github.com/smarty/assertions v1.15.0 // indirect
 	github.com/spf13/cast v1.7.1 // indirect
+	github.com/valyala/fastrand v1.1.0 // indirect
 	github.com/wk8/go-ordered-map/v2 v2.1.8 // indirect
 	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
-	golang.org/x/net v0.34.0 // indirect
+	go.mongodb.org/mongo-driver v1.17.1 // indirect
 	golang.org/x/sys v0.29.0 // indirect
 	golang.org/x/text v0.21.0 // indirect
-	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -3,6 +3,7 @@ package openai
 
 import (
 	"context"
+	"errors"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/sashabaranov/go-openai"
@@ -13,6 +14,9 @@ type ModelConfig struct {
 	Model   string `json:"model,omitempty"`
 	BaseURL string `json:"base_url,omitempty"`
 	APIKey  string `json:"api_key,omitempty"`
+
+	// 上下文的 token 预算, 供 Conversation 截断历史消息使用, 0 表示不限制
+	MaxContextTokens int `json:"max_context_tokens,omitempty"`
 }
 
 // Process 完全自助式地处理一次完整的流式响应, 从发起请求开始, 自动调用工具, 直到模型返回完成为止
@@ -29,6 +33,13 @@ func Process(
 	return p.do(ctx)
 }
 
+// DefaultMaxIterations 默认最多请求模型的次数, 参见 WithMaxIterations
+const DefaultMaxIterations = 20
+
+// ErrMaxIterations 表示模型连续要求调用工具, 请求次数超过了 WithMaxIterations 的限制。
+// 此时 Process 同时返回已有的消息, 最后一条为工具调用的结果
+var ErrMaxIterations = errors.New("超过最大迭代次数")
+
 // ProcessResponse 表示一次请求的结果
 type ProcessResponse struct {
 	Messages     []openai.ChatCompletionMessage
@@ -36,19 +36,28 @@ var (
 	deepseekAPIKey  = ""
 	deepseekMCPURL  = ""
 	hunyuanAPIKey   = ""
+
+	// 是否设置了测试环境变量
+	liveEnv = false
 )
 
 //go:embed test_image.png
 var testPNG []byte
 
 func TestMain(m *testing.M) {
-	if !readEnv() {
-		fmt.Println("测试环境变量未设置, 不进行测试")
-		os.Exit(0)
+	if liveEnv = readEnv(); !liveEnv {
+		fmt.Println("测试环境变量未设置, 不进行需要调用模型的测试")
 	}
 	os.Exit(m.Run())
 }
 
+// skipIfNoEnv 测试环境变量未设置时, 跳过需要调用真实模型的测试
+func skipIfNoEnv(t *testing.T) {
+	if !liveEnv {
+		t.Skip("测试环境变量未设置")
+	}
+}
+
 func readEnv() bool {
 	if deepseekBaseURL = os.Getenv("DEEPSEEK_BASE_URL"); deepseekBaseURL == "" {
 		return false
@@ -216,6 +225,7 @@ func TestAddOrSetPromptForMessages(t *testing.T) {
 }
 
 func TestProcessBasic(t *testing.T) {
+	skipIfNoEnv(t)
 	reasoningBuilder := strings.Builder{}
 	contentBuilder := strings.Builder{}
 
@@ -304,6 +314,7 @@ func TestProcessBasic(t *testing.T) {
 }
 
 func TestProcessMCP(t *testing.T) {
+	skipIfNoEnv(t)
 	cv("带完整的 MCP 询问", t, func() {
 		ctx := context.Background()
 		config := utils.ModelConfig{
@@ -439,6 +450,7 @@ func TestProcessMCP(t *testing.T) {
 }
 
 func TestProcessMultiModal(t *testing.T) {
+	skipIfNoEnv(t)
 	cv("带图片请求, 混元图生文, 网络链接", t, func() {
 		// Reference: https://cloud.tencent.com/document/product/1729/111007
 		ctx := context.Background()
@@ -585,6 +597,7 @@ func TestProcessMultiModal(t *testing.T) {
 }
 
 func TestInitializedMCP(t *testing.T) {
+	skipIfNoEnv(t)
 	cv("时间 + 天气两个 MCP", t, func() {
 		ctx := context.Background()
 		config := utils.ModelConfig{
@@ -1,7 +1,9 @@
 package openai
 
 import (
+	"context"
 	"strings"
+	"time"
 
 	jsonvalue "github.com/Andrew-M-C/go.jsonvalue"
 	"github.com/mark3labs/mcp-go/client/transport"
@@ -14,6 +16,8 @@ type options struct {
 
 	customizeMCPs []initializedMCPParams
 
+	functionTools []functionTool
+
 	// 简单回调
 	reasoningCallback func(string)
 	contentCallback   func(string)
@@ -23,8 +27,20 @@ type options struct {
 	toolCallRequestCallback  func(openai.ToolCall)
 	toolCallResponseCallback func(openai.ChatCompletionMessage)
 
+	// 工具调用控制
+	toolCallConcurrency int
+	toolCallTimeout     time.Duration
+	toolTimeouts        map[string]time.Duration
+	toolCallApprover    ToolCallApprover
+	maxIterations       int
+
 	// 额外参数
 	extraFields *jsonvalue.V
+
+	// 结构化输出
+	responseFormat    *openai.ChatCompletionResponseFormat
+	jsonRepairRetries int
+	jsonObjectMode    bool
 }
 
 type initializedMCPParams struct {
@@ -43,7 +59,10 @@ type remoteMCPParams struct {
 }
 
 func mergeOptions(opts []Option) *options {
-	o := &options{}
+	o := &options{
+		maxIterations:     DefaultMaxIterations,
+		jsonRepairRetries: 2,
+	}
 	for _, f := range opts {
 		if f == nil {
 			continue
@@ -176,6 +195,61 @@ func WithToolCallResponseCallback(c func(openai.ChatCompletionMessage)) Option {
 	}
 }
 
+// ToolCallApprover 在工具实际调用之前审批。返回 error 表示拒绝调用, 错误信息会作为调用结果告知模型;
+// 否则按照返回的 ToolCall 调用, 可以修改其中的参数, 但 ID 和工具名称不可修改。
+// 同一轮的多个工具调用按照模型返回的顺序逐个审批, 审批通过之后才会并发调用
+type ToolCallApprover func(ctx context.Context, tc openai.ToolCall) (openai.ToolCall, error)
+
+// WithToolCallApprover 设置工具调用审批函数
+func WithToolCallApprover(a ToolCallApprover) Option {
+	return func(o *options) {
+		if a != nil {
+			o.toolCallApprover = a
+		}
+	}
+}
+
+// WithToolCallConcurrency 设置同一轮工具调用的最大并发数, 小于等于 0 表示不限制 (默认)。
+// 无论并发数多少, 工具调用结果都按照模型返回的顺序添加到消息中
+func WithToolCallConcurrency(n int) Option {
+	return func(o *options) {
+		o.toolCallConcurrency = n
+	}
+}
+
+// WithToolCallTimeout 设置所有工具调用的默认超时时间, 小于等于 0 表示不限制 (默认)
+func WithToolCallTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.toolCallTimeout = d
+	}
+}
+
+// WithToolTimeout 设置指定工具的超时时间, 优先于 WithToolCallTimeout。name 为提供给模型的工具名称,
+// MCP 工具的格式为 "<MCP ID>:<工具名>", 本地函数工具则为 WithFunctionTool 指定的名称
+func WithToolTimeout(name string, d time.Duration) Option {
+	return func(o *options) {
+		if o.toolTimeouts == nil {
+			o.toolTimeouts = map[string]time.Duration{}
+		}
+		o.toolTimeouts[name] = d
+	}
+}
+
+// WithMaxIterations 设置最多请求模型的次数, 默认为 DefaultMaxIterations, 小于等于 0 表示不限制。
+// 模型连续要求调用工具超过这个次数时, Process 返回 ErrMaxIterations
+func WithMaxIterations(n int) Option {
+	return func(o *options) {
+		o.maxIterations = n
+	}
+}
+
+func (o *options) timeoutOfTool(name string) time.Duration {
+	if d, exist := o.toolTimeouts[name]; exist {
+		return d
+	}
+	return o.toolCallTimeout
+}
+
 // WithInitializedMCP 设置自定义的已初始化完成的 MCP 客户端, 可以设置多个
 // 参数 id 可以是任意不含空格和毛好的字符串, 多个 MCP 之间不得重复
 func WithInitializedMCP(c InitializedMCPClient, id string) Option {
@@ -241,3 +315,26 @@ func WithExtraFields(fields any) Option {
 		})
 	}
 }
+
+// WithJSONRepairRetries 设置 ProcessJSON 在模型输出不符合 JSON Schema 时要求模型修正的最大次数, 默认为 2
+func WithJSONRepairRetries(n int) Option {
+	return func(o *options) {
+		if n >= 0 {
+			o.jsonRepairRetries = n
+		}
+	}
+}
+
+// WithJSONObjectMode 使 ProcessJSON 使用 json_object 模式而不是 json_schema 模式, JSON Schema 通过 system
+// 消息告知模型。适用于不支持 json_schema 的模型, 如 DeepSeek
+func WithJSONObjectMode() Option {
+	return func(o *options) {
+		o.jsonObjectMode = true
+	}
+}
+
+func withResponseFormat(f *openai.ChatCompletionResponseFormat) Option {
+	return func(o *options) {
+		o.responseFormat = f
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"sync"
 
 	hutil "github.com/Andrew-M-C/go.util/net/http"
+	"github.com/Andrew-M-C/go.util/recovery"
+	"github.com/Andrew-M-C/go.util/runtime/caller"
 	mcpclient "github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/sashabaranov/go-openai"
@@ -29,6 +31,9 @@ type processor struct {
 	mcpClientByID map[string]InitializedMCPClient
 	mcpTools      []openai.Tool
 
+	functionByName map[string]functionTool
+	functionTools  []openai.Tool
+
 	lastFinishReason openai.FinishReason
 }
 
@@ -45,10 +50,14 @@ func (p *processor) do(ctx context.Context) (ProcessResponse, error) {
 		p.addCustomizedMCPs, // 自定义 MCP 或者是初始化好了的 MCP
 		p.connectRemoteMCP,  // 连接远程 MCP
 		p.packMCPTools,      // 打包 MCP 工具作为后续请求的参数
+		p.packFunctionTools, // 打包本地函数工具
 		p.iteration,         // 开始迭代
 	}
 	for _, proc := range procedures {
 		if err := proc(ctx); err != nil {
+			if errors.Is(err, ErrMaxIterations) {
+				return ProcessResponse{Messages: p.Messages, FinishReason: p.lastFinishReason}, err
+			}
 			return ProcessResponse{}, err
 		}
 	}
@@ -208,7 +217,11 @@ func (p *processor) iteration(ctx context.Context) error {
 		return nil
 	}
 
-	for {
+	for i := 0; ; i++ {
+		if max := p.Opts.maxIterations; max > 0 && i >= max {
+			p.Opts.debugf("已请求模型 %d 次, 超过最大迭代次数, 停止工具调用", i)
+			return fmt.Errorf("%w (%d)", ErrMaxIterations, max)
+		}
 		if err := iterate(); err != nil {
 			return err
 		}
@@ -252,7 +265,8 @@ func (p *oneTimeProcessor) do(ctx context.Context) (openai.ChatCompletionStreamR
 	emptyRsp := openai.ChatCompletionStreamResponse{}
 
 	// 首先发起请求, 获取响应
-	rsp, err := connect(ctx, p.Conf, p.Messages, p.mcpTools, p.Opts)
+	tools := slices.Concat(p.mcpTools, p.functionTools)
+	rsp, err := connect(ctx, p.Conf, p.Messages, tools, p.Opts)
 	if err != nil {
 		return emptyRsp, fmt.Errorf("发起请求失败 (%w)", err)
 	}
@@ -273,21 +287,41 @@ type toolProcessor struct {
 }
 
 func (p *toolProcessor) do(ctx context.Context) error {
-	tcList := p.lastMessage().ToolCalls
+	tcList := p.approve(ctx, p.lastMessage().ToolCalls)
+	results := make([]openai.ChatCompletionMessage, len(tcList))
 
 	lck := sync.Mutex{}
 	wg := sync.WaitGroup{}
+	var sem chan struct{}
+	if n := p.Opts.toolCallConcurrency; n > 0 {
+		sem = make(chan struct{}, n)
+	}
 
-	// 并发调用
+	// 并发调用, 结果按照顺序保存
 	for i, tc := range tcList {
+		results[i] = openai.ChatCompletionMessage{
+			Role:       openai.ChatMessageRoleTool,
+			ToolCallID: tc.ID,
+		}
+		if tc.denied != nil {
+			results[i].Content = fmt.Sprintf("工具调用被拒绝, 原因: '%v', 工具 %v", tc.denied, tc.Function.Name)
+			p.Opts.debugf("%s", results[i].Content)
+			p.Opts.toolCallResponseCallback(results[i])
+			continue
+		}
+
 		p.Opts.debugf("需要调用工具: %v", tc.Function.Name)
-		p.Opts.toolCallRequestCallback(tc)
+		p.Opts.toolCallRequestCallback(tc.ToolCall)
 
 		wg.Add(1)
 		go func(i int, tc openai.ToolCall) {
 			defer wg.Done()
+			if sem != nil {
+				sem <- struct{}{}
+				defer func() { <-sem }()
+			}
 
-			res, e := p.doToolCall(ctx, tc)
+			res, e := p.doToolCallWithTimeout(ctx, tc)
 			if e != nil {
 				res = fmt.Sprintf("工具调用失败, 错误: '%v', 工具 %v", e, tc.Function.Name)
 				p.Opts.debugf("%s", res)
@@ -297,22 +331,101 @@ func (p *toolProcessor) do(ctx context.Context) error {
 
 			lck.Lock()
 			defer lck.Unlock()
-			m := openai.ChatCompletionMessage{
-				Role:       openai.ChatMessageRoleTool,
-				Content:    res,
-				ToolCallID: tc.ID,
-			}
-
-			p.Messages = append(p.Messages, m)
-			p.Opts.toolCallResponseCallback(m)
-		}(i, tc)
+			results[i].Content = res
+			p.Opts.toolCallResponseCallback(results[i])
+		}(i, tc.ToolCall)
 	}
 	wg.Wait()
 
+	p.Messages = append(p.Messages, results...)
 	return nil // 暂时没有返回错误
 }
 
+type approvedToolCall struct {
+	openai.ToolCall
+	denied error
+}
+
+// approve 逐个审批工具调用。审批时修改了参数的话, 同步修改 assistant 消息中的工具调用, 使上下文与实际调用一致
+func (p *toolProcessor) approve(ctx context.Context, tcList []openai.ToolCall) []approvedToolCall {
+	res := make([]approvedToolCall, len(tcList))
+	modified := false
+	for i, tc := range tcList {
+		res[i].ToolCall = tc
+		if p.Opts.toolCallApprover == nil {
+			continue
+		}
+		approved, err := p.Opts.toolCallApprover(ctx, tc)
+		switch {
+		case err != nil:
+			res[i].denied = err
+		case approved.ID != tc.ID || approved.Function.Name != tc.Function.Name:
+			res[i].denied = fmt.Errorf("审批时不可修改工具调用的 ID 和名称 (%s)", tc.Function.Name)
+		default:
+			res[i].ToolCall = approved
+			modified = modified || approved.Function.Arguments != tc.Function.Arguments
+		}
+	}
+	if modified {
+		last := &p.Messages[len(p.Messages)-1]
+		last.ToolCalls = make([]openai.ToolCall, len(res))
+		for i, tc := range res {
+			last.ToolCalls[i] = tc.ToolCall
+		}
+	}
+	return res
+}
+
+// doToolCallWithTimeout 在 doToolCall 的基础上增加超时控制和 panic 捕获
+func (p *toolProcessor) doToolCallWithTimeout(ctx context.Context, tc openai.ToolCall) (res string, err error) {
+	if d := p.Opts.timeoutOfTool(tc.Function.Name); d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	type result struct {
+		res string
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		r := result{}
+		defer func() { ch <- r }()
+		defer recovery.CatchPanic(
+			recovery.WithContext(ctx),
+			recovery.WithCallback(func(info any, stack []caller.Caller) {
+				r.err = fmt.Errorf("工具调用 panic: %v", info)
+				if len(stack) > 0 {
+					r.err = fmt.Errorf("%w, 位置 %v", r.err, stack[0])
+				}
+			}),
+		)
+		r.res, r.err = p.doToolCall(ctx, tc)
+	}()
+
+	// 工具实现不一定响应 ctx, 超时后不再等待
+	select {
+	case r := <-ch:
+		return r.res, r.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("工具调用超时 (%v)", p.Opts.timeoutOfTool(tc.Function.Name))
+		}
+		return "", ctx.Err()
+	}
+}
+
 func (p *toolProcessor) doToolCall(ctx context.Context, tc openai.ToolCall) (string, error) {
+	if f, exist := p.functionByName[tc.Function.Name]; exist {
+		res, err := f.call(ctx, tc.Function.Arguments)
+		if err != nil {
+			return "", fmt.Errorf("调用函数工具 '%s' 失败 (%w)", f.name, err)
+		}
+		p.Opts.debugf("调用函数工具 '%s', 返回 '%s'", f.name, res)
+		return res, nil
+	}
+
 	var args map[string]any
 	if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
 		return "", fmt.Errorf("解析工具调用参数失败 (%w)", err)
@@ -0,0 +1,174 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	utils "github.com/Andrew-M-C/go.util/openai"
+	"github.com/sashabaranov/go-openai"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+type sleepArgs struct {
+	MS int `json:"ms"`
+}
+
+func TestToolCallControl(t *testing.T) {
+	ctx := context.Background()
+	msgs := []openai.ChatCompletionMessage{userMsg("调用工具")}
+
+	// 首轮返回指定的工具调用, 有工具结果之后结束
+	newLLM := func(calls ...[2]string) *fakeLLM {
+		return newFakeLLM(func(req openai.ChatCompletionRequest) openai.ChatCompletionMessage {
+			if len(toolResults(req)) > 0 {
+				return openai.ChatCompletionMessage{Content: "done"}
+			}
+			return toolCallMsg(calls...)
+		})
+	}
+	toolMessages := func(rsp utils.ProcessResponse) (res []openai.ChatCompletionMessage) {
+		for _, m := range rsp.Messages {
+			if m.Role == openai.ChatMessageRoleTool {
+				res = append(res, m)
+			}
+		}
+		return res
+	}
+
+	cv("限制并发, 结果保持顺序", t, func() {
+		var running, maxRunning int32
+		sleep := func(_ context.Context, args sleepArgs) (string, error) {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Duration(args.MS) * time.Millisecond)
+			return fmt.Sprint(args.MS), nil
+		}
+		llm := newLLM(
+			[2]string{"sleep", `{"ms":80}`},
+			[2]string{"sleep", `{"ms":10}`},
+			[2]string{"sleep", `{"ms":40}`},
+			[2]string{"sleep", `{"ms":0}`},
+		)
+		defer llm.Close()
+
+		rsp, err := utils.Process(ctx, llm.config(), msgs,
+			utils.WithFunctionTool("sleep", "睡眠指定毫秒数", sleep),
+			utils.WithToolCallConcurrency(2),
+		)
+		so(err, isNil)
+		so(atomic.LoadInt32(&maxRunning), eq, 2)
+
+		var contents []string
+		for _, m := range toolMessages(rsp) {
+			contents = append(contents, m.Content)
+		}
+		so(contents, convey.ShouldResemble, []string{"80", "10", "40", "0"})
+	})
+
+	cv("超时和 panic", t, func() {
+		block := func(ctx context.Context, _ struct{}) (string, error) {
+			time.Sleep(time.Second) // 不响应 ctx 的工具
+			return "unreachable", nil
+		}
+		wait := func(ctx context.Context, _ struct{}) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		crash := func(context.Context, struct{}) (string, error) {
+			panic("崩溃了")
+		}
+		llm := newLLM(
+			[2]string{"block", `{}`},
+			[2]string{"wait", `{}`},
+			[2]string{"crash", `{}`},
+		)
+		defer llm.Close()
+
+		start := time.Now()
+		rsp, err := utils.Process(ctx, llm.config(), msgs,
+			utils.WithFunctionTool("block", "", block),
+			utils.WithFunctionTool("wait", "", wait),
+			utils.WithFunctionTool("crash", "", crash),
+			utils.WithToolCallTimeout(50*time.Millisecond),
+			utils.WithToolTimeout("wait", 20*time.Millisecond),
+		)
+		so(err, isNil)
+		so(time.Since(start), convey.ShouldBeLessThan, 500*time.Millisecond)
+
+		tms := toolMessages(rsp)
+		so(len(tms), eq, 3)
+		so(tms[0].Content, convey.ShouldContainSubstring, "工具调用超时 (50ms)")
+		so(tms[1].Content, convey.ShouldContainSubstring, "工具调用超时 (20ms)")
+		so(tms[2].Content, convey.ShouldContainSubstring, "工具调用 panic: 崩溃了")
+	})
+
+	cv("审批: 拒绝、修改和确认", t, func() {
+		echo := func(_ context.Context, args sleepArgs) (int, error) { return args.MS, nil }
+		llm := newLLM(
+			[2]string{"echo", `{"ms":1}`},
+			[2]string{"echo", `{"ms":2}`},
+			[2]string{"echo", `{"ms":3}`},
+			[2]string{"rename", `{}`},
+		)
+		defer llm.Close()
+
+		var approved []string
+		approver := func(_ context.Context, tc openai.ToolCall) (openai.ToolCall, error) {
+			approved = append(approved, tc.Function.Arguments)
+			switch tc.Function.Arguments {
+			case `{"ms":1}`:
+				return tc, errors.New("用户拒绝")
+			case `{"ms":2}`:
+				tc.Function.Arguments = `{"ms":20}`
+			}
+			if tc.Function.Name == "rename" {
+				tc.Function.Name = "echo"
+			}
+			return tc, nil
+		}
+		rsp, err := utils.Process(ctx, llm.config(), msgs,
+			utils.WithFunctionTool("echo", "", echo),
+			utils.WithToolCallApprover(approver),
+		)
+		so(err, isNil)
+		so(len(approved), eq, 4)
+
+		tms := toolMessages(rsp)
+		so(len(tms), eq, 4)
+		so(tms[0].Content, convey.ShouldContainSubstring, "工具调用被拒绝, 原因: '用户拒绝'")
+		so(tms[1].Content, eq, "20")
+		so(tms[2].Content, eq, "3")
+		so(tms[3].Content, convey.ShouldContainSubstring, "不可修改工具调用的 ID 和名称")
+
+		// assistant 消息中的参数与实际调用一致
+		so(rsp.Messages[1].ToolCalls[1].Function.Arguments, eq, `{"ms":20}`)
+		so(llm.lastRequest().Messages[1].ToolCalls[1].Function.Arguments, eq, `{"ms":20}`)
+	})
+
+	cv("最大迭代次数", t, func() {
+		llm := newFakeLLM(func(openai.ChatCompletionRequest) openai.ChatCompletionMessage {
+			return toolCallMsg([2]string{"echo", `{}`})
+		})
+		defer llm.Close()
+		echo := func(context.Context, struct{}) (string, error) { return "again", nil }
+
+		rsp, err := utils.Process(ctx, llm.config(), msgs,
+			utils.WithFunctionTool("echo", "", echo),
+			utils.WithMaxIterations(3),
+		)
+		so(errors.Is(err, utils.ErrMaxIterations), eq, true)
+		so(len(llm.requests), eq, 3)
+		so(len(rsp.Messages), eq, 1+3*2)
+		so(rsp.Messages[len(rsp.Messages)-1].Role, eq, openai.ChatMessageRoleTool)
+	})
+}
@@ -29,6 +29,9 @@ func connect(
 		req.Tools = tools
 		req.ToolChoice = "auto"
 	}
+	if opt.responseFormat != nil {
+		req.ResponseFormat = opt.responseFormat
+	}
 
 	options := []hutil.RequestOption{
 		hutil.WithRequestHeader(h),
@@ -0,0 +1,340 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONSchema 表示一个 JSON Schema, 由 GenerateJSONSchema 根据 Go 类型生成
+type JSONSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"` // false 或 *JSONSchema
+
+	nullable bool // 指针、切片、map 允许为 null
+}
+
+var (
+	jsonTimeType       = reflect.TypeOf(time.Time{})
+	jsonRawMessageType = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// GenerateJSONSchema 根据 v 的类型生成 JSON Schema。结构体字段支持以下 tag:
+//
+//   - json: 字段名, 与 encoding/json 相同。"-" 表示忽略, 带有 omitempty 的字段不是必需的
+//   - description: 字段说明, 模型依此理解字段的含义
+//   - enum: 逗号分隔的可选值
+//   - required: "true" 或 "false", 覆盖 omitempty 的判断
+//
+// time.Time 为 date-time 格式的字符串, 实现了 json.Marshaler 的其他类型以及 interface 不限制类型
+func GenerateJSONSchema(v any) (*JSONSchema, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, errors.New("cannot generate JSON schema for nil")
+	}
+	return schemaForType(t, map[reflect.Type]bool{})
+}
+
+func schemaForType(t reflect.Type, visiting map[reflect.Type]bool) (*JSONSchema, error) {
+	nullable := false
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		nullable = true
+	}
+	s, err := schemaForNonNullType(t, visiting)
+	if err != nil {
+		return nil, err
+	}
+	s.nullable = nullable
+	return s, nil
+}
+
+func schemaForNonNullType(t reflect.Type, visiting map[reflect.Type]bool) (*JSONSchema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == jsonTimeType:
+		return &JSONSchema{Type: "string", Format: "date-time"}, nil
+	case t == jsonRawMessageType, t.Kind() == reflect.Interface:
+		return &JSONSchema{}, nil
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &JSONSchema{}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &JSONSchema{Type: "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &JSONSchema{Type: "integer", Minimum: &zero}, nil
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}, nil
+	case reflect.String:
+		return &JSONSchema{Type: "string"}, nil
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &JSONSchema{Type: "string", Description: "base64"}, nil
+		}
+		items, err := schemaForType(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: "array", Items: items}, nil
+
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %v", t.Key())
+		}
+		elem, err := schemaForType(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: "object", AdditionalProperties: elem}, nil
+
+	case reflect.Struct:
+		if visiting[t] {
+			// 递归类型, 不再展开
+			return &JSONSchema{Type: "object"}, nil
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		s := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}, AdditionalProperties: false}
+		if err := addStructProperties(s, t, visiting); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unsupported type %v", t)
+}
+
+func addStructProperties(s *JSONSchema, t reflect.Type, visiting map[reflect.Type]bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		// 匿名嵌入的结构体展开到上一层, 与 encoding/json 相同
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := addStructProperties(s, ft, visiting); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop, err := schemaForType(f.Type, visiting)
+		if err != nil {
+			return fmt.Errorf("field %s (%w)", f.Name, err)
+		}
+		prop.Description = f.Tag.Get("description")
+		if enum := f.Tag.Get("enum"); enum != "" {
+			for _, e := range strings.Split(enum, ",") {
+				prop.Enum = append(prop.Enum, enumValue(prop.Type, strings.TrimSpace(e)))
+			}
+		}
+		s.Properties[name] = prop
+
+		required := !slices.Contains(strings.Split(opts, ","), "omitempty")
+		if r := f.Tag.Get("required"); r != "" {
+			required, _ = strconv.ParseBool(r)
+		}
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return nil
+}
+
+func enumValue(typ, s string) any {
+	switch typ {
+	case "integer", "number":
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	}
+	return s
+}
+
+// MarshalJSON 实现 json.Marshaler
+func (s *JSONSchema) MarshalJSON() ([]byte, error) {
+	type schema JSONSchema
+	return json.Marshal((*schema)(s))
+}
+
+// Validate 校验 JSON 数据是否符合 schema, 返回所有不符合的地方
+func (s *JSONSchema) Validate(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("invalid JSON (%w)", err)
+	}
+	if dec.More() {
+		return errors.New("invalid JSON, unexpected data after top-level value")
+	}
+	var errs []error
+	s.validate("$", v, &errs)
+	return errors.Join(errs...)
+}
+
+func (s *JSONSchema) validate(path string, v any, errs *[]error) {
+	fail := func(format string, a ...any) {
+		*errs = append(*errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, a...)))
+	}
+	if s.Type == "" {
+		return
+	}
+	if v == nil {
+		if !s.nullable {
+			fail("expect %s but got null", s.Type)
+		}
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			fail("expect object but got %s", jsonTypeName(v))
+			return
+		}
+		for _, name := range s.Required {
+			if _, exist := obj[name]; !exist {
+				fail("missing required field '%s'", name)
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			if prop, exist := s.Properties[k]; exist {
+				prop.validate(path+"."+k, obj[k], errs)
+				continue
+			}
+			switch ap := s.AdditionalProperties.(type) {
+			case bool:
+				if !ap {
+					fail("unknown field '%s'", k)
+				}
+			case *JSONSchema:
+				ap.validate(path+"."+k, obj[k], errs)
+			}
+		}
+
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			fail("expect array but got %s", jsonTypeName(v))
+			return
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				s.Items.validate(path+"["+strconv.Itoa(i)+"]", item, errs)
+			}
+		}
+
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail("expect string but got %s", jsonTypeName(v))
+			return
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				fail("invalid date-time '%s', expect RFC 3339 format", str)
+			}
+		}
+
+	case "integer", "number":
+		n, ok := v.(json.Number)
+		if !ok {
+			fail("expect %s but got %s", s.Type, jsonTypeName(v))
+			return
+		}
+		f, err := n.Float64()
+		if err != nil {
+			fail("invalid number %s", n)
+			return
+		}
+		if s.Type == "integer" && f != float64(int64(f)) {
+			fail("expect integer but got %s", n)
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			fail("%s is less than minimum %v", n, *s.Minimum)
+		}
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail("expect boolean but got %s", jsonTypeName(v))
+		}
+	}
+
+	if len(s.Enum) > 0 && !enumContains(s.Enum, v) {
+		b, _ := json.Marshal(s.Enum)
+		fail("value %v is not one of %s", v, b)
+	}
+}
+
+func enumContains(enum []any, v any) bool {
+	if n, ok := v.(json.Number); ok {
+		f, _ := n.Float64()
+		v = f
+	}
+	return slices.Contains(enum, v)
+}
+
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", v)
+}
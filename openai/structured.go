@@ -0,0 +1,140 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// ProcessJSON 要求模型按照 T 对应的 JSON Schema (参见 GenerateJSONSchema) 输出, 并解析为 T。
+// 模型的输出不是合法的 JSON 或者不符合 schema 时, 会把错误信息发给模型要求修正, 最多重试 WithJSONRepairRetries 次。
+// 返回的 ProcessResponse 包含所有轮次的消息, 包括要求修正的消息
+func ProcessJSON[T any](
+	ctx context.Context, config ModelConfig, messages []openai.ChatCompletionMessage,
+	options ...Option,
+) (T, ProcessResponse, error) {
+	var res T
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	schema, err := schemaForType(typ, map[reflect.Type]bool{})
+	if err != nil {
+		return res, ProcessResponse{}, fmt.Errorf("生成 JSON Schema 失败 (%w)", err)
+	}
+	schema.nullable = false
+
+	// json_schema 模式要求顶层为 object, 其他类型包装在 result 字段中
+	wrapped := schema.Type != "object"
+	if wrapped {
+		schema = &JSONSchema{
+			Type:                 "object",
+			Properties:           map[string]*JSONSchema{structuredResultField: schema},
+			Required:             []string{structuredResultField},
+			AdditionalProperties: false,
+		}
+	}
+
+	opts := mergeOptions(options)
+	format := &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONSchema}
+	if opts.jsonObjectMode {
+		format.Type = openai.ChatCompletionResponseFormatTypeJSONObject
+		b, _ := json.Marshal(schema)
+		messages = insertSystemMessage(messages, jsonObjectModePrompt+string(b))
+	} else {
+		format.JSONSchema = &openai.ChatCompletionResponseFormatJSONSchema{
+			Name:   schemaName(typ),
+			Schema: schema,
+		}
+	}
+	options = append(options[:len(options):len(options)], withResponseFormat(format))
+
+	for attempt := 0; ; attempt++ {
+		rsp, err := Process(ctx, config, messages, options...)
+		if err != nil {
+			return res, rsp, err
+		}
+		content := extractJSON(rsp.Messages[len(rsp.Messages)-1].Content)
+		err = decodeStructured(schema, wrapped, content, &res)
+		if err == nil {
+			return res, rsp, nil
+		}
+		if attempt >= opts.jsonRepairRetries {
+			return res, rsp, fmt.Errorf("模型输出不符合 JSON Schema, 已重试 %d 次 (%w)", attempt, err)
+		}
+		opts.debugf("模型输出不符合 JSON Schema, 要求修正: %v", err)
+		messages = append(rsp.Messages, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleUser,
+			Content: fmt.Sprintf(jsonRepairPrompt, err),
+		})
+	}
+}
+
+const (
+	structuredResultField = "result"
+
+	jsonObjectModePrompt = "请只输出 JSON, 不要输出其他内容。JSON 需要符合以下 JSON Schema:\n"
+	jsonRepairPrompt     = "你的输出不符合要求, 错误如下:\n%v\n请修正后重新输出完整的 JSON, 不要输出其他内容。"
+)
+
+func decodeStructured[T any](schema *JSONSchema, wrapped bool, content string, res *T) error {
+	if err := schema.Validate([]byte(content)); err != nil {
+		return err
+	}
+	if !wrapped {
+		return json.Unmarshal([]byte(content), res)
+	}
+	var w struct {
+		Result T `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(content), &w); err != nil {
+		return err
+	}
+	*res = w.Result
+	return nil
+}
+
+var jsonFenceRegex = regexp.MustCompile("(?s)^```[a-zA-Z]*\\s*(.*?)\\s*```$")
+
+// extractJSON 去掉模型输出中可能存在的 markdown 代码块和前后的说明文字
+func extractJSON(s string) string {
+	s = strings.TrimSpace(s)
+	if m := jsonFenceRegex.FindStringSubmatch(s); m != nil {
+		s = m[1]
+	}
+	if strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[") {
+		return s
+	}
+	start, end := strings.IndexByte(s, '{'), strings.LastIndexByte(s, '}')
+	if start >= 0 && end > start {
+		return s[start : end+1]
+	}
+	return s
+}
+
+// insertSystemMessage 在开头的 system 消息之后插入一条 system 消息, 不修改原切片
+func insertSystemMessage(messages []openai.ChatCompletionMessage, content string) []openai.ChatCompletionMessage {
+	i := 0
+	for i < len(messages) && messages[i].Role == openai.ChatMessageRoleSystem {
+		i++
+	}
+	res := make([]openai.ChatCompletionMessage, 0, len(messages)+1)
+	res = append(res, messages[:i]...)
+	res = append(res, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleSystem, Content: content})
+	return append(res, messages[i:]...)
+}
+
+var schemaNameRegex = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// schemaName 使用类型名作为 schema 名称
+func schemaName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if name := schemaNameRegex.ReplaceAllString(t.Name(), "_"); name != "" {
+		return name
+	}
+	return structuredResultField
+}
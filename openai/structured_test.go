@@ -0,0 +1,99 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	utils "github.com/Andrew-M-C/go.util/openai"
+	"github.com/sashabaranov/go-openai"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+type weatherReport struct {
+	City        string   `json:"city" description:"城市名称"`
+	Temperature float64  `json:"temperature" description:"摄氏度"`
+	Condition   string   `json:"condition" enum:"sunny,cloudy,rainy"`
+	Tips        []string `json:"tips,omitempty"`
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	cv("生成 schema", t, func() {
+		s, err := utils.GenerateJSONSchema(weatherReport{})
+		so(err, isNil)
+		b, err := json.Marshal(s)
+		so(err, isNil)
+		so(string(b), eq, `{"type":"object","properties":{"city":{"type":"string","description":"城市名称"},`+
+			`"condition":{"type":"string","enum":["sunny","cloudy","rainy"]},`+
+			`"temperature":{"type":"number","description":"摄氏度"},"tips":{"type":"array","items":{"type":"string"}}},`+
+			`"required":["city","temperature","condition"],"additionalProperties":false}`)
+	})
+
+	cv("校验", t, func() {
+		s, err := utils.GenerateJSONSchema(weatherReport{})
+		so(err, isNil)
+		so(s.Validate([]byte(`{"city":"深圳","temperature":25.5,"condition":"sunny","tips":null}`)), isNil)
+
+		err = s.Validate([]byte(`{"city":1,"condition":"snowy","extra":true}`))
+		so(err, convey.ShouldBeError)
+		so(err.Error(), convey.ShouldContainSubstring, "$: missing required field 'temperature'")
+		so(err.Error(), convey.ShouldContainSubstring, "$.city: expect string but got number")
+		so(err.Error(), convey.ShouldContainSubstring, `$.condition: value snowy is not one of ["sunny","cloudy","rainy"]`)
+		so(err.Error(), convey.ShouldContainSubstring, "$: unknown field 'extra'")
+
+		so(s.Validate([]byte(`{"city":`)), convey.ShouldBeError)
+	})
+}
+
+func TestProcessJSON(t *testing.T) {
+	replies := []string{
+		`{"city":"深圳","temperature":"hot"}`,
+		"```json\n{\"city\":\"深圳\",\"temperature\":25,\"condition\":\"sunny\"}\n```",
+	}
+	llm := newFakeLLM(func(req openai.ChatCompletionRequest) openai.ChatCompletionMessage {
+		if req.ResponseFormat != nil && req.ResponseFormat.Type == openai.ChatCompletionResponseFormatTypeJSONObject {
+			return openai.ChatCompletionMessage{Content: `{"result":["a","b"]}`}
+		}
+		n := 0
+		for _, m := range req.Messages {
+			if m.Role == openai.ChatMessageRoleAssistant {
+				n++
+			}
+		}
+		return openai.ChatCompletionMessage{Content: replies[min(n, len(replies)-1)]}
+	})
+	defer llm.Close()
+	ctx := context.Background()
+	msgs := []openai.ChatCompletionMessage{userMsg("深圳天气如何?")}
+
+	cv("校验失败后修正", t, func() {
+		res, rsp, err := utils.ProcessJSON[weatherReport](ctx, llm.config(), msgs)
+		so(err, isNil)
+		so(res.City, eq, "深圳")
+		so(res.Temperature, eq, 25)
+		so(res.Condition, eq, "sunny")
+		so(len(rsp.Messages), eq, 4)
+
+		req := llm.lastRequest()
+		so(req.ResponseFormat.Type, eq, openai.ChatCompletionResponseFormatTypeJSONSchema)
+		so(req.ResponseFormat.JSONSchema.Name, eq, "weatherReport")
+		so(req.Messages[2].Content, convey.ShouldContainSubstring, "$.temperature: expect number but got string")
+	})
+
+	cv("超过重试次数", t, func() {
+		_, _, err := utils.ProcessJSON[weatherReport](ctx, llm.config(), msgs, utils.WithJSONRepairRetries(0))
+		so(err, convey.ShouldBeError)
+		so(strings.Contains(err.Error(), "missing required field 'condition'"), eq, true)
+	})
+
+	cv("json_object 模式和非对象类型", t, func() {
+		res, _, err := utils.ProcessJSON[[]string](ctx, llm.config(), msgs, utils.WithJSONObjectMode())
+		so(err, isNil)
+		so(res, convey.ShouldResemble, []string{"a", "b"})
+
+		req := llm.lastRequest()
+		so(req.Messages[0].Role, eq, openai.ChatMessageRoleSystem)
+		so(req.Messages[0].Content, convey.ShouldContainSubstring, `"required":["result"]`)
+	})
+}
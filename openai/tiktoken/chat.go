@@ -0,0 +1,46 @@
+package tiktoken
+
+import (
+	"github.com/sashabaranov/go-openai"
+)
+
+// 聊天格式的额外开销, 参见 OpenAI cookbook 中的 num_tokens_from_messages
+const (
+	tokensPerMessage  = 3  // 每条消息的 <|start|>{role}\n ... <|end|>
+	tokensPerName     = 1  // 消息带有 name 时额外的开销
+	tokensPerReply    = 3  // 每次回复都以 <|start|>assistant<|message|> 开头
+	tokensPerToolCall = 3  // 每个工具调用的开销, 为近似值
+	tokensPerImage    = 85 // 图片按照 low detail 计算
+)
+
+// CountMessage 计算单条消息的 token 数, 包括角色、name、工具调用等聊天格式的开销
+func (e *Encoding) CountMessage(m openai.ChatCompletionMessage) int {
+	n := tokensPerMessage + e.Count(m.Role) + e.Count(m.Content)
+	if m.Name != "" {
+		n += tokensPerName + e.Count(m.Name)
+	}
+	for _, p := range m.MultiContent {
+		switch p.Type {
+		case openai.ChatMessagePartTypeImageURL:
+			n += tokensPerImage
+		default:
+			n += e.Count(p.Text)
+		}
+	}
+	for _, tc := range m.ToolCalls {
+		n += tokensPerToolCall + e.Count(tc.Function.Name) + e.Count(tc.Function.Arguments)
+	}
+	if m.ToolCallID != "" {
+		n += e.Count(m.ToolCallID)
+	}
+	return n
+}
+
+// CountMessages 计算一次请求中所有消息的 token 数, 包括回复的起始开销。可以直接作为 openai.WithTokenCounter 的参数
+func (e *Encoding) CountMessages(messages []openai.ChatCompletionMessage) int {
+	n := tokensPerReply
+	for _, m := range messages {
+		n += e.CountMessage(m)
+	}
+	return n
+}
@@ -0,0 +1,6 @@
+package tiktoken
+
+// SplitForTest 返回预分词的结果, 仅用于测试
+func SplitForTest(e *Encoding, s string) []string {
+	return e.split(s)
+}
@@ -0,0 +1,238 @@
+package tiktoken
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// tiktoken 的预分词正则使用了 Go regexp 不支持的环视和占有量词, 因此按照正则的语义手工实现。
+//
+// cl100k_base:
+//
+//	'(?i:[sdmt]|ll|ve|re)|[^\r\n\p{L}\p{N}]?+\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]++[\r\n]*|\s*[\r\n]|\s+(?!\S)|\s+
+//
+// o200k_base:
+//
+//	[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?|
+//	[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?|
+//	\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n/]*|\s*[\r\n]+|\s+(?!\S)|\s+
+
+var splitters = map[string]func(string) []string{
+	Cl100kBase: func(s string) []string { return split(s, cl100kMatchers) },
+	O200kBase:  func(s string) []string { return split(s, o200kMatchers) },
+}
+
+// matcher 尝试在 runes[i:] 处匹配, 返回匹配结束的位置, 不匹配时返回 -1
+type matcher func(runes []rune, i int) int
+
+var (
+	cl100kMatchers = []matcher{
+		matchContraction,
+		matchCl100kWord,
+		matchDigits,
+		matchPunct(false),
+		matchNewlines,
+		matchTrailingSpaces,
+		matchSpaces,
+	}
+	o200kMatchers = []matcher{
+		matchO200kWord(false),
+		matchO200kWord(true),
+		matchDigits,
+		matchPunct(true),
+		matchNewlines,
+		matchTrailingSpaces,
+		matchSpaces,
+	}
+)
+
+func split(s string, matchers []matcher) []string {
+	runes := []rune(s)
+	var res []string
+	for i := 0; i < len(runes); {
+		end := -1
+		for _, m := range matchers {
+			if end = m(runes, i); end > i {
+				break
+			}
+		}
+		if end <= i {
+			end = i + 1 // 正则不会走到这里, 兜底
+		}
+		res = append(res, string(runes[i:end]))
+		i = end
+	}
+	return res
+}
+
+func isLetter(r rune) bool  { return unicode.IsLetter(r) }
+func isNumber(r rune) bool  { return unicode.IsNumber(r) }
+func isSpace(r rune) bool   { return unicode.IsSpace(r) }
+func isNewline(r rune) bool { return r == '\r' || r == '\n' }
+
+// isPrefix 对应 [^\r\n\p{L}\p{N}]
+func isPrefix(r rune) bool {
+	return !isNewline(r) && !isLetter(r) && !isNumber(r)
+}
+
+// isPunct 对应 [^\s\p{L}\p{N}]
+func isPunct(r rune) bool {
+	return !isSpace(r) && !isLetter(r) && !isNumber(r)
+}
+
+// isUpper 对应 [\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]
+func isUpper(r rune) bool {
+	return unicode.In(r, unicode.Lu, unicode.Lt, unicode.Lm, unicode.Lo, unicode.M)
+}
+
+// isLower 对应 [\p{Ll}\p{Lm}\p{Lo}\p{M}]
+func isLower(r rune) bool {
+	return unicode.In(r, unicode.Ll, unicode.Lm, unicode.Lo, unicode.M)
+}
+
+func span(runes []rune, i int, f func(rune) bool) int {
+	for i < len(runes) && f(runes[i]) {
+		i++
+	}
+	return i
+}
+
+func lowerAt(runes []rune, i int) rune {
+	if i >= len(runes) {
+		return utf8.RuneError
+	}
+	return unicode.ToLower(runes[i])
+}
+
+// matchContraction '(?i:[sdmt]|ll|ve|re)
+func matchContraction(runes []rune, i int) int {
+	if i >= len(runes) || runes[i] != '\'' {
+		return -1
+	}
+	switch c := lowerAt(runes, i+1); c {
+	case 's', 'd', 'm', 't':
+		return i + 2
+	case 'l', 'v', 'r':
+		next := map[rune]rune{'l': 'l', 'v': 'e', 'r': 'e'}[c]
+		if lowerAt(runes, i+2) == next {
+			return i + 3
+		}
+	}
+	return -1
+}
+
+// matchCl100kWord [^\r\n\p{L}\p{N}]?+\p{L}+
+func matchCl100kWord(runes []rune, i int) int {
+	start := i
+	if isPrefix(runes[i]) {
+		start++ // 占有量词, 不回溯
+	}
+	end := span(runes, start, isLetter)
+	if end == start {
+		return -1
+	}
+	return end
+}
+
+// matchDigits \p{N}{1,3}
+func matchDigits(runes []rune, i int) int {
+	end := i
+	for end < len(runes) && end-i < 3 && isNumber(runes[end]) {
+		end++
+	}
+	if end == i {
+		return -1
+	}
+	return end
+}
+
+// matchPunct ' ?[^\s\p{L}\p{N}]++[\r\n]*', withSlash 时结尾为 [\r\n/]*
+func matchPunct(withSlash bool) matcher {
+	tail := isNewline
+	if withSlash {
+		tail = func(r rune) bool { return isNewline(r) || r == '/' }
+	}
+	return func(runes []rune, i int) int {
+		start := i
+		if runes[i] == ' ' && i+1 < len(runes) && isPunct(runes[i+1]) {
+			start++
+		}
+		end := span(runes, start, isPunct)
+		if end == start {
+			return -1
+		}
+		return span(runes, end, tail)
+	}
+}
+
+// matchNewlines \s*[\r\n] 或 \s*[\r\n]+, 两者都匹配到空白中最后一个换行为止
+func matchNewlines(runes []rune, i int) int {
+	end := span(runes, i, isSpace)
+	for j := end - 1; j >= i; j-- {
+		if isNewline(runes[j]) {
+			return j + 1
+		}
+	}
+	return -1
+}
+
+// matchTrailingSpaces \s+(?!\S)
+func matchTrailingSpaces(runes []rune, i int) int {
+	end := span(runes, i, isSpace)
+	switch {
+	case end == i:
+		return -1
+	case end == len(runes):
+		return end
+	case end-i > 1:
+		return end - 1 // 回溯一个字符, 留给后面的非空白字符
+	}
+	return -1
+}
+
+// matchSpaces \s+
+func matchSpaces(runes []rune, i int) int {
+	if end := span(runes, i, isSpace); end > i {
+		return end
+	}
+	return -1
+}
+
+// matchO200kWord 两种单词规则:
+//
+//	upperFirst 为 false: [^\r\n\p{L}\p{N}]?[upper]*[lower]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?
+//	upperFirst 为 true:  [^\r\n\p{L}\p{N}]?[upper]+[lower]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?
+func matchO200kWord(upperFirst bool) matcher {
+	body := func(runes []rune, i int) int {
+		if upperFirst {
+			end := span(runes, i, isUpper)
+			if end == i {
+				return -1
+			}
+			return span(runes, end, isLower)
+		}
+		// [upper]* 贪婪匹配之后回溯, 直到后面可以匹配 [lower]+
+		for j := span(runes, i, isUpper); j >= i; j-- {
+			if end := span(runes, j, isLower); end > j {
+				return end
+			}
+		}
+		return -1
+	}
+	return func(runes []rune, i int) int {
+		end := -1
+		if isPrefix(runes[i]) {
+			end = body(runes, i+1)
+		}
+		if end < 0 {
+			end = body(runes, i)
+		}
+		if end < 0 {
+			return end
+		}
+		if c := matchContraction(runes, end); c > 0 {
+			return c
+		}
+		return end
+	}
+}
@@ -0,0 +1,269 @@
+// Package tiktoken 离线的 BPE tokenizer, 兼容 OpenAI tiktoken 的 cl100k_base 和 o200k_base 编码,
+// 用于在发送请求之前计算消息的 token 数。
+//
+// 由于词表文件较大, 本包不内置词表, 需要从磁盘加载或者由调用方 embed 之后注册。
+// 词表文件格式与 tiktoken 相同 (每行为 base64 编码的 token 和序号), 可以从
+// https://openaipublic.blob.core.windows.net/encodings/cl100k_base.tiktoken 等地址下载
+package tiktoken
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	Cl100kBase = "cl100k_base"
+	O200kBase  = "o200k_base"
+)
+
+// VocabDirEnv 指定词表文件所在目录的环境变量, 文件名为 "<编码名>.tiktoken"。
+// 未设置时使用 os.UserCacheDir() 下的 tiktoken 目录
+const VocabDirEnv = "TIKTOKEN_VOCAB_DIR"
+
+// ErrUnknownModel 表示无法判断模型使用的编码
+var ErrUnknownModel = errors.New("unknown model encoding")
+
+// Encoding 表示一种 BPE 编码
+type Encoding struct {
+	name    string
+	ranks   map[string]int
+	decoder map[int]string
+	split   func(string) []string
+}
+
+// NewEncoding 从 tiktoken 格式的词表中创建编码, name 决定预分词规则, 只支持 Cl100kBase 和 O200kBase
+func NewEncoding(name string, vocab io.Reader) (*Encoding, error) {
+	split, ok := splitters[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported encoding '%s'", name)
+	}
+	e := &Encoding{
+		name:    name,
+		ranks:   map[string]int{},
+		decoder: map[int]string{},
+		split:   split,
+	}
+
+	scanner := bufio.NewScanner(vocab)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		token, rank, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("invalid vocab line %d", n)
+		}
+		b, err := base64.StdEncoding.DecodeString(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token in vocab line %d (%w)", n, err)
+		}
+		r, err := strconv.Atoi(rank)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rank in vocab line %d (%w)", n, err)
+		}
+		e.ranks[string(b)] = r
+		e.decoder[r] = string(b)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read vocab error (%w)", err)
+	}
+	if len(e.ranks) == 0 {
+		return nil, errors.New("empty vocab")
+	}
+	return e, nil
+}
+
+// LoadEncoding 从文件中加载编码
+func LoadEncoding(name, path string) (*Encoding, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open vocab file error (%w)", err)
+	}
+	defer f.Close()
+	return NewEncoding(name, f)
+}
+
+var (
+	registryLock sync.Mutex
+	registry     = map[string]*Encoding{}
+)
+
+// Register 注册编码, 之后 GetEncoding 和 EncodingForModel 直接返回该编码。一般用于注册 embed 的词表
+func Register(e *Encoding) {
+	if e == nil {
+		return
+	}
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	registry[e.name] = e
+}
+
+// GetEncoding 按照名称获取编码, 未注册时从 VocabDirEnv 指定的目录中加载并注册
+func GetEncoding(name string) (*Encoding, error) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	if e, exist := registry[name]; exist {
+		return e, nil
+	}
+	if _, ok := splitters[name]; !ok {
+		return nil, fmt.Errorf("unsupported encoding '%s'", name)
+	}
+
+	dir := os.Getenv(VocabDirEnv)
+	if dir == "" {
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("vocab dir not specified (%w)", err)
+		}
+		dir = filepath.Join(cache, "tiktoken")
+	}
+	e, err := LoadEncoding(name, filepath.Join(dir, name+".tiktoken"))
+	if err != nil {
+		return nil, err
+	}
+	registry[name] = e
+	return e, nil
+}
+
+// ModelEncodingName 返回模型使用的编码名称, 无法判断时返回 false
+func ModelEncodingName(model string) (string, bool) {
+	model = strings.ToLower(model)
+	for _, prefix := range []string{"gpt-4o", "chatgpt-4o", "gpt-4.1", "gpt-4.5", "gpt-5", "o1", "o3", "o4"} {
+		if strings.HasPrefix(model, prefix) {
+			return O200kBase, true
+		}
+	}
+	for _, prefix := range []string{"gpt-4", "gpt-3.5", "text-embedding-3", "text-embedding-ada-002"} {
+		if strings.HasPrefix(model, prefix) {
+			return Cl100kBase, true
+		}
+	}
+	return "", false
+}
+
+// EncodingForModel 按照模型名称获取编码
+func EncodingForModel(model string) (*Encoding, error) {
+	name, ok := ModelEncodingName(model)
+	if !ok {
+		return nil, fmt.Errorf("%w '%s'", ErrUnknownModel, model)
+	}
+	return GetEncoding(name)
+}
+
+// Name 返回编码名称
+func (e *Encoding) Name() string {
+	return e.name
+}
+
+// Encode 将文本编码为 token 序列。特殊 token (如 <|endoftext|>) 按照普通文本处理
+func (e *Encoding) Encode(s string) []int {
+	var res []int
+	for _, piece := range e.split(s) {
+		if r, exist := e.ranks[piece]; exist {
+			res = append(res, r)
+			continue
+		}
+		res = append(res, e.bytePairEncode(piece)...)
+	}
+	return res
+}
+
+// Count 返回文本的 token 数
+func (e *Encoding) Count(s string) int {
+	n := 0
+	for _, piece := range e.split(s) {
+		if _, exist := e.ranks[piece]; exist {
+			n++
+			continue
+		}
+		n += len(e.bytePairEncode(piece))
+	}
+	return n
+}
+
+// Decode 将 token 序列解码为文本, 无法识别的 token 被忽略
+func (e *Encoding) Decode(tokens []int) string {
+	b := strings.Builder{}
+	for _, t := range tokens {
+		b.WriteString(e.decoder[t])
+	}
+	return b.String()
+}
+
+// Truncate 将文本截断到最多 n 个 token, 截断位置总是在 token 边界上, 并且不会截断 UTF-8 字符
+func (e *Encoding) Truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	tokens := e.Encode(s)
+	if len(tokens) <= n {
+		return s
+	}
+	for ; n > 0; n-- {
+		if res := e.Decode(tokens[:n]); utf8.ValidString(res) {
+			return res
+		}
+	}
+	return ""
+}
+
+// bytePairEncode 对单个预分词片段执行 BPE 合并, 算法与 tiktoken 相同: 每次合并序号最小的相邻字节对
+func (e *Encoding) bytePairEncode(piece string) []int {
+	if len(piece) == 1 {
+		return []int{e.ranks[piece]}
+	}
+
+	// parts 为各个片段的起始位置, 最后一个为结束位置
+	parts := make([]int, len(piece)+1)
+	for i := range parts {
+		parts[i] = i
+	}
+	rank := func(i int) int {
+		if i+2 >= len(parts) {
+			return math.MaxInt
+		}
+		if r, exist := e.ranks[piece[parts[i]:parts[i+2]]]; exist {
+			return r
+		}
+		return math.MaxInt
+	}
+	ranks := make([]int, len(parts)-1)
+	for i := range ranks {
+		ranks[i] = rank(i)
+	}
+
+	for len(parts) > 2 {
+		minIdx, minRank := -1, math.MaxInt
+		for i, r := range ranks[:len(ranks)-1] {
+			if r < minRank {
+				minIdx, minRank = i, r
+			}
+		}
+		if minIdx < 0 {
+			break
+		}
+		parts = append(parts[:minIdx+1], parts[minIdx+2:]...)
+		ranks = append(ranks[:minIdx+1], ranks[minIdx+2:]...)
+		ranks[minIdx] = rank(minIdx)
+		if minIdx > 0 {
+			ranks[minIdx-1] = rank(minIdx - 1)
+		}
+	}
+
+	res := make([]int, 0, len(parts)-1)
+	for i := 0; i+1 < len(parts); i++ {
+		res = append(res, e.ranks[piece[parts[i]:parts[i+1]]])
+	}
+	return res
+}
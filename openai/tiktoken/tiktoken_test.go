@@ -0,0 +1,140 @@
+package tiktoken_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Andrew-M-C/go.util/openai/tiktoken"
+	"github.com/sashabaranov/go-openai"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	cv = convey.Convey
+	so = convey.So
+	eq = convey.ShouldEqual
+
+	isNil = convey.ShouldBeNil
+	isErr = convey.ShouldBeError
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(m.Run())
+}
+
+// testVocab 生成一个最小的词表: 256 个单字节 token 加上若干合并规则
+func testVocab() string {
+	b := strings.Builder{}
+	add := func(token string, rank int) {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), rank)
+	}
+	for i := 0; i < 256; i++ {
+		add(string([]byte{byte(i)}), i)
+	}
+	for i, token := range []string{"he", "ll", "llo", "hello", " w", "or"} {
+		add(token, 256+i)
+	}
+	return b.String()
+}
+
+func TestEncoding(t *testing.T) {
+	enc, err := tiktoken.NewEncoding(tiktoken.Cl100kBase, strings.NewReader(testVocab()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cv("BPE 编码与解码", t, func() {
+		tokens := enc.Encode("hello world")
+		so(tokens, convey.ShouldResemble, []int{259, 260, 261, 'l', 'd'})
+		so(enc.Count("hello world"), eq, 5)
+		so(enc.Decode(tokens), eq, "hello world")
+
+		// "hel" 先合并 he, 再剩下 l
+		so(enc.Encode("hel"), convey.ShouldResemble, []int{256, 'l'})
+	})
+
+	cv("按 token 截断", t, func() {
+		so(enc.Truncate("hello world", 2), eq, "hello w")
+		so(enc.Truncate("hello world", 10), eq, "hello world")
+		so(enc.Truncate("你好", 4), eq, "你")
+		so(enc.Truncate("你好", 2), eq, "")
+	})
+
+	cv("聊天消息计数", t, func() {
+		msgs := []openai.ChatCompletionMessage{{Role: "user", Content: "hello"}}
+		so(enc.CountMessages(msgs), eq, 3+(3+4+1))
+
+		msgs[0].Name = "he"
+		so(enc.CountMessages(msgs), eq, 3+(3+4+1)+(1+1))
+	})
+
+	cv("非法词表", t, func() {
+		_, err := tiktoken.NewEncoding("p50k_base", strings.NewReader(testVocab()))
+		so(err, isErr)
+		_, err = tiktoken.NewEncoding(tiktoken.Cl100kBase, strings.NewReader("abc\n"))
+		so(err, isErr)
+		_, err = tiktoken.NewEncoding(tiktoken.Cl100kBase, strings.NewReader(""))
+		so(err, isErr)
+	})
+}
+
+func TestSplit(t *testing.T) {
+	pieces := func(name, s string) []string {
+		enc, err := tiktoken.NewEncoding(name, strings.NewReader(testVocab()))
+		so(err, isNil)
+		return tiktoken.SplitForTest(enc, s)
+	}
+
+	cv("cl100k_base", t, func() {
+		so(pieces(tiktoken.Cl100kBase, "I'm here  now\n\nok 123456 !!\n"), convey.ShouldResemble, []string{
+			"I", "'m", " here", " ", " now", "\n\n", "ok", " ", "123", "456", " !!\n",
+		})
+		so(pieces(tiktoken.Cl100kBase, "中文 test  "), convey.ShouldResemble, []string{"中文", " test", "  "})
+	})
+
+	cv("o200k_base", t, func() {
+		so(pieces(tiktoken.O200kBase, "HelloWorld's abc/def\n"), convey.ShouldResemble, []string{
+			"Hello", "World's", " abc", "/def", "\n",
+		})
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	cv("按模型获取编码", t, func() {
+		name, ok := tiktoken.ModelEncodingName("gpt-4o-mini")
+		so(ok, eq, true)
+		so(name, eq, tiktoken.O200kBase)
+		name, _ = tiktoken.ModelEncodingName("gpt-4-turbo")
+		so(name, eq, tiktoken.Cl100kBase)
+
+		_, err := tiktoken.EncodingForModel("deepseek-chat")
+		so(errors.Is(err, tiktoken.ErrUnknownModel), eq, true)
+	})
+
+	cv("从目录加载和注册", t, func() {
+		dir := t.TempDir()
+		so(os.WriteFile(filepath.Join(dir, "o200k_base.tiktoken"), []byte(testVocab()), 0o600), isNil)
+		t.Setenv(tiktoken.VocabDirEnv, dir)
+
+		enc, err := tiktoken.EncodingForModel("o1-mini")
+		so(err, isNil)
+		so(enc.Name(), eq, tiktoken.O200kBase)
+		so(enc.Count("hello"), eq, 1)
+
+		again, err := tiktoken.GetEncoding(tiktoken.O200kBase)
+		so(err, isNil)
+		so(again, eq, enc)
+
+		enc, err = tiktoken.NewEncoding(tiktoken.Cl100kBase, strings.NewReader(testVocab()))
+		so(err, isNil)
+		tiktoken.Register(enc)
+		got, err := tiktoken.EncodingForModel("gpt-3.5-turbo")
+		so(err, isNil)
+		so(got, eq, enc)
+	})
+}